@@ -63,6 +63,8 @@ type FieldProperty struct {
 	MinLength    string            `json:"minLength,omitempty"`
 	DefaultValue any               `json:"defaultValue,omitempty"`
 	Options      map[string]Option `json:"options,omitempty"`
+	// Fields はテーブル（SUBTABLE）内のフィールド
+	Fields map[string]FieldProperty `json:"fields,omitempty"`
 	// 他にも多数のプロパティがあるが、必要に応じて追加
 }
 
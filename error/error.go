@@ -1,7 +1,21 @@
 // Package error はkintone APIエラー型を提供する
 package error
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// kintoneのエラーコード
+const (
+	CodeAppNotFound      = "GAIA_AP01" // アプリが存在しない
+	CodeRecordNotFound   = "GAIA_RE01" // レコードが存在しない
+	CodeRevisionConflict = "GAIA_CO02" // 指定したリビジョンが最新ではない
+	CodeValidation       = "CB_VA01"   // 入力内容が正しくない
+	CodeNoPermission     = "CB_NO02"   // 権限がない
+	CodeCursorLimit      = "GAIA_TM12" // 作成できるカーソルの上限に達している
+	CodeCursorNotFound   = "GAIA_RE18" // カーソルが存在しない（有効期限切れを含む）
+)
 
 // KintoneRestAPIError はkintone REST APIエラー
 type KintoneRestAPIError struct {
@@ -10,6 +24,10 @@ type KintoneRestAPIError struct {
 	Message string         // エラーメッセージ
 	ID      string         // エラーID
 	Errors  map[string]any // 詳細エラー情報
+
+	// BulkRequestIndex はバルクリクエストで失敗したリクエストのインデックス
+	// バルクリクエスト以外のエラーではnil
+	BulkRequestIndex *int `json:"-"`
 }
 
 // Error はerrorインターフェースを実装
@@ -17,10 +35,34 @@ func (e *KintoneRestAPIError) Error() string {
 	return fmt.Sprintf("[%d] [%s] %s (%s)", e.Status, e.Code, e.Message, e.ID)
 }
 
+// HasCode はエラーが指定したエラーコードのKintoneRestAPIErrorかどうかを返す
+// ラップされたエラー（KintoneAllRecordsErrorなど）も判定できる
+func HasCode(err error, code string) bool {
+	var apiErr *KintoneRestAPIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
 // KintoneAllRecordsError は大量レコード処理時のエラー
+// バルクリクエスト単位で処理するため、失敗したバルクリクエストより前の結果は確定している
 type KintoneAllRecordsError struct {
-	ProcessedRecords   any                  // 処理済みレコード
-	UnprocessedRecords []any                // 未処理レコード
-	Error              *KintoneRestAPIError // 原因のエラー
-	ErrorIndex         int                  // エラーが発生したインデックス
+	ProcessedRecords      any   // 処理済みレコードの結果
+	UnprocessedRecords    []any // 未処理レコード
+	Err                   error // 原因のエラー
+	ErrorIndex            int   // エラーが発生したレコードのインデックス（特定できない場合は-1）
+	NumOfProcessedRecords int   // 処理済みレコード数
+	NumOfAllRecords       int   // 全レコード数
+}
+
+// Error はerrorインターフェースを実装
+func (e *KintoneAllRecordsError) Error() string {
+	msg := fmt.Sprintf("%d/%d件のレコードを処理済み", e.NumOfProcessedRecords, e.NumOfAllRecords)
+	if e.ErrorIndex >= 0 {
+		msg += fmt.Sprintf("、%d件目のレコードでエラーが発生", e.ErrorIndex)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+// Unwrap は原因のエラーを返す
+func (e *KintoneAllRecordsError) Unwrap() error {
+	return e.Err
 }
@@ -0,0 +1,385 @@
+package export
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/file"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// defaultAttachmentsConcurrency は同時にダウンロードするファイル数の既定値
+const defaultAttachmentsConcurrency = 4
+
+// ManifestFileName はAttachmentsが書き出すマニフェストのファイル名
+const ManifestFileName = "manifest.json"
+
+// AttachmentsParams はAttachmentsのパラメータ
+type AttachmentsParams struct {
+	App       types.AppID
+	Condition string   // 対象のレコードを絞り込む条件（省略時は全レコード）
+	Fields    []string // ダウンロードする添付ファイルのフィールドコード（テーブル内を含む、省略時は全ての添付ファイルのフィールド）
+	Dir       string   // 保存先のディレクトリ
+
+	Concurrency int // 同時にダウンロードするファイル数（省略時は4）
+}
+
+// AttachmentEntry はダウンロードした添付ファイル
+type AttachmentEntry struct {
+	RecordID    types.RecordID `json:"recordId"`
+	Field       string         `json:"field"`           // 添付ファイルのフィールドコード
+	Table       string         `json:"table,omitempty"` // テーブル内のフィールドの場合はテーブルのフィールドコード
+	RowID       string         `json:"rowId,omitempty"` // テーブル内のフィールドの場合は行ID
+	FileKey     string         `json:"fileKey"`
+	Name        string         `json:"name"`
+	ContentType string         `json:"contentType"`
+	Size        string         `json:"size"`
+	Path        string         `json:"path"` // 保存先のディレクトリからの相対パス（区切り文字は「/」）
+
+	Skipped bool `json:"-"` // 保存済みのためダウンロードしなかった
+
+	done bool // 保存が完了した
+}
+
+// Manifest はAttachmentsが保存先のディレクトリに書き出すマニフェスト
+type Manifest struct {
+	App       types.AppID       `json:"app"`
+	Condition string            `json:"condition"`
+	Files     []AttachmentEntry `json:"files"`
+}
+
+// AttachmentsResult はAttachmentsの結果
+type AttachmentsResult struct {
+	Downloaded int // ダウンロードしたファイル数
+	Skipped    int // 保存済みのためダウンロードしなかったファイル数
+	Files      []AttachmentEntry
+}
+
+// Attachments は条件に一致する全レコードの添付ファイルを、保存先のディレクトリに「レコードID/フィールドコード/ファイル名」で保存する
+//
+// テーブル内の添付ファイルも、テーブル内のフィールドコードのディレクトリに保存する。
+// 同じディレクトリに同じファイル名がある場合は「名前 (2).拡張子」のように番号を付ける。
+// 保存先に同じパスのファイルがあり、サイズが一致する場合はダウンロードしないため、中断した後に再実行すると続きから保存できる
+// （ダウンロード中のファイルは「.part」を付けたパスに書き込み、完了後に名前を変更する）。
+//
+// レコードは$idの昇順に取得し、ファイルはConcurrency個ずつ並行してダウンロードする。
+// 最後に、各ファイルとレコード・フィールド・ファイルキーの対応をマニフェスト（manifest.json）に書き出す。
+// エラーが発生した場合は、それまでに保存したファイルのマニフェストを書き出してエラーを返す
+func Attachments(ctx context.Context, appClient *app.Client, recordClient *record.Client, fileClient *file.Client, params AttachmentsParams) (*AttachmentsResult, error) {
+	if params.Dir == "" {
+		return nil, fmt.Errorf("保存先のディレクトリが指定されていません")
+	}
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultAttachmentsConcurrency
+	}
+
+	fields, err := appClient.GetFormFields(ctx, app.GetFormFieldsParams{App: params.App})
+	if err != nil {
+		return nil, err
+	}
+	targets, requested, err := attachmentFields(fields, params.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(params.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ディレクトリの作成エラー: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var mu sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	d := &attachmentDownloader{fileClient: fileClient, dir: params.Dir}
+	jobs := make(chan *AttachmentEntry)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			for entry := range jobs {
+				if err := d.download(ctx, entry); err != nil {
+					fail(err)
+				}
+			}
+		})
+	}
+
+	var entries []*AttachmentEntry
+	names := map[string]bool{}
+	enqueue := func(entry *AttachmentEntry) bool {
+		entry.Path = uniquePath(names, entry.RecordID, entry.Field, entry.Name)
+		entries = append(entries, entry)
+		select {
+		case jobs <- entry:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+records:
+	for rec, err := range record.AllRecords[record.Dynamic](ctx, recordClient, record.GetAllRecordsParams{
+		App:       params.App,
+		Fields:    requested,
+		Condition: params.Condition,
+	}) {
+		if err != nil {
+			fail(err)
+			break
+		}
+		for _, target := range targets {
+			files, err := recordFiles(rec, target)
+			if err != nil {
+				fail(err)
+				break records
+			}
+			for _, entry := range files {
+				if !enqueue(entry) {
+					break records
+				}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &AttachmentsResult{Files: []AttachmentEntry{}}
+	for _, entry := range entries {
+		if !entry.done {
+			continue
+		}
+		if entry.Skipped {
+			result.Skipped++
+		} else {
+			result.Downloaded++
+		}
+		result.Files = append(result.Files, *entry)
+	}
+
+	if err := writeManifest(params, result.Files); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if firstErr != nil {
+		return result, firstErr
+	}
+	return result, nil
+}
+
+// attachmentField はダウンロードする添付ファイルのフィールド
+type attachmentField struct {
+	code  string
+	table string // テーブル内のフィールドの場合はテーブルのフィールドコード
+}
+
+// attachmentFields はダウンロードする添付ファイルのフィールドと、レコードの取得で指定するフィールドコードを返す
+func attachmentFields(fields *app.GetFormFieldsResult, codes []string) ([]attachmentField, []string, error) {
+	all := map[string]attachmentField{}
+	for code, prop := range fields.Properties {
+		if prop.Type == types.FieldTypeFile {
+			all[code] = attachmentField{code: code}
+		}
+		for inner, innerProp := range prop.Fields {
+			if innerProp.Type == types.FieldTypeFile {
+				all[inner] = attachmentField{code: inner, table: code}
+			}
+		}
+	}
+
+	if len(codes) == 0 {
+		for code := range all {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+	}
+	var targets []attachmentField
+	var requested []string
+	seen := map[string]bool{}
+	for _, code := range codes {
+		target, ok := all[code]
+		if !ok {
+			return nil, nil, fmt.Errorf("添付ファイルのフィールドではありません: %s", code)
+		}
+		if slices.Contains(targets, target) {
+			continue
+		}
+		targets = append(targets, target)
+		request := target.code
+		if target.table != "" {
+			request = target.table
+		}
+		if !seen[request] {
+			seen[request] = true
+			requested = append(requested, request)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("添付ファイルのフィールドがありません")
+	}
+	// 保存先のパスが実行ごとに変わらないよう、フィールドの順序を固定する
+	slices.SortFunc(targets, func(a, b attachmentField) int {
+		return cmp.Or(cmp.Compare(a.table, b.table), cmp.Compare(a.code, b.code))
+	})
+	return targets, requested, nil
+}
+
+// recordFiles はレコードのフィールドの添付ファイルを返す
+func recordFiles(rec record.Dynamic, target attachmentField) ([]*AttachmentEntry, error) {
+	newEntry := func(f types.File, rowID string) *AttachmentEntry {
+		return &AttachmentEntry{
+			RecordID:    rec.ID(),
+			Field:       target.code,
+			Table:       target.table,
+			RowID:       rowID,
+			FileKey:     f.FileKey,
+			Name:        f.Name,
+			ContentType: f.ContentType,
+			Size:        f.Size,
+		}
+	}
+
+	var entries []*AttachmentEntry
+	if target.table == "" {
+		if !rec.Has(target.code) {
+			return nil, nil
+		}
+		files, err := rec.Files(target.code)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			entries = append(entries, newEntry(f, ""))
+		}
+		return entries, nil
+	}
+
+	if !rec.Has(target.table) {
+		return nil, nil
+	}
+	rows, err := rec.Subtable(target.table)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if !row.Record.Has(target.code) {
+			continue
+		}
+		files, err := row.Record.Files(target.code)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			entries = append(entries, newEntry(f, row.ID))
+		}
+	}
+	return entries, nil
+}
+
+// uniquePath は保存先の相対パスを返す
+// 同じディレクトリに同じファイル名がある場合は番号を付ける（namesには使用済みのパスを記録する）
+func uniquePath(names map[string]bool, recordID types.RecordID, field, name string) string {
+	dir := path.Join(safeName(recordID), safeName(field))
+	base := safeName(name)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	p := path.Join(dir, base)
+	for n := 2; names[p]; n++ {
+		p = path.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+	}
+	names[p] = true
+	return p
+}
+
+// safeName はファイル名として使えない文字を「_」に置き換える
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', 0:
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_" + name
+	}
+	return name
+}
+
+// attachmentDownloader は添付ファイルをダウンロードして保存する
+type attachmentDownloader struct {
+	fileClient *file.Client
+	dir        string
+}
+
+// download は添付ファイルを保存する。保存済みでサイズが一致する場合はダウンロードしない
+func (d *attachmentDownloader) download(ctx context.Context, entry *AttachmentEntry) error {
+	dest := filepath.Join(d.dir, filepath.FromSlash(entry.Path))
+	if info, err := os.Stat(dest); err == nil && info.Mode().IsRegular() {
+		if size, err := strconv.ParseInt(entry.Size, 10, 64); err != nil || size == info.Size() {
+			entry.Skipped = true
+			entry.done = true
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("ディレクトリの作成エラー: %w", err)
+	}
+	body, err := d.fileClient.Download(ctx, file.DownloadParams{FileKey: entry.FileKey})
+	if err != nil {
+		return fmt.Errorf("ダウンロードエラー: %s: %w", entry.Path, err)
+	}
+	defer body.Close()
+
+	part := dest + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return fmt.Errorf("ファイルの作成エラー: %w", err)
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(part)
+		return fmt.Errorf("ダウンロードエラー: %s: %w", entry.Path, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(part)
+		return fmt.Errorf("ファイルの書き込みエラー: %w", err)
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return fmt.Errorf("ファイルの書き込みエラー: %w", err)
+	}
+	entry.done = true
+	return nil
+}
+
+// writeManifest はマニフェストを保存先のディレクトリに書き出す
+func writeManifest(params AttachmentsParams, files []AttachmentEntry) error {
+	data, err := json.MarshalIndent(Manifest{App: params.App, Condition: params.Condition, Files: files}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("マニフェストのJSONエンコードエラー: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(params.Dir, ManifestFileName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("マニフェストの書き出しエラー: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package export_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/auth"
+	"github.com/goqoo-on-kintone/goten/export"
+	"github.com/goqoo-on-kintone/goten/file"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+const attachmentFieldsJSON = `{
+	"properties": {
+		"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名"},
+		"添付": {"type": "FILE", "code": "添付", "label": "添付"},
+		"明細": {"type": "SUBTABLE", "code": "明細", "label": "明細", "fields": {
+			"品名": {"type": "SINGLE_LINE_TEXT", "code": "品名", "label": "品名"},
+			"領収書": {"type": "FILE", "code": "領収書", "label": "領収書"}
+		}}
+	},
+	"revision": "1"
+}`
+
+const attachmentRecordsJSON = `{"records": [
+	{
+		"$id": {"type": "__ID__", "value": "1"},
+		"添付": {"type": "FILE", "value": [{"fileKey": "key-1", "name": "申請書.pdf", "contentType": "application/pdf", "size": "10"}]},
+		"明細": {"type": "SUBTABLE", "value": [
+			{"id": "101", "value": {"領収書": {"type": "FILE", "value": [{"fileKey": "key-2", "name": "領収書.png", "contentType": "image/png", "size": "10"}]}}},
+			{"id": "102", "value": {"領収書": {"type": "FILE", "value": [{"fileKey": "key-3", "name": "領収書.png", "contentType": "image/png", "size": "10"}]}}}
+		]}
+	},
+	{
+		"$id": {"type": "__ID__", "value": "2"},
+		"添付": {"type": "FILE", "value": [{"fileKey": "key-4", "name": "a/b.txt", "contentType": "text/plain", "size": "10"}]},
+		"明細": {"type": "SUBTABLE", "value": []}
+	}
+]}`
+
+// downloadAttachments はテストサーバーの添付ファイルをdirに保存する
+func downloadAttachments(serverURL, dir string) (*export.AttachmentsResult, error) {
+	httpClient := gotenhttp.NewDefaultClient(serverURL, auth.APITokenAuth{Token: "test-token"})
+	return export.Attachments(context.Background(), app.NewClient(httpClient), record.NewClient(httpClient), file.NewClient(httpClient), export.AttachmentsParams{
+		App:         "1",
+		Dir:         dir,
+		Concurrency: 2,
+	})
+}
+
+func TestAttachments(t *testing.T) {
+	// downloadsにはダウンロードされたファイルキーを記録する
+	var requestedFields, downloads []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/k/v1/app/form/fields.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(attachmentFieldsJSON))
+
+		case "/k/v1/records.json":
+			var reqBody struct {
+				Fields []string `json:"fields"`
+				Query  string   `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			requestedFields = reqBody.Fields
+			w.Header().Set("Content-Type", "application/json")
+			if strings.Contains(reqBody.Query, "$id > 0 ") {
+				w.Write([]byte(attachmentRecordsJSON))
+				return
+			}
+			w.Write([]byte(`{"records": []}`))
+
+		case "/k/v1/file.json":
+			fileKey := r.URL.Query().Get("fileKey")
+			mu.Lock()
+			downloads = append(downloads, fileKey)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("file:" + fileKey))
+
+		default:
+			t.Errorf("予期しないリクエスト: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("download", func(t *testing.T) {
+		requestedFields, downloads = nil, nil
+		dir := t.TempDir()
+
+		result, err := downloadAttachments(server.URL, dir)
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if result.Downloaded != 4 || result.Skipped != 0 {
+			t.Errorf("期待される件数: ダウンロード4件 スキップ0件, 実際: %+v", result)
+		}
+		if strings.Join(requestedFields, ",") != "添付,明細,$id" {
+			t.Errorf("期待される取得フィールド: 添付,明細,$id, 実際: %v", requestedFields)
+		}
+
+		// 同じディレクトリの同じファイル名には番号を付け、パスの区切り文字は置き換える
+		files := map[string]string{
+			"1/添付/申請書.pdf":      "file:key-1",
+			"1/領収書/領収書.png":     "file:key-2",
+			"1/領収書/領収書 (2).png": "file:key-3",
+			"2/添付/a_b.txt":      "file:key-4",
+		}
+		for p, want := range files {
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+			if err != nil {
+				t.Errorf("ファイルが保存されていない: %s: %v", p, err)
+				continue
+			}
+			if string(content) != want {
+				t.Errorf("%s: 期待される内容: %s, 実際: %s", p, want, content)
+			}
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, export.ManifestFileName))
+		if err != nil {
+			t.Fatalf("マニフェストの読み込みエラー: %v", err)
+		}
+		var manifest export.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("マニフェストの解析エラー: %v", err)
+		}
+		if manifest.App != "1" || len(manifest.Files) != 4 {
+			t.Fatalf("期待されるマニフェスト: アプリ1 ファイル4件, 実際: %+v", manifest)
+		}
+		entry := manifest.Files[2]
+		if entry.RecordID != "1" || entry.Field != "領収書" || entry.Table != "明細" || entry.RowID != "102" ||
+			entry.FileKey != "key-3" || entry.Path != "1/領収書/領収書 (2).png" {
+			t.Errorf("期待されるマニフェストの項目: 明細の行102の領収書, 実際: %+v", entry)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		requestedFields, downloads = nil, nil
+		dir := t.TempDir()
+
+		// サイズが一致する保存済みのファイルはダウンロードせず、一致しないファイルはダウンロードし直す
+		os.MkdirAll(filepath.Join(dir, "1", "添付"), 0o755)
+		os.WriteFile(filepath.Join(dir, "1", "添付", "申請書.pdf"), []byte("file:key-1"), 0o644)
+		os.MkdirAll(filepath.Join(dir, "2", "添付"), 0o755)
+		os.WriteFile(filepath.Join(dir, "2", "添付", "a_b.txt"), []byte("file:"), 0o644)
+
+		result, err := downloadAttachments(server.URL, dir)
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if result.Downloaded != 3 || result.Skipped != 1 {
+			t.Errorf("期待される件数: ダウンロード3件 スキップ1件, 実際: %+v", result)
+		}
+		for _, fileKey := range downloads {
+			if fileKey == "key-1" {
+				t.Errorf("保存済みのファイルをダウンロードした: %v", downloads)
+			}
+		}
+		content, _ := os.ReadFile(filepath.Join(dir, "2", "添付", "a_b.txt"))
+		if string(content) != "file:key-4" {
+			t.Errorf("期待される内容: file:key-4, 実際: %s", content)
+		}
+		if len(result.Files) != 4 {
+			t.Errorf("期待されるファイル数: 4, 実際: %d", len(result.Files))
+		}
+	})
+}
+
+func TestAttachmentsDownloadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/k/v1/app/form/fields.json":
+			w.Write([]byte(attachmentFieldsJSON))
+		case "/k/v1/records.json":
+			w.Write([]byte(attachmentRecordsJSON))
+		case "/k/v1/file.json":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "GAIA_BL01", "id": "error-id", "message": "指定したファイルが見つかりません。"}`))
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	result, err := downloadAttachments(server.URL, dir)
+	if err == nil || !strings.Contains(err.Error(), "GAIA_BL01") {
+		t.Fatalf("期待されるエラー: GAIA_BL01, 実際: %v", err)
+	}
+	if result == nil || result.Downloaded != 0 {
+		t.Errorf("期待される結果: ダウンロード0件, 実際: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, export.ManifestFileName)); err != nil {
+		t.Errorf("エラーの場合もマニフェストを書き出すはず: %v", err)
+	}
+}
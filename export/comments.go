@@ -0,0 +1,184 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// MentionResolver はメンション先（ユーザー・組織・グループ）のコードから表示名を解決する
+// 同じメンション先は1回だけ呼ばれる
+type MentionResolver func(ctx context.Context, mention record.MentionUser) (string, error)
+
+// CommentsParams はCommentsのパラメータ
+type CommentsParams struct {
+	App       types.AppID
+	Condition string // 対象のレコードを絞り込む条件（省略時は全レコード）
+	Order     string // コメントの順序。asc（古い順、省略時）または desc（新しい順）
+	Format    Format // 出力形式（省略時はJSONL）
+
+	// ResolveMention はメンション先の表示名を解決する（省略時は解決しない）
+	ResolveMention MentionResolver
+}
+
+// CommentEntry は書き出すコメント
+type CommentEntry struct {
+	RecordID  types.RecordID     `json:"recordId"`
+	ID        string             `json:"id"`
+	CreatedAt string             `json:"createdAt"`
+	Creator   record.CommentUser `json:"creator"`
+	Text      string             `json:"text"`
+	Mentions  []Mention          `json:"mentions"`
+}
+
+// Mention はメンション先
+type Mention struct {
+	Code string `json:"code"`
+	Type string `json:"type"`           // USER, GROUP, ORGANIZATION
+	Name string `json:"name,omitempty"` // ResolveMentionで解決した表示名
+}
+
+// commentsCSVHeader はCSV形式のヘッダー
+var commentsCSVHeader = []string{"recordId", "id", "createdAt", "creatorCode", "creatorName", "text", "mentions"}
+
+// Comments は条件に一致する全レコードのコメントをwに書き出し、書き出したコメント数を返す
+// レコードは$idの昇順、コメントはレコードごとにOrderの順で書き出す
+// CSV形式のmentions列は「表示名 <コード>」（表示名がない場合はコード）を改行区切りで出力する
+func Comments(ctx context.Context, c *record.Client, w io.Writer, params CommentsParams) (int, error) {
+	format, err := params.Format.validate()
+	if err != nil {
+		return 0, err
+	}
+	order := params.Order
+	if order == "" {
+		order = "asc"
+	}
+
+	writer := newCommentWriter(w, format)
+	if err := writer.header(); err != nil {
+		return 0, err
+	}
+
+	names := map[record.MentionUser]string{}
+	count := 0
+	for rec, err := range record.AllRecords[record.Dynamic](ctx, c, record.GetAllRecordsParams{
+		App:       params.App,
+		Fields:    []string{"$id"},
+		Condition: params.Condition,
+	}) {
+		if err != nil {
+			return count, err
+		}
+
+		for comment, err := range c.AllRecordComments(ctx, record.AllRecordCommentsParams{
+			App:    params.App,
+			Record: rec.ID(),
+			Order:  order,
+		}) {
+			if err != nil {
+				return count, fmt.Errorf("レコード%sのコメント取得エラー: %w", rec.ID(), err)
+			}
+
+			entry := CommentEntry{
+				RecordID:  rec.ID(),
+				ID:        comment.ID,
+				CreatedAt: comment.CreatedAt,
+				Creator:   comment.Creator,
+				Text:      comment.Text,
+				Mentions:  make([]Mention, len(comment.Mentions)),
+			}
+			for i, m := range comment.Mentions {
+				entry.Mentions[i] = Mention{Code: m.Code, Type: m.Type}
+				if params.ResolveMention == nil {
+					continue
+				}
+				name, ok := names[m]
+				if !ok {
+					name, err = params.ResolveMention(ctx, m)
+					if err != nil {
+						return count, fmt.Errorf("メンション先の解決エラー: %s: %w", m.Code, err)
+					}
+					names[m] = name
+				}
+				entry.Mentions[i].Name = name
+			}
+
+			if err := writer.write(entry); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	return count, writer.flush()
+}
+
+// commentWriter は出力形式ごとにコメントを書き出す
+type commentWriter struct {
+	format  Format
+	encoder *json.Encoder
+	csv     *csv.Writer
+}
+
+// newCommentWriter はcommentWriterを作成する
+func newCommentWriter(w io.Writer, format Format) *commentWriter {
+	if format == FormatCSV {
+		return &commentWriter{format: format, csv: csv.NewWriter(w)}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &commentWriter{format: format, encoder: encoder}
+}
+
+// header はヘッダーを書き出す（CSV形式のみ）
+func (cw *commentWriter) header() error {
+	if cw.csv == nil {
+		return nil
+	}
+	return cw.csv.Write(commentsCSVHeader)
+}
+
+// write はコメントを1件書き出す
+func (cw *commentWriter) write(entry CommentEntry) error {
+	if cw.csv == nil {
+		if err := cw.encoder.Encode(entry); err != nil {
+			return fmt.Errorf("書き出しエラー: %w", err)
+		}
+		return nil
+	}
+
+	mentions := make([]string, len(entry.Mentions))
+	for i, m := range entry.Mentions {
+		mentions[i] = m.Code
+		if m.Name != "" {
+			mentions[i] = fmt.Sprintf("%s <%s>", m.Name, m.Code)
+		}
+	}
+	return cw.csv.Write([]string{
+		entry.RecordID,
+		entry.ID,
+		entry.CreatedAt,
+		entry.Creator.Code,
+		entry.Creator.Name,
+		entry.Text,
+		strings.Join(mentions, "\n"),
+	})
+}
+
+// flush はバッファに残っている内容を書き出す
+func (cw *commentWriter) flush() error {
+	if cw.csv == nil {
+		return nil
+	}
+	cw.csv.Flush()
+	if err := cw.csv.Error(); err != nil {
+		return fmt.Errorf("書き出しエラー: %w", err)
+	}
+	return nil
+}
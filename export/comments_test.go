@@ -0,0 +1,147 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	"github.com/goqoo-on-kintone/goten/export"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+func TestComments(t *testing.T) {
+	// レコード2件（$id=1, 2）のうち、レコード1は12件、レコード2は1件のコメントを持ち、全コメントがuser1をメンションする
+	counts := map[string]int{"1": 12, "2": 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/k/v1/records.json":
+			var reqBody struct {
+				Query string `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if !strings.HasPrefix(reqBody.Query, `(状態 = "完了") and $id > `) {
+				t.Errorf("期待されるクエリ: (状態 = \"完了\") and $id > ..., 実際: %s", reqBody.Query)
+			}
+			records := []map[string]any{}
+			if strings.Contains(reqBody.Query, "$id > 0 ") {
+				for _, id := range []string{"1", "2"} {
+					records = append(records, map[string]any{"$id": map[string]any{"type": "__ID__", "value": id}})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records})
+
+		case "/k/v1/record/comments.json":
+			var reqBody struct {
+				Record string `json:"record"`
+				Order  string `json:"order"`
+				Offset int    `json:"offset"`
+				Limit  int    `json:"limit"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if reqBody.Order != "asc" {
+				t.Errorf("期待されるorder: asc, 実際: %s", reqBody.Order)
+			}
+
+			total := counts[reqBody.Record]
+			comments := []map[string]any{}
+			for i := reqBody.Offset; i < min(reqBody.Offset+reqBody.Limit, total); i++ {
+				comments = append(comments, map[string]any{
+					"id":        strconv.Itoa(i + 1),
+					"text":      "user1 確認お願いします <b>",
+					"createdAt": "2024-01-01T00:00:00Z",
+					"creator":   map[string]any{"code": "user2", "name": "ユーザー2"},
+					"mentions":  []map[string]any{{"code": "user1", "type": "USER"}},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"comments": comments,
+				"newer":    reqBody.Offset+reqBody.Limit < total,
+			})
+		}
+	}))
+	defer server.Close()
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	t.Run("jsonl", func(t *testing.T) {
+		resolveCount := 0
+		var buf bytes.Buffer
+		n, err := export.Comments(context.Background(), client, &buf, export.CommentsParams{
+			App:       "1",
+			Condition: `状態 = "完了"`,
+			ResolveMention: func(ctx context.Context, m record.MentionUser) (string, error) {
+				resolveCount++
+				return "ユーザー1", nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if n != 13 {
+			t.Errorf("期待されるコメント数: 13, 実際: %d", n)
+		}
+		if resolveCount != 1 {
+			t.Errorf("同じメンション先の解決は1回のはずが: %d回", resolveCount)
+		}
+
+		var entries []export.CommentEntry
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var entry export.CommentEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("JSONLの行を解析できない: %s: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) != 13 {
+			t.Fatalf("期待される行数: 13, 実際: %d", len(entries))
+		}
+		last := entries[12]
+		if last.RecordID != "2" || last.ID != "1" || last.Creator.Code != "user2" {
+			t.Errorf("最後の行が不正: %+v", last)
+		}
+		if len(last.Mentions) != 1 || last.Mentions[0].Name != "ユーザー1" {
+			t.Errorf("メンション先の表示名が解決されていない: %+v", last.Mentions)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := export.Comments(context.Background(), client, &buf, export.CommentsParams{
+			App:       "1",
+			Condition: `状態 = "完了"`,
+			Format:    export.FormatCSV,
+		})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("CSVを解析できない: %v", err)
+		}
+		if len(rows) != 14 {
+			t.Fatalf("期待される行数: 14（ヘッダーを含む）, 実際: %d", len(rows))
+		}
+		if strings.Join(rows[0], ",") != "recordId,id,createdAt,creatorCode,creatorName,text,mentions" {
+			t.Errorf("ヘッダーが不正: %v", rows[0])
+		}
+		if rows[1][0] != "1" || rows[1][5] != "user1 確認お願いします <b>" || rows[1][6] != "user1" {
+			t.Errorf("1行目が不正: %v", rows[1])
+		}
+
+		if _, err := export.Comments(context.Background(), client, &buf, export.CommentsParams{App: "1", Format: "xml"}); err == nil {
+			t.Error("未対応の出力形式でエラーが発生しない")
+		}
+	})
+}
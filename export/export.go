@@ -0,0 +1,98 @@
+// Package export はkintoneのデータをファイル形式で書き出す機能を提供する
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// Format は出力形式
+type Format string
+
+// 出力形式
+const (
+	FormatJSONL Format = "jsonl" // 1行に1件のJSON
+	FormatCSV   Format = "csv"   // CSV（RFC 4180）
+)
+
+// validate は出力形式を確認し、省略時はJSONLにする
+func (f Format) validate() (Format, error) {
+	switch f {
+	case "":
+		return FormatJSONL, nil
+	case FormatJSONL, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("未対応の出力形式です: %s", f)
+	}
+}
+
+// Encoding はCSVの文字コード
+type Encoding string
+
+// 文字コード
+const (
+	EncodingUTF8     Encoding = "utf-8"
+	EncodingShiftJIS Encoding = "shift_jis" // Windows-31J（日本語版Excel向け）
+)
+
+// utf8BOM はUTF-8のバイトオーダーマーク
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// encodeWriter は文字コードを変換してwに書き出すio.WriteCloserを返す
+// Shift_JISで表せない文字は書き出しエラーになるため、事前にencodeCellで確認・置換する
+// Closeで変換途中の内容を書き出す（wは閉じない）
+func encodeWriter(w io.Writer, enc Encoding, bom bool) (io.WriteCloser, error) {
+	switch enc {
+	case "", EncodingUTF8:
+		if bom {
+			if _, err := w.Write(utf8BOM); err != nil {
+				return nil, fmt.Errorf("書き出しエラー: %w", err)
+			}
+		}
+		return nopCloser{w}, nil
+	case EncodingShiftJIS:
+		if bom {
+			return nil, fmt.Errorf("BOMはUTF-8の場合のみ指定できます")
+		}
+		return transform.NewWriter(w, japanese.ShiftJIS.NewEncoder()), nil
+	default:
+		return nil, fmt.Errorf("未対応の文字コードです: %s", enc)
+	}
+}
+
+// encodeCell はセルの文字列をencで表せるかを確認する
+// 表せない文字がある場合、replaceの場合は「?」に置き換え、それ以外の場合はその文字を示すエラーを返す
+func encodeCell(enc Encoding, s string, replace bool) (string, error) {
+	if enc != EncodingShiftJIS {
+		return s, nil
+	}
+	encoder := japanese.ShiftJIS.NewEncoder()
+	if _, err := encoder.String(s); err == nil {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if _, err := encoder.String(string(r)); err != nil {
+			if !replace {
+				return "", fmt.Errorf("Shift_JISで表せない文字があります: %q", r)
+			}
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// nopCloser は何もしないCloseを持つio.WriteCloser
+type nopCloser struct {
+	io.Writer
+}
+
+// Close は何もしない
+func (nopCloser) Close() error { return nil }
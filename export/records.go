@@ -0,0 +1,404 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// HeaderType はCSVのヘッダーに出力する名前
+type HeaderType string
+
+// ヘッダーに出力する名前
+const (
+	HeaderLabel HeaderType = "label" // フィールド名
+	HeaderCode  HeaderType = "code"  // フィールドコード
+)
+
+// RecordStartHeader はテーブルを含むCSVの先頭列のヘッダー
+// レコードの最初の行に「*」を出力する（kintoneのCSV読み込みと同じ形式）
+const RecordStartHeader = "レコードの開始行"
+
+// dateTimeCSVFormat はCSVに出力する日時の形式
+const dateTimeCSVFormat = "2006-01-02 15:04"
+
+// RecordsCSVParams はRecordsCSVのパラメータ
+type RecordsCSVParams struct {
+	App       types.AppID
+	Condition string // 対象のレコードを絞り込む条件（省略時は全レコード）
+	OrderBy   string // 並び順（省略時は$idの昇順）
+
+	// Fields は出力するフィールドコード（省略時はフォームの全フィールドを配置順に出力する）
+	// テーブルのフィールドコードを指定するとテーブル内の全フィールドを出力する
+	Fields []string
+
+	Header   HeaderType // ヘッダーに出力する名前（省略時はフィールド名）
+	Encoding Encoding   // 文字コード（省略時はUTF-8）
+	BOM      bool       // 先頭にBOMを付ける（UTF-8の場合のみ）
+
+	// ReplaceUnsupported はShift_JISで表せない文字を「?」に置き換える（省略時はエラー）
+	ReplaceUnsupported bool
+
+	UserName bool           // ユーザー・組織・グループを表示名で出力する（省略時はコード）
+	Location *time.Location // 日時を出力するタイムゾーン（省略時はtime.Local）
+}
+
+// csvColumn はCSVの列
+type csvColumn struct {
+	code      string
+	label     string
+	fieldType string
+	table     string // テーブル内のフィールドとテーブルの行IDの列は、テーブルのフィールドコード
+}
+
+// unexportableFieldTypes はCSVに出力できないフィールドタイプ
+var unexportableFieldTypes = []string{"GROUP", "REFERENCE_TABLE", "LABEL", "SPACER", "HR"}
+
+// RecordsCSV は条件に一致するレコードをCSV形式でwに書き出し、書き出したレコード数を返す
+// フォームのフィールド定義をもとに、kintoneのCSV書き出しと同じ形式で出力する
+//   - 複数の値を持つフィールド（チェックボックス、ユーザー選択、添付ファイルなど）は改行区切りで1つのセルに出力する
+//   - テーブルは1行を1行として出力し、テーブル以外のフィールドは各行に繰り返す。先頭列はレコードの最初の行のみ「*」
+//   - テーブルの列の前には行IDの列を出力する（ヘッダーはテーブルの名前）
+//   - 日時は「YYYY-MM-DD HH:MM」で出力する
+//
+// Shift_JISで表せない文字がある場合は、そのレコードとフィールドを示すエラーを返す（ReplaceUnsupportedの場合は「?」に置き換える）
+func RecordsCSV(ctx context.Context, appClient *app.Client, recordClient *record.Client, w io.Writer, params RecordsCSVParams) (int, error) {
+	location := params.Location
+	if location == nil {
+		location = time.Local
+	}
+	header := params.Header
+	if header == "" {
+		header = HeaderLabel
+	}
+	if header != HeaderLabel && header != HeaderCode {
+		return 0, fmt.Errorf("未対応のヘッダーです: %s", header)
+	}
+
+	fields, err := appClient.GetFormFields(ctx, app.GetFormFieldsParams{App: params.App})
+	if err != nil {
+		return 0, err
+	}
+	var layout *app.GetFormLayoutResult
+	if len(params.Fields) == 0 {
+		layout, err = appClient.GetFormLayout(ctx, app.GetFormLayoutParams{App: params.App})
+		if err != nil {
+			return 0, err
+		}
+	}
+	columns, err := csvColumns(fields, layout, params.Fields)
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, err := encodeWriter(w, params.Encoding, params.BOM)
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(encoded)
+	cw.UseCRLF = true
+
+	hasTable := slices.ContainsFunc(columns, func(col csvColumn) bool { return col.table != "" })
+	var headerRow []string
+	if hasTable {
+		headerRow = append(headerRow, RecordStartHeader)
+	}
+	var recordFields []string
+	for _, col := range columns {
+		if header == HeaderCode {
+			headerRow = append(headerRow, col.code)
+		} else {
+			headerRow = append(headerRow, col.label)
+		}
+		code := col.code
+		if col.table != "" {
+			code = col.table
+		}
+		if !slices.Contains(recordFields, code) {
+			recordFields = append(recordFields, code)
+		}
+	}
+	for i, name := range headerRow {
+		if headerRow[i], err = encodeCell(params.Encoding, name, params.ReplaceUnsupported); err != nil {
+			return 0, fmt.Errorf("ヘッダーの%s: %w", name, err)
+		}
+	}
+	if err := cw.Write(headerRow); err != nil {
+		return 0, fmt.Errorf("書き出しエラー: %w", err)
+	}
+
+	formatter := csvFormatter{userName: params.UserName, location: location}
+	count := 0
+	for rec, err := range record.AllRecords[record.Dynamic](ctx, recordClient, record.GetAllRecordsParams{
+		App:       params.App,
+		Fields:    recordFields,
+		Condition: params.Condition,
+		OrderBy:   params.OrderBy,
+	}) {
+		if err != nil {
+			return count, err
+		}
+		rows, err := formatter.rows(rec, columns, hasTable)
+		if err != nil {
+			return count, fmt.Errorf("レコード%sの変換エラー: %w", rec.ID(), err)
+		}
+		if err := encodeRows(rows, columns, hasTable, params.Encoding, params.ReplaceUnsupported); err != nil {
+			return count, fmt.Errorf("レコード%sの%w", rec.ID(), err)
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return count, fmt.Errorf("書き出しエラー: %w", err)
+		}
+		count++
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return count, fmt.Errorf("書き出しエラー: %w", err)
+	}
+	if err := encoded.Close(); err != nil {
+		return count, fmt.Errorf("書き出しエラー: %w", err)
+	}
+	return count, nil
+}
+
+// encodeRows はCSVの行の各セルをencodeCellで確認・置換する
+func encodeRows(rows [][]string, columns []csvColumn, hasTable bool, enc Encoding, replace bool) error {
+	offset := 0
+	if hasTable {
+		offset = 1
+	}
+	for _, row := range rows {
+		for i, col := range columns {
+			value, err := encodeCell(enc, row[offset+i], replace)
+			if err != nil {
+				return fmt.Errorf("フィールド%s: %w", col.code, err)
+			}
+			row[offset+i] = value
+		}
+	}
+	return nil
+}
+
+// csvColumns は出力する列を返す
+// codesが空の場合はフォームの配置順（配置されていないフィールドはフィールドコード順で最後）にする
+func csvColumns(fields *app.GetFormFieldsResult, layout *app.GetFormLayoutResult, codes []string) ([]csvColumn, error) {
+	// テーブル内のフィールドコードからテーブルのフィールドコードを引く
+	tableOf := map[string]string{}
+	for code, prop := range fields.Properties {
+		for inner := range prop.Fields {
+			tableOf[inner] = code
+		}
+	}
+
+	innerOrder := map[string][]string{}
+	if len(codes) == 0 {
+		codes, innerOrder = layoutOrder(fields, layout)
+	}
+
+	var columns []csvColumn
+	tableAdded := map[string]bool{}
+	addTable := func(table string) {
+		if !tableAdded[table] {
+			tableAdded[table] = true
+			prop := fields.Properties[table]
+			columns = append(columns, csvColumn{code: table, label: prop.Label, fieldType: prop.Type, table: table})
+		}
+	}
+
+	for _, code := range codes {
+		if table, ok := tableOf[code]; ok {
+			addTable(table)
+			prop := fields.Properties[table].Fields[code]
+			columns = append(columns, csvColumn{code: code, label: prop.Label, fieldType: prop.Type, table: table})
+			continue
+		}
+
+		prop, ok := fields.Properties[code]
+		if !ok {
+			return nil, fmt.Errorf("フィールドが存在しません: %s", code)
+		}
+		if slices.Contains(unexportableFieldTypes, prop.Type) {
+			return nil, fmt.Errorf("%sフィールドは出力できません: %s", prop.Type, code)
+		}
+		if prop.Type != types.FieldTypeSubtable {
+			columns = append(columns, csvColumn{code: code, label: prop.Label, fieldType: prop.Type})
+			continue
+		}
+
+		addTable(code)
+		inner := innerOrder[code]
+		if inner == nil {
+			for innerCode := range prop.Fields {
+				inner = append(inner, innerCode)
+			}
+			sort.Strings(inner)
+		}
+		for _, innerCode := range inner {
+			innerProp := prop.Fields[innerCode]
+			columns = append(columns, csvColumn{code: innerCode, label: innerProp.Label, fieldType: innerProp.Type, table: code})
+		}
+	}
+	return columns, nil
+}
+
+// layoutOrder はフォームの配置順のフィールドコードと、テーブルごとのテーブル内のフィールドコードを返す
+func layoutOrder(fields *app.GetFormFieldsResult, layout *app.GetFormLayoutResult) ([]string, map[string][]string) {
+	var codes []string
+	innerOrder := map[string][]string{}
+	seen := map[string]bool{}
+
+	var walk func(elements []app.LayoutElement)
+	walk = func(elements []app.LayoutElement) {
+		for _, element := range elements {
+			switch element.Type {
+			case "SUBTABLE":
+				for _, f := range element.Fields {
+					innerOrder[element.Code] = append(innerOrder[element.Code], f.Code)
+				}
+				codes = append(codes, element.Code)
+				seen[element.Code] = true
+			case "GROUP":
+				walk(element.Layout)
+			default:
+				for _, f := range element.Fields {
+					if prop, ok := fields.Properties[f.Code]; ok && !slices.Contains(unexportableFieldTypes, prop.Type) {
+						codes = append(codes, f.Code)
+						seen[f.Code] = true
+					}
+				}
+			}
+		}
+	}
+	if layout != nil {
+		walk(layout.Layout)
+	}
+
+	var rest []string
+	for code, prop := range fields.Properties {
+		if !seen[code] && !slices.Contains(unexportableFieldTypes, prop.Type) {
+			rest = append(rest, code)
+		}
+	}
+	sort.Strings(rest)
+	return append(codes, rest...), innerOrder
+}
+
+// csvFormatter はフィールド値をCSVのセルの文字列に変換する
+type csvFormatter struct {
+	userName bool
+	location *time.Location
+}
+
+// rows はレコードをCSVの行に変換する（テーブルの行数分、最低1行）
+func (f csvFormatter) rows(rec record.Dynamic, columns []csvColumn, hasTable bool) ([][]string, error) {
+	tables := map[string][]record.DynamicRow{}
+	n := 1
+	for _, col := range columns {
+		if col.table == "" || col.code != col.table || !rec.Has(col.table) {
+			continue
+		}
+		rows, err := rec.Subtable(col.table)
+		if err != nil {
+			return nil, err
+		}
+		tables[col.table] = rows
+		n = max(n, len(rows))
+	}
+
+	result := make([][]string, n)
+	for i := range result {
+		var row []string
+		if hasTable {
+			if i == 0 {
+				row = append(row, "*")
+			} else {
+				row = append(row, "")
+			}
+		}
+		for _, col := range columns {
+			if col.table == "" {
+				value, err := f.format(rec, col.code)
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, value)
+				continue
+			}
+
+			tableRows := tables[col.table]
+			switch {
+			case i >= len(tableRows):
+				row = append(row, "")
+			case col.code == col.table:
+				row = append(row, tableRows[i].ID)
+			default:
+				value, err := f.format(tableRows[i].Record, col.code)
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, value)
+			}
+		}
+		result[i] = row
+	}
+	return result, nil
+}
+
+// format はフィールド値をフィールドタイプに応じた文字列に変換する（フィールドがない場合は空文字列）
+func (f csvFormatter) format(rec record.Dynamic, code string) (string, error) {
+	if !rec.Has(code) {
+		return "", nil
+	}
+
+	switch rec.Type(code) {
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeCategory:
+		values, err := rec.Strings(code)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(values, "\n"), nil
+
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect,
+		types.FieldTypeStatusAssignee, types.FieldTypeCreator, types.FieldTypeModifier:
+		entities, err := rec.Users(code)
+		if err != nil {
+			return "", err
+		}
+		names := make([]string, len(entities))
+		for i, entity := range entities {
+			names[i] = entity.Code
+			if f.userName && entity.Name != "" {
+				names[i] = entity.Name
+			}
+		}
+		return strings.Join(names, "\n"), nil
+
+	case types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime:
+		t, err := rec.Time(code)
+		if err != nil || t.IsZero() {
+			return "", err
+		}
+		return t.In(f.location).Format(dateTimeCSVFormat), nil
+
+	case types.FieldTypeFile:
+		files, err := rec.Files(code)
+		if err != nil {
+			return "", err
+		}
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = file.Name
+		}
+		return strings.Join(names, "\n"), nil
+	}
+	return rec.String(code)
+}
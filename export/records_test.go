@@ -0,0 +1,236 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/auth"
+	"github.com/goqoo-on-kintone/goten/export"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const formFieldsJSON = `{
+	"properties": {
+		"レコード番号": {"type": "RECORD_NUMBER", "code": "レコード番号", "label": "レコード番号"},
+		"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名"},
+		"金額": {"type": "NUMBER", "code": "金額", "label": "金額"},
+		"タグ": {"type": "CHECK_BOX", "code": "タグ", "label": "タグ"},
+		"担当者": {"type": "USER_SELECT", "code": "担当者", "label": "担当者"},
+		"更新日時": {"type": "UPDATED_TIME", "code": "更新日時", "label": "更新日時"},
+		"グループ": {"type": "GROUP", "code": "グループ", "label": "グループ"},
+		"明細": {"type": "SUBTABLE", "code": "明細", "label": "明細", "fields": {
+			"品名": {"type": "SINGLE_LINE_TEXT", "code": "品名", "label": "品名"},
+			"単価": {"type": "NUMBER", "code": "単価", "label": "単価"}
+		}}
+	},
+	"revision": "1"
+}`
+
+const formLayoutJSON = `{
+	"layout": [
+		{"type": "ROW", "fields": [{"type": "RECORD_NUMBER", "code": "レコード番号"}, {"type": "SINGLE_LINE_TEXT", "code": "件名"}]},
+		{"type": "GROUP", "code": "グループ", "layout": [
+			{"type": "ROW", "fields": [{"type": "NUMBER", "code": "金額"}, {"type": "CHECK_BOX", "code": "タグ"}]}
+		]},
+		{"type": "SUBTABLE", "code": "明細", "fields": [{"type": "SINGLE_LINE_TEXT", "code": "品名"}, {"type": "NUMBER", "code": "単価"}]},
+		{"type": "ROW", "fields": [{"type": "USER_SELECT", "code": "担当者"}]}
+	],
+	"revision": "1"
+}`
+
+const exportRecordsJSON = `{"records": [
+	{
+		"$id": {"type": "__ID__", "value": "1"},
+		"レコード番号": {"type": "RECORD_NUMBER", "value": "1"},
+		"件名": {"type": "SINGLE_LINE_TEXT", "value": "出張費"},
+		"金額": {"type": "NUMBER", "value": "12000"},
+		"タグ": {"type": "CHECK_BOX", "value": ["至急", "海外"]},
+		"担当者": {"type": "USER_SELECT", "value": [{"code": "user1", "name": "佐藤"}, {"code": "user2", "name": "鈴木"}]},
+		"更新日時": {"type": "UPDATED_TIME", "value": "2024-04-01T00:30:00Z"},
+		"明細": {"type": "SUBTABLE", "value": [
+			{"id": "101", "value": {"品名": {"type": "SINGLE_LINE_TEXT", "value": "航空券"}, "単価": {"type": "NUMBER", "value": "10000"}}},
+			{"id": "102", "value": {"品名": {"type": "SINGLE_LINE_TEXT", "value": "宿泊"}, "単価": {"type": "NUMBER", "value": "2000"}}}
+		]}
+	},
+	{
+		"$id": {"type": "__ID__", "value": "2"},
+		"レコード番号": {"type": "RECORD_NUMBER", "value": "2"},
+		"件名": {"type": "SINGLE_LINE_TEXT", "value": "文具"},
+		"金額": {"type": "NUMBER", "value": ""},
+		"タグ": {"type": "CHECK_BOX", "value": []},
+		"担当者": {"type": "USER_SELECT", "value": []},
+		"更新日時": {"type": "UPDATED_TIME", "value": "2024-04-02T00:00:00Z"},
+		"明細": {"type": "SUBTABLE", "value": []}
+	}
+]}`
+
+func TestRecordsCSV(t *testing.T) {
+	var requestedFields []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/k/v1/app/form/fields.json":
+			w.Write([]byte(formFieldsJSON))
+		case "/k/v1/app/form/layout.json":
+			w.Write([]byte(formLayoutJSON))
+		case "/k/v1/records.json":
+			var reqBody struct {
+				Query  string   `json:"query"`
+				Fields []string `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			requestedFields = reqBody.Fields
+			if strings.Contains(reqBody.Query, "$id > 0 ") {
+				w.Write([]byte(exportRecordsJSON))
+				return
+			}
+			w.Write([]byte(`{"records": []}`))
+		default:
+			t.Errorf("予期しないリクエスト: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	appClient, recordClient := app.NewClient(httpClient), record.NewClient(httpClient)
+
+	var buf bytes.Buffer
+	n, err := export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:      "1",
+		BOM:      true,
+		Location: time.FixedZone("JST", 9*60*60),
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("期待されるレコード数: 2, 実際: %d", n)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0xEF, 0xBB, 0xBF}) {
+		t.Error("BOMが付いていない")
+	}
+	if !strings.Contains(buf.String(), "\r\n") {
+		t.Error("改行がCRLFになっていない")
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(buf.Bytes()[3:])).ReadAll()
+	if err != nil {
+		t.Fatalf("CSVを解析できない: %v", err)
+	}
+	want := [][]string{
+		{"レコードの開始行", "レコード番号", "件名", "金額", "タグ", "明細", "品名", "単価", "担当者", "更新日時"},
+		{"*", "1", "出張費", "12000", "至急\n海外", "101", "航空券", "10000", "user1\nuser2", "2024-04-01 09:30"},
+		{"", "1", "出張費", "12000", "至急\n海外", "102", "宿泊", "2000", "user1\nuser2", "2024-04-01 09:30"},
+		{"*", "2", "文具", "", "", "", "", "", "", "2024-04-02 09:00"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("期待される行数: %d, 実際: %d (%q)", len(want), len(rows), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("%d行目\n期待: %q\n実際: %q", i, want[i], rows[i])
+		}
+	}
+	if !strings.HasPrefix(strings.Join(requestedFields, ","), "レコード番号,件名,金額,タグ,明細,担当者,更新日時") {
+		t.Errorf("取得するフィールドが不正: %v", requestedFields)
+	}
+
+	// Shift_JIS・フィールドコードのヘッダー・ユーザー名で出力する
+	buf.Reset()
+	_, err = export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:      "1",
+		Fields:   []string{"件名", "担当者"},
+		Header:   export.HeaderCode,
+		Encoding: export.EncodingShiftJIS,
+		UserName: true,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Shift_JISとして読み込めない: %v", err)
+	}
+	if want := "件名,担当者\r\n出張費,\"佐藤\r\n鈴木\"\r\n文具,\r\n"; string(decoded) != want {
+		t.Errorf("期待される出力: %q, 実際: %q", want, decoded)
+	}
+
+	_, err = export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:      "1",
+		Encoding: export.EncodingShiftJIS,
+		BOM:      true,
+	})
+	if err == nil {
+		t.Error("Shift_JISでBOMを指定してもエラーが発生しない")
+	}
+	_, err = export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:    "1",
+		Fields: []string{"グループ"},
+	})
+	if err == nil {
+		t.Error("出力できないフィールドを指定してもエラーが発生しない")
+	}
+}
+
+func TestRecordsCSVShiftJISUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/k/v1/app/form/fields.json":
+			w.Write([]byte(formFieldsJSON))
+		case "/k/v1/records.json":
+			var reqBody struct {
+				Query string `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if !strings.Contains(reqBody.Query, "$id > 0 ") {
+				w.Write([]byte(`{"records": []}`))
+				return
+			}
+			w.Write([]byte(`{"records": [{
+				"$id": {"type": "__ID__", "value": "5"},
+				"件名": {"type": "SINGLE_LINE_TEXT", "value": "𠮷野家"},
+				"金額": {"type": "NUMBER", "value": "980"}
+			}]}`))
+		}
+	}))
+	defer server.Close()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	appClient, recordClient := app.NewClient(httpClient), record.NewClient(httpClient)
+
+	// Shift_JISで表せない文字はエラーにする
+	var buf bytes.Buffer
+	_, err := export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:      "1",
+		Fields:   []string{"金額", "件名"},
+		Encoding: export.EncodingShiftJIS,
+	})
+	if err == nil || !strings.Contains(err.Error(), "レコード5のフィールド件名") {
+		t.Errorf("期待されるエラー: レコード5のフィールド件名, 実際: %v", err)
+	}
+
+	// ReplaceUnsupportedの場合は「?」に置き換える
+	buf.Reset()
+	if _, err := export.RecordsCSV(context.Background(), appClient, recordClient, &buf, export.RecordsCSVParams{
+		App:                "1",
+		Fields:             []string{"金額", "件名"},
+		Encoding:           export.EncodingShiftJIS,
+		ReplaceUnsupported: true,
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	decoded, _ := japanese.ShiftJIS.NewDecoder().Bytes(buf.Bytes())
+	if want := "金額,件名\r\n980,?野家\r\n"; string(decoded) != want {
+		t.Errorf("期待される出力: %q, 実際: %q", want, decoded)
+	}
+}
@@ -5,4 +5,5 @@ go 1.25.0
 require (
 	github.com/goqoo-on-kintone/gotenks v0.3.1
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/text v0.30.0
 )
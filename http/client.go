@@ -39,12 +39,47 @@ func NewDefaultClient(baseURL string, a auth.Auth) *DefaultClient {
 	}
 }
 
-// buildPath はAPIパスを構築する
+// buildPath はAPIのURLを構築する
 func (c *DefaultClient) buildPath(endpointName string) string {
+	return c.BaseURL + c.APIPath(endpointName)
+}
+
+// APIPath はドメインを含まないAPIパスを返す
+// バルクリクエストの各リクエストに指定するパスとして使用する
+func (c *DefaultClient) APIPath(endpointName string) string {
 	if c.GuestSpaceID != nil {
-		return fmt.Sprintf("%s/k/guest/%d/v1/%s.json", c.BaseURL, *c.GuestSpaceID, endpointName)
+		return fmt.Sprintf("/k/guest/%d/v1/%s.json", *c.GuestSpaceID, endpointName)
 	}
-	return fmt.Sprintf("%s/k/v1/%s.json", c.BaseURL, endpointName)
+	return fmt.Sprintf("/k/v1/%s.json", endpointName)
+}
+
+// bulkErrorResponse はバルクリクエスト失敗時のレスポンス
+type bulkErrorResponse struct {
+	Results []json.RawMessage `json:"results"`
+}
+
+// parseErrorResponse はエラーレスポンスをエラー型に変換する
+// バルクリクエストの場合は失敗したリクエストのエラーを取り出す
+func parseErrorResponse(status int, body []byte) error {
+	var bulkErr bulkErrorResponse
+	if err := json.Unmarshal(body, &bulkErr); err == nil && len(bulkErr.Results) > 0 {
+		for i, raw := range bulkErr.Results {
+			var apiErr kintoneError.KintoneRestAPIError
+			if err := json.Unmarshal(raw, &apiErr); err != nil || apiErr.Code == "" {
+				continue
+			}
+			apiErr.Status = status
+			apiErr.BulkRequestIndex = &i
+			return &apiErr
+		}
+	}
+
+	var apiErr kintoneError.KintoneRestAPIError
+	if err := json.Unmarshal(body, &apiErr); err == nil {
+		apiErr.Status = status
+		return &apiErr
+	}
+	return fmt.Errorf("APIエラー (status=%d): %s", status, string(body))
 }
 
 // do はHTTPリクエストを実行する
@@ -64,12 +99,7 @@ func (c *DefaultClient) do(req *http.Request) ([]byte, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var apiErr kintoneError.KintoneRestAPIError
-		if err := json.Unmarshal(body, &apiErr); err == nil {
-			apiErr.Status = resp.StatusCode
-			return nil, &apiErr
-		}
-		return nil, fmt.Errorf("APIエラー (status=%d): %s", resp.StatusCode, string(body))
+		return nil, parseErrorResponse(resp.StatusCode, body)
 	}
 
 	return body, nil
@@ -215,12 +245,7 @@ func (c *DefaultClient) PostMultipart(ctx context.Context, path string, fileName
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var apiErr kintoneError.KintoneRestAPIError
-		if err := json.Unmarshal(body, &apiErr); err == nil {
-			apiErr.Status = resp.StatusCode
-			return nil, &apiErr
-		}
-		return nil, fmt.Errorf("APIエラー (status=%d): %s", resp.StatusCode, string(body))
+		return nil, parseErrorResponse(resp.StatusCode, body)
 	}
 
 	return body, nil
@@ -249,12 +274,7 @@ func (c *DefaultClient) GetFile(ctx context.Context, path string, fileKey string
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
-		var apiErr kintoneError.KintoneRestAPIError
-		if err := json.Unmarshal(body, &apiErr); err == nil {
-			apiErr.Status = resp.StatusCode
-			return nil, &apiErr
-		}
-		return nil, fmt.Errorf("APIエラー (status=%d): %s", resp.StatusCode, string(body))
+		return nil, parseErrorResponse(resp.StatusCode, body)
 	}
 
 	return resp.Body, nil
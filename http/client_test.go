@@ -3,6 +3,7 @@ package http_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
 	gotenhttp "github.com/goqoo-on-kintone/goten/http"
 )
 
@@ -209,6 +211,42 @@ func TestAPIErrorHandling(t *testing.T) {
 	}
 }
 
+func TestBulkRequestErrorHandling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"results": [{}, {"code": "GAIA_RE01", "id": "error-id", "message": "レコードが見つかりません"}, {}]}`))
+	}))
+	defer server.Close()
+
+	client := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test"})
+	ctx := context.Background()
+	_, err := client.Post(ctx, "bulkRequest", map[string]any{})
+
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("KintoneRestAPIErrorが返るはずが: %v", err)
+	}
+	if apiErr.Code != "GAIA_RE01" {
+		t.Errorf("期待されるコード: GAIA_RE01, 実際: %s", apiErr.Code)
+	}
+	if apiErr.BulkRequestIndex == nil || *apiErr.BulkRequestIndex != 1 {
+		t.Errorf("期待されるBulkRequestIndex: 1, 実際: %v", apiErr.BulkRequestIndex)
+	}
+}
+
+func TestAPIPath(t *testing.T) {
+	client := gotenhttp.NewDefaultClient("https://example.cybozu.com", auth.APITokenAuth{Token: "test"})
+	if got := client.APIPath("records"); got != "/k/v1/records.json" {
+		t.Errorf("期待されるパス: /k/v1/records.json, 実際: %s", got)
+	}
+
+	client.GuestSpaceID = intPtr(5)
+	if got := client.APIPath("records"); got != "/k/guest/5/v1/records.json" {
+		t.Errorf("期待されるパス: /k/guest/5/v1/records.json, 実際: %s", got)
+	}
+}
+
 // ヘルパー関数
 func intPtr(i int) *int {
 	return &i
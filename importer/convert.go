@@ -0,0 +1,474 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/export"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// 入力の値として受け付ける日付・時刻・日時の形式
+var (
+	dateLayouts     = []string{"2006-01-02", "2006/01/02", "2006/1/2", "2006-1-2"}
+	timeLayouts     = []string{"15:04", "15:04:05", "3:04"}
+	dateTimeLayouts = []string{"2006-01-02 15:04", "2006/01/02 15:04", "2006-01-02 15:04:05", "2006/01/02 15:04:05", "2006/1/2 15:04"}
+)
+
+// writableFieldTypes は書き込みできるフィールドタイプ（テーブルを除く）
+var writableFieldTypes = []string{
+	types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText, types.FieldTypeLink,
+	types.FieldTypeNumber, types.FieldTypeDate, types.FieldTypeTime, types.FieldTypeDateTime,
+	types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeDropDown, types.FieldTypeRadioButton,
+	types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect,
+}
+
+// fieldRef は列に対応するフィールド
+type fieldRef struct {
+	code  string // フィールドコード（空の場合は読み込まない列）
+	table string // テーブル内のフィールドの場合はテーブルのフィールドコード
+}
+
+// converter は入力の行をフィールド定義に従ってレコードに変換する
+type converter struct {
+	props    map[string]app.FieldProperty
+	tableOf  map[string]string   // テーブル内のフィールドコード → テーブルのフィールドコード
+	labels   map[string][]string // フィールド名 → フィールドコード
+	mapping  map[string]string
+	ignored  map[string]bool // フィールドに対応しなくてもエラーにしない列
+	location *time.Location
+}
+
+// newConverter はconverterを作成する
+func newConverter(fields *app.GetFormFieldsResult, params RecordsParams) *converter {
+	c := &converter{
+		props:    fields.Properties,
+		tableOf:  map[string]string{},
+		labels:   map[string][]string{},
+		mapping:  params.Mapping,
+		ignored:  map[string]bool{export.RecordStartHeader: true},
+		location: params.Location,
+	}
+	if c.location == nil {
+		c.location = time.Local
+	}
+	if params.GroupBy != "" {
+		c.ignored[params.GroupBy] = true
+	}
+	for code, prop := range fields.Properties {
+		c.labels[prop.Label] = append(c.labels[prop.Label], code)
+		for inner, innerProp := range prop.Fields {
+			c.tableOf[inner] = code
+			c.labels[innerProp.Label] = append(c.labels[innerProp.Label], inner)
+		}
+	}
+	return c
+}
+
+// property はフィールドコードのフィールド定義を返す（テーブル内のフィールドを含む）
+func (c *converter) property(code string) app.FieldProperty {
+	if table, ok := c.tableOf[code]; ok {
+		return c.props[table].Fields[code]
+	}
+	return c.props[code]
+}
+
+// resolve は列名に対応するフィールドを返す
+// Mapping、フィールドコード、フィールド名（一意の場合）の順に探す
+func (c *converter) resolve(column string) (fieldRef, error) {
+	code, mapped := c.mapping[column]
+	if !mapped {
+		code = column
+		if _, ok := c.props[code]; !ok {
+			if _, ok := c.tableOf[code]; !ok {
+				switch codes := c.labels[column]; {
+				case len(codes) == 1:
+					code = codes[0]
+				case c.ignored[column]:
+					return fieldRef{}, nil
+				case len(codes) > 1:
+					return fieldRef{}, fmt.Errorf("同じフィールド名のフィールドが複数あります。Mappingでフィールドコードを指定してください")
+				default:
+					return fieldRef{}, fmt.Errorf("対応するフィールドがありません")
+				}
+			}
+		}
+	}
+	if code == "" {
+		return fieldRef{}, nil
+	}
+
+	if table, ok := c.tableOf[code]; ok {
+		return fieldRef{code: code, table: table}, c.checkWritable(code)
+	}
+	prop, ok := c.props[code]
+	if !ok {
+		return fieldRef{}, fmt.Errorf("フィールドが存在しません: %s", code)
+	}
+	if prop.Type == types.FieldTypeSubtable {
+		// テーブルのフィールドコードの列は行IDとして扱う
+		return fieldRef{code: code, table: code}, nil
+	}
+	return fieldRef{code: code}, c.checkWritable(code)
+}
+
+// checkWritable は書き込みできるフィールドかどうかを確認する
+func (c *converter) checkWritable(code string) error {
+	if prop := c.property(code); !slices.Contains(writableFieldTypes, prop.Type) {
+		return fmt.Errorf("%sフィールドには書き込みできません: %s", prop.Type, code)
+	}
+	return nil
+}
+
+// tableRow は変換中のテーブルの行
+type tableRow struct {
+	id     string
+	values map[string]types.FieldValue
+}
+
+// pendingRow はCSVの1行から読み込んだテーブルの行（変換前）
+type pendingRow struct {
+	id     string
+	cells  []pendingCell
+	filled bool // 行IDまたは値が入力されているかどうか
+}
+
+// pendingCell はテーブルの行のセル（変換前）
+type pendingCell struct {
+	column string
+	code   string
+	value  any
+}
+
+// record は入力の行をレコードに変換する
+// 列の解決エラーは列ごとに1回だけ報告するため、reportedに記録する
+func (c *converter) record(src sourceRecord, refs map[string]fieldRef, reported map[string]bool) (map[string]types.FieldValue, []RowError) {
+	rec := map[string]types.FieldValue{}
+	tables := map[string][]tableRow{}
+	var tableOrder []string
+	var errs []RowError
+
+	addTable := func(table string) {
+		if _, ok := tables[table]; !ok {
+			tables[table] = []tableRow{}
+			tableOrder = append(tableOrder, table)
+		}
+	}
+
+	for i, row := range src.rows {
+		pending := map[string]*pendingRow{}
+		for _, column := range row.columns {
+			ref, ok := refs[column]
+			if !ok {
+				var err error
+				ref, err = c.resolve(column)
+				if err != nil {
+					if !reported[column] {
+						reported[column] = true
+						line := row.line
+						if row.headerLine > 0 {
+							line = row.headerLine
+						}
+						errs = append(errs, RowError{Line: line, Column: column, Message: err.Error()})
+					}
+					continue
+				}
+				refs[column] = ref
+			}
+			if ref.code == "" {
+				continue
+			}
+			value := row.values[column]
+
+			// JSONLのテーブルは行の配列
+			if ref.code == ref.table {
+				if nested, ok := value.([]any); ok {
+					addTable(ref.table)
+					rows, rowErrs := c.nestedRows(row.line, column, ref.table, nested)
+					tables[ref.table] = append(tables[ref.table], rows...)
+					errs = append(errs, rowErrs...)
+					continue
+				}
+			}
+
+			if ref.table == "" {
+				if i > 0 {
+					continue
+				}
+				converted, err := c.convert(c.props[ref.code], value)
+				if err != nil {
+					errs = append(errs, RowError{Line: row.line, Column: column, Message: err.Error()})
+					continue
+				}
+				rec[ref.code] = types.FieldValue{Value: converted}
+				continue
+			}
+
+			// CSVのテーブルは1行が1行
+			addTable(ref.table)
+			p := pending[ref.table]
+			if p == nil {
+				p = &pendingRow{}
+				pending[ref.table] = p
+			}
+			if ref.code == ref.table {
+				p.id = strings.TrimSpace(stringValue(value))
+				p.filled = p.filled || p.id != ""
+				continue
+			}
+			p.cells = append(p.cells, pendingCell{column: column, code: ref.code, value: value})
+			p.filled = p.filled || len(listValue(value)) > 0
+		}
+
+		// 行IDも値もない行（テーブルの行数が少ないレコードの行）は読み込まない
+		for _, table := range tableOrder {
+			p := pending[table]
+			if p == nil || !p.filled {
+				continue
+			}
+			r := tableRow{id: p.id, values: map[string]types.FieldValue{}}
+			for _, cell := range p.cells {
+				converted, err := c.convert(c.property(cell.code), cell.value)
+				if err != nil {
+					errs = append(errs, RowError{Line: row.line, Column: cell.column, Message: err.Error()})
+					continue
+				}
+				r.values[cell.code] = types.FieldValue{Value: converted}
+			}
+			tables[table] = append(tables[table], r)
+		}
+	}
+
+	for _, table := range tableOrder {
+		rows := make([]map[string]any, len(tables[table]))
+		for i, r := range tables[table] {
+			rows[i] = map[string]any{"value": r.values}
+			if r.id != "" {
+				rows[i]["id"] = r.id
+			}
+		}
+		rec[table] = types.FieldValue{Value: rows}
+	}
+	return rec, errs
+}
+
+// nestedRows はJSONLのテーブルの行（オブジェクトの配列）を変換する
+// 行のキーはテーブル内のフィールドの列名として解決し、「id」は行IDとして扱う
+func (c *converter) nestedRows(line int, column, table string, nested []any) ([]tableRow, []RowError) {
+	var rows []tableRow
+	var errs []RowError
+	for i, item := range nested {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			errs = append(errs, RowError{Line: line, Column: column, Message: fmt.Sprintf("%d行目がオブジェクトではありません", i+1)})
+			continue
+		}
+		row := tableRow{values: map[string]types.FieldValue{}}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := fmt.Sprintf("%s[%d].%s", column, i, key)
+			if key == "id" {
+				row.id = stringValue(obj[key])
+				continue
+			}
+			ref, err := c.resolve(key)
+			if err == nil && ref.table != table {
+				err = fmt.Errorf("テーブル%sのフィールドではありません", table)
+			}
+			if err != nil {
+				errs = append(errs, RowError{Line: line, Column: name, Message: err.Error()})
+				continue
+			}
+			if ref.code == "" {
+				continue
+			}
+			converted, err := c.convert(c.property(ref.code), obj[key])
+			if err != nil {
+				errs = append(errs, RowError{Line: line, Column: name, Message: err.Error()})
+				continue
+			}
+			row.values[ref.code] = types.FieldValue{Value: converted}
+		}
+		rows = append(rows, row)
+	}
+	return rows, errs
+}
+
+// convert は入力の値をフィールドタイプに応じたkintoneの値に変換する
+func (c *converter) convert(prop app.FieldProperty, value any) (any, error) {
+	switch prop.Type {
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect:
+		values := listValue(value)
+		for _, v := range values {
+			if _, ok := prop.Options[v]; !ok {
+				return nil, fmt.Errorf("選択肢にない値です: %s", v)
+			}
+		}
+		if len(values) == 0 && prop.Required {
+			return nil, fmt.Errorf("必須項目です")
+		}
+		return values, nil
+
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect:
+		codes := listValue(value)
+		if len(codes) == 0 && prop.Required {
+			return nil, fmt.Errorf("必須項目です")
+		}
+		entities := make(types.Entities, len(codes))
+		for i, code := range codes {
+			entities[i] = types.Entity{Code: code}
+		}
+		return entities, nil
+	}
+
+	if _, ok := value.([]any); ok {
+		return nil, fmt.Errorf("複数の値は指定できません")
+	}
+	s := strings.TrimSpace(stringValue(value))
+	if s == "" {
+		if prop.Required {
+			return nil, fmt.Errorf("必須項目です")
+		}
+		return "", nil
+	}
+
+	switch prop.Type {
+	case types.FieldTypeNumber:
+		d, err := types.ParseDecimal(strings.ReplaceAll(s, ",", ""))
+		if err != nil {
+			return nil, fmt.Errorf("数値ではありません: %s", s)
+		}
+		return d.String(), nil
+
+	case types.FieldTypeDate:
+		t, err := parseTime(s, dateLayouts, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("日付の形式が不正です: %s", s)
+		}
+		return t.Format(types.DateFormat), nil
+
+	case types.FieldTypeTime:
+		t, err := parseTime(s, timeLayouts, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("時刻の形式が不正です: %s", s)
+		}
+		return t.Format(types.TimeFormat), nil
+
+	case types.FieldTypeDateTime:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t, err = parseTime(s, dateTimeLayouts, c.location)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("日時の形式が不正です: %s", s)
+		}
+		return t.UTC().Format(types.DateTimeFormat), nil
+
+	case types.FieldTypeDropDown, types.FieldTypeRadioButton:
+		if _, ok := prop.Options[s]; !ok {
+			return nil, fmt.Errorf("選択肢にない値です: %s", s)
+		}
+	}
+	return stringValue(value), nil
+}
+
+// parseTime はいずれかの形式で日時を解釈する
+func parseTime(s string, layouts []string, location *time.Location) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// stringValue は入力の値を文字列に変換する
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// listValue は複数の値を持つフィールドの入力を文字列の配列に変換する
+// CSVの値は改行区切り、JSONLの値は配列として扱う
+func listValue(value any) []string {
+	values := []string{}
+	if list, ok := value.([]any); ok {
+		for _, v := range list {
+			if s := strings.TrimSpace(stringValue(v)); s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	for _, s := range strings.Split(strings.ReplaceAll(stringValue(value), "\r\n", "\n"), "\n") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// normalize は比較のためにフィールド値のJSONを正規化する
+// ユーザー選択などはコード、複数選択は並べ替えた値、数値はrecord.NormalizeKeyで正規化した値、テーブルは行ごとのフィールド値にする
+func (c *converter) normalize(code string, raw json.RawMessage) any {
+	prop := c.property(code)
+	switch prop.Type {
+	case types.FieldTypeNumber:
+		var value string
+		json.Unmarshal(raw, &value)
+		return record.NormalizeKey(prop.Type, value)
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect:
+		var entities types.Entities
+		json.Unmarshal(raw, &entities)
+		codes := entities.Codes()
+		sort.Strings(codes)
+		return codes
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect:
+		var values []string
+		json.Unmarshal(raw, &values)
+		sort.Strings(values)
+		return values
+	case types.FieldTypeSubtable:
+		var rows []struct {
+			Value map[string]struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"value"`
+		}
+		json.Unmarshal(raw, &rows)
+		result := make([]map[string]any, len(rows))
+		for i, row := range rows {
+			result[i] = map[string]any{}
+			for inner, field := range row.Value {
+				result[i][inner] = c.normalize(inner, field.Value)
+			}
+		}
+		return result
+	}
+
+	var v any
+	json.Unmarshal(raw, &v)
+	if v == nil {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,361 @@
+// Package importer はCSV・JSONLのファイルをkintoneのアプリに読み込む機能を提供する
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/export"
+	"github.com/goqoo-on-kintone/goten/query"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// lookupSize は既存レコードの検索で1回のクエリに含めるキーの数
+const lookupSize = 100
+
+// ChangeAction はレコードに対する変更の種類
+type ChangeAction string
+
+// 変更の種類
+const (
+	ChangeAdd       ChangeAction = "add"       // 追加
+	ChangeUpdate    ChangeAction = "update"    // 更新
+	ChangeUnchanged ChangeAction = "unchanged" // 既存のレコードと同じ値
+)
+
+// RecordsParams はRecordsのパラメータ
+type RecordsParams struct {
+	App      types.AppID
+	Format   export.Format   // 入力形式（省略時はCSV）
+	Encoding export.Encoding // CSVの文字コード（省略時はUTF-8、BOMは取り除く）
+
+	// Mapping は列名からフィールドコードへの対応（空文字列を指定した列は読み込まない）
+	// 指定していない列は、列名と同じフィールドコード、列名と同じフィールド名（一意の場合）のフィールドに読み込む
+	Mapping map[string]string
+
+	// KeyField を指定すると、キーの値が同じ既存レコードを更新し、存在しない場合は追加する（省略時は追加のみ）
+	// 重複禁止に設定したフィールドを指定する
+	KeyField string
+
+	// GroupBy はCSVでテーブルの行をレコードにまとめる列（同じ値が続く行を1レコードにする）
+	// 省略時は、先頭列が「レコードの開始行」の場合は「*」の行から、それ以外は1行を1レコードにする
+	GroupBy string
+
+	DryRun   bool           // 書き込まずに変更内容のみ返す
+	Location *time.Location // タイムゾーンのない日時を解釈するタイムゾーン（省略時はtime.Local）
+}
+
+// RecordsResult はRecordsの結果
+type RecordsResult struct {
+	Total   int        // 読み込んだレコード数
+	Added   int        // 追加したレコード数（DryRunの場合は追加されるレコード数）
+	Updated int        // 更新したレコード数（DryRunの場合は更新されるレコード数）
+	Changes []Change   // レコードごとの変更内容（DryRunの場合のみ）
+	Errors  []RowError // 行ごとのエラー
+}
+
+// Change はレコードに対する変更内容
+type Change struct {
+	Line   int            // 入力の行番号
+	Action ChangeAction   // 変更の種類
+	ID     types.RecordID // 更新の場合は既存のレコードID
+	Fields []string       // 値が変わるフィールドコード（追加の場合は入力した全フィールド）
+}
+
+// RowError は入力の行のエラー
+type RowError struct {
+	Line    int    // 入力の行番号（1始まり、CSVのヘッダーは1行目）
+	Column  string // 列名（行全体のエラーの場合は空）
+	Message string
+}
+
+// Error はerrorインターフェースを実装
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("%d行目: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("%d行目 %s: %s", e.Line, e.Column, e.Message)
+}
+
+// ValidationError は入力にエラーがあり、書き込みを行わなかったことを示すエラー
+type ValidationError struct {
+	Errors []RowError
+}
+
+// Error はerrorインターフェースを実装
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("入力に%d件のエラーがあります", len(e.Errors))
+	if len(e.Errors) > 0 {
+		msg += ": " + e.Errors[0].Error()
+	}
+	return msg
+}
+
+// Records はCSVまたはJSONLをrから読み込み、アプリにレコードとして書き込む
+//
+// 全行をフォームのフィールド定義に従って変換・検証してから書き込み、エラーがある場合は何も書き込まずに*ValidationErrorを返す。
+// 書き込みはKeyFieldを指定した場合はrecord.UpsertRecords、それ以外はrecord.AddAllRecordsで行う（処理の単位はrecordパッケージのドキュメントを参照）。
+// 途中のバルクリクエストが失敗した場合は、それより前の結果とエラーの行を含むRecordsResultと、*error.KintoneAllRecordsErrorを返す
+//
+// 値はフィールドタイプに応じて変換する
+//   - 数値: 桁区切りのカンマを取り除く
+//   - 日付: YYYY-MM-DD、YYYY/MM/DD など。時刻: HH:MM、HH:MM:SS
+//   - 日時: RFC 3339、または YYYY-MM-DD HH:MM など（Locationのタイムゾーンで解釈する）
+//   - チェックボックス・複数選択・ユーザー選択・組織選択・グループ選択: CSVは改行区切り、JSONLは配列（ユーザーなどはコード）
+//
+// JSONLのテーブルは、テーブルのフィールドコードをキーに行のオブジェクトの配列で指定する（行IDは「id」）。
+// CSVでは、テーブルのフィールドコードに対応する列を行IDとして扱う
+func Records(ctx context.Context, appClient *app.Client, recordClient *record.Client, r io.Reader, params RecordsParams) (*RecordsResult, error) {
+	reader, err := decodeReader(r, params.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []sourceRecord
+	switch params.Format {
+	case "", export.FormatCSV:
+		header, rows, err := readCSV(reader)
+		if err != nil {
+			return nil, err
+		}
+		sources = groupCSVRows(header, rows, params.GroupBy)
+	case export.FormatJSONL:
+		if sources, err = readJSONL(reader); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("未対応の入力形式です: %s", params.Format)
+	}
+
+	fields, err := appClient.GetFormFields(ctx, app.GetFormFieldsParams{App: params.App})
+	if err != nil {
+		return nil, err
+	}
+	if params.KeyField != "" {
+		if _, ok := fields.Properties[params.KeyField]; !ok {
+			return nil, fmt.Errorf("キーのフィールドが存在しません: %s", params.KeyField)
+		}
+	}
+
+	conv := newConverter(fields, params)
+	result := &RecordsResult{Total: len(sources), Errors: []RowError{}}
+	records := make([]map[string]types.FieldValue, len(sources))
+	refs := map[string]fieldRef{}
+	reported := map[string]bool{}
+	keys := map[string]int{}
+	keyType := fields.Properties[params.KeyField].Type
+	for i, src := range sources {
+		rec, errs := conv.record(src, refs, reported)
+		result.Errors = append(result.Errors, errs...)
+		records[i] = rec
+
+		if params.KeyField == "" || len(errs) > 0 {
+			continue
+		}
+		key := ""
+		if field, ok := rec[params.KeyField]; ok {
+			key, _ = field.Value.(string)
+		}
+		if key == "" {
+			result.Errors = append(result.Errors, RowError{Line: src.line, Column: params.KeyField, Message: "キーの値がありません"})
+			continue
+		}
+		// 表記が異なっても同じ値のキー（数値の"0123"と"123"など）は重複とする
+		normalized := record.NormalizeKey(keyType, key)
+		if line, dup := keys[normalized]; dup {
+			result.Errors = append(result.Errors, RowError{Line: src.line, Column: params.KeyField, Message: fmt.Sprintf("キーの値が%d行目と重複しています: %s", line, key)})
+			continue
+		}
+		keys[normalized] = src.line
+	}
+	if len(result.Errors) > 0 {
+		slices.SortStableFunc(result.Errors, func(a, b RowError) int { return a.Line - b.Line })
+		return result, &ValidationError{Errors: result.Errors}
+	}
+
+	if params.DryRun {
+		changes, err := plan(ctx, recordClient, conv, params, sources, records)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = changes
+		for _, change := range changes {
+			switch change.Action {
+			case ChangeAdd:
+				result.Added++
+			case ChangeUpdate:
+				result.Updated++
+			}
+		}
+		return result, nil
+	}
+
+	if params.KeyField == "" {
+		added, err := recordClient.AddAllRecords(ctx, record.AddAllRecordsParams{App: params.App, Records: records})
+		if err != nil {
+			var allErr *kintoneError.KintoneAllRecordsError
+			if errors.As(err, &allErr) {
+				if processed, ok := allErr.ProcessedRecords.(*record.AddAllRecordsResult); ok {
+					result.Added = len(processed.IDs)
+				}
+				result.Errors = append(result.Errors, writeError(sources, allErr))
+			}
+			return result, err
+		}
+		result.Added = len(added.IDs)
+		return result, nil
+	}
+
+	upserted, err := recordClient.UpsertRecords(ctx, record.UpsertRecordsParams{App: params.App, KeyField: params.KeyField, Records: records})
+	if err != nil {
+		var allErr *kintoneError.KintoneAllRecordsError
+		if errors.As(err, &allErr) {
+			if processed, ok := allErr.ProcessedRecords.(*record.UpsertRecordsResult); ok {
+				countUpserted(result, processed)
+			}
+			result.Errors = append(result.Errors, writeError(sources, allErr))
+		}
+		return result, err
+	}
+	countUpserted(result, upserted)
+	return result, nil
+}
+
+// countUpserted はUpsertRecordsの結果から追加・更新したレコード数を数える
+func countUpserted(result *RecordsResult, upserted *record.UpsertRecordsResult) {
+	for _, r := range upserted.Records {
+		switch {
+		case r.ID == "":
+		case r.Added:
+			result.Added++
+		default:
+			result.Updated++
+		}
+	}
+}
+
+// writeError は書き込みのエラーを入力の行のエラーに変換する
+func writeError(sources []sourceRecord, err *kintoneError.KintoneAllRecordsError) RowError {
+	if err.ErrorIndex < 0 || err.ErrorIndex >= len(sources) {
+		return RowError{Message: err.Err.Error()}
+	}
+	return RowError{Line: sources[err.ErrorIndex].line, Message: err.Err.Error()}
+}
+
+// plan は既存のレコードと比較して、レコードごとの変更内容を返す
+func plan(ctx context.Context, recordClient *record.Client, conv *converter, params RecordsParams, sources []sourceRecord, records []map[string]types.FieldValue) ([]Change, error) {
+	existing := map[string]record.Dynamic{}
+	keyType := conv.property(params.KeyField).Type
+	if params.KeyField != "" {
+		var err error
+		if existing, err = lookupExisting(ctx, recordClient, params, keyType, records); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make([]Change, len(records))
+	for i, rec := range records {
+		codes := make([]string, 0, len(rec))
+		for code := range rec {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		key := ""
+		if params.KeyField != "" {
+			key = record.NormalizeKey(keyType, rec[params.KeyField].Value.(string))
+		}
+		current, ok := existing[key]
+		if !ok {
+			changes[i] = Change{Line: sources[i].line, Action: ChangeAdd, Fields: codes}
+			continue
+		}
+
+		change := Change{Line: sources[i].line, Action: ChangeUnchanged, ID: current.ID(), Fields: []string{}}
+		for _, code := range codes {
+			if !sameValue(conv, code, current.Raw(code), rec[code].Value) {
+				change.Fields = append(change.Fields, code)
+			}
+		}
+		if len(change.Fields) > 0 {
+			change.Action = ChangeUpdate
+		}
+		changes[i] = change
+	}
+	return changes, nil
+}
+
+// lookupExisting はキーの値で既存レコードを検索し、キーの値からレコードを引けるようにする
+// キーの値はUpsertRecordsと同じくrecord.NormalizeKeyで正規化した値で照合する
+func lookupExisting(ctx context.Context, recordClient *record.Client, params RecordsParams, keyType string, records []map[string]types.FieldValue) (map[string]record.Dynamic, error) {
+	var keys []any
+	fieldSet := map[string]bool{"$id": true}
+	for _, rec := range records {
+		keys = append(keys, rec[params.KeyField].Value)
+		for code := range rec {
+			fieldSet[code] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for code := range fieldSet {
+		fields = append(fields, code)
+	}
+	sort.Strings(fields)
+
+	existing := map[string]record.Dynamic{}
+	for start := 0; start < len(keys); start += lookupSize {
+		condition := query.Field(params.KeyField).In(keys[start:min(start+lookupSize, len(keys))]...)
+		for rec, err := range record.AllRecords[record.Dynamic](ctx, recordClient, record.GetAllRecordsParams{
+			App:       params.App,
+			Fields:    fields,
+			Condition: condition.String(),
+		}) {
+			if err != nil {
+				return nil, fmt.Errorf("既存レコードの検索エラー: %w", err)
+			}
+			key, err := rec.String(params.KeyField)
+			if err != nil {
+				return nil, fmt.Errorf("既存レコードの検索エラー: %w", err)
+			}
+			existing[record.NormalizeKey(keyType, key)] = rec
+		}
+	}
+	return existing, nil
+}
+
+// sameValue は既存のフィールド値と入力の値が同じかどうかを返す
+// テーブルは入力した列のフィールドのみ比較する
+func sameValue(conv *converter, code string, current json.RawMessage, value any) bool {
+	raw, err := json.Marshal(value)
+	if err != nil || current == nil {
+		return false
+	}
+	a, b := conv.normalize(code, current), conv.normalize(code, raw)
+
+	currentRows, ok := a.([]map[string]any)
+	if !ok {
+		return reflect.DeepEqual(a, b)
+	}
+	rows := b.([]map[string]any)
+	if len(currentRows) != len(rows) {
+		return false
+	}
+	for i, row := range rows {
+		for inner, v := range row {
+			if !reflect.DeepEqual(currentRows[i][inner], v) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,347 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/export"
+	"github.com/goqoo-on-kintone/goten/importer"
+	"github.com/goqoo-on-kintone/goten/kintonetest"
+)
+
+// importFixture は申請アプリ（ID: 1）と申請番号A-001の既存レコード
+const importFixture = `{"apps": [{
+	"id": "1",
+	"name": "経費申請",
+	"fields": {
+		"申請番号": {"type": "SINGLE_LINE_TEXT", "code": "申請番号", "label": "申請番号", "unique": true},
+		"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名", "required": true},
+		"金額": {"type": "NUMBER", "code": "金額", "label": "金額"},
+		"申請日": {"type": "DATE", "code": "申請日", "label": "申請日"},
+		"提出日時": {"type": "DATETIME", "code": "提出日時", "label": "提出日時"},
+		"区分": {"type": "DROP_DOWN", "code": "区分", "label": "区分", "options": {"出張": {"label": "出張", "index": "0"}, "交通費": {"label": "交通費", "index": "1"}}},
+		"タグ": {"type": "CHECK_BOX", "code": "タグ", "label": "タグ", "options": {"至急": {"label": "至急", "index": "0"}, "海外": {"label": "海外", "index": "1"}}},
+		"承認者": {"type": "USER_SELECT", "code": "承認者", "label": "承認者"},
+		"明細": {"type": "SUBTABLE", "code": "明細", "label": "明細", "fields": {
+			"品名": {"type": "SINGLE_LINE_TEXT", "code": "品名", "label": "品名"},
+			"単価": {"type": "NUMBER", "code": "単価", "label": "単価"}
+		}}
+	},
+	"records": [{
+		"$id": {"value": "1"},
+		"申請番号": {"value": "A-001"},
+		"件名": {"value": "出張費"},
+		"金額": {"value": "12000"},
+		"申請日": {"value": "2024-04-01"},
+		"提出日時": {"value": "2024-04-01T00:30:00Z"},
+		"区分": {"value": "出張"},
+		"タグ": {"value": ["海外", "至急"]},
+		"承認者": {"value": [{"code": "user1", "name": "佐藤"}]},
+		"明細": {"value": [
+			{"id": "101", "value": {"品名": {"value": "航空券"}, "単価": {"value": "10000"}}},
+			{"id": "102", "value": {"品名": {"value": "宿泊"}, "単価": {"value": "2000"}}}
+		]}
+	}]
+}]}`
+
+// importCSV はRecordsCSVと同じ形式のCSV（A-001は金額のみ既存レコードと異なる）
+const importCSV = "レコードの開始行,申請番号,件名,金額,申請日,提出日時,区分,タグ,承認者,明細,品名,単価\n" +
+	"*,A-001,出張費,\"13,000\",2024/4/1,2024-04-01 09:30,出張,\"至急\n海外\",user1,101,航空券,10000\n" +
+	",A-001,出張費,\"13,000\",2024/4/1,2024-04-01 09:30,出張,\"至急\n海外\",user1,102,宿泊,2000\n" +
+	"*,A-002,文具,500,2024-04-02,,交通費,,user2,,,\n"
+
+func TestRecordsDryRun(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(importFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader(importCSV), importer.RecordsParams{
+		App:      "1",
+		KeyField: "申請番号",
+		DryRun:   true,
+		Location: time.FixedZone("JST", 9*60*60),
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if records := server.Records("1"); len(records) != 1 || records[0].Revision() != "1" {
+		t.Errorf("DryRunでレコードが書き込まれている: %d件", len(records))
+	}
+	if result.Total != 2 || result.Added != 1 || result.Updated != 1 {
+		t.Errorf("期待される件数: 全2件 追加1件 更新1件, 実際: %+v", result)
+	}
+
+	if len(result.Changes) != 2 {
+		t.Fatalf("期待される変更数: 2, 実際: %d", len(result.Changes))
+	}
+	update := result.Changes[0]
+	if update.Line != 2 || update.Action != importer.ChangeUpdate || update.ID != "1" || strings.Join(update.Fields, ",") != "金額" {
+		t.Errorf("期待される変更: 2行目 レコード1の金額を更新, 実際: %+v", update)
+	}
+	add := result.Changes[1]
+	if add.Line != 6 || add.Action != importer.ChangeAdd {
+		t.Errorf("期待される変更: 6行目（改行を含むセルの後）追加, 実際: %+v", add)
+	}
+}
+
+func TestRecordsDryRunNumberKey(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(`{"apps": [{
+		"id": "1",
+		"name": "台帳",
+		"fields": {
+			"管理番号": {"type": "NUMBER", "code": "管理番号", "label": "管理番号", "unique": true},
+			"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名"}
+		},
+		"records": [{"管理番号": {"value": "123"}, "件名": {"value": "旧件名"}}]
+	}]}`)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	// 数値のフィールドは数値として検索されるため、"0123"で"123"のレコードが見つかる
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader("管理番号,件名\n0123,新件名\n"), importer.RecordsParams{
+		App:      "1",
+		KeyField: "管理番号",
+		DryRun:   true,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result.Changes) != 1 {
+		t.Fatalf("期待される変更数: 1, 実際: %d", len(result.Changes))
+	}
+	change := result.Changes[0]
+	if change.Action != importer.ChangeUpdate || change.ID != "1" || strings.Join(change.Fields, ",") != "件名" {
+		t.Errorf("期待される変更: レコード1の件名を更新, 実際: %+v", change)
+	}
+
+	_, err = importer.Records(context.Background(), client.App, client.Record, strings.NewReader("管理番号,件名\n0123,x\n123,y\n"), importer.RecordsParams{
+		App:      "1",
+		KeyField: "管理番号",
+		DryRun:   true,
+	})
+	var validationErr *importer.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Line != 3 {
+		t.Errorf("数値として同じキーが3行目の重複エラーになっていない: %v", err)
+	}
+}
+
+func TestRecordsUpsert(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(importFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader(importCSV), importer.RecordsParams{
+		App:      "1",
+		KeyField: "申請番号",
+		Location: time.FixedZone("JST", 9*60*60),
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Added != 1 || result.Updated != 1 {
+		t.Errorf("期待される件数: 追加1件 更新1件, 実際: %+v", result)
+	}
+	records := server.Records("1")
+	if len(records) != 2 {
+		t.Fatalf("期待されるレコード数: 2, 実際: %d", len(records))
+	}
+	updated, added := records[0], records[1]
+
+	tests := []struct {
+		name string
+		got  func() (string, error)
+		want string
+	}{
+		{"金額", func() (string, error) { return updated.String("金額") }, "13000"},
+		{"申請日", func() (string, error) { return updated.String("申請日") }, "2024-04-01"},
+		{"提出日時", func() (string, error) { return updated.String("提出日時") }, "2024-04-01T00:30:00Z"},
+		{"タグ", func() (string, error) {
+			tags, err := updated.Strings("タグ")
+			return strings.Join(tags, ","), err
+		}, "至急,海外"},
+		{"承認者", func() (string, error) {
+			users, err := updated.Users("承認者")
+			return strings.Join(users.Codes(), ","), err
+		}, "user1"},
+		{"明細", func() (string, error) {
+			rows, err := updated.Subtable("明細")
+			cells := make([]string, len(rows))
+			for i, row := range rows {
+				name, _ := row.Record.String("品名")
+				price, _ := row.Record.String("単価")
+				cells[i] = row.ID + ":" + name + ":" + price
+			}
+			return strings.Join(cells, ","), err
+		}, "101:航空券:10000,102:宿泊:2000"},
+		{"追加した申請番号", func() (string, error) { return added.String("申請番号") }, "A-002"},
+		{"追加した提出日時", func() (string, error) { return added.String("提出日時") }, ""},
+		{"追加した明細", func() (string, error) {
+			rows, err := added.Subtable("明細")
+			return strings.Repeat("*", len(rows)), err
+		}, ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.got()
+		if err != nil {
+			t.Errorf("%s: エラーが発生: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: 期待される値: %s, 実際: %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRecordsValidation(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(importFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	input := "件名,金額,申請日,区分,不明,レコード番号\n" +
+		"出張費,abc,2024-04-01,出張,x,1\n" +
+		"文具,100,2024/13/40,その他,x,2\n" +
+		",100,,,x,3\n"
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader(input), importer.RecordsParams{
+		App:     "1",
+		Mapping: map[string]string{"レコード番号": ""},
+	})
+
+	var validationErr *importer.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("期待されるエラー: *importer.ValidationError, 実際: %v", err)
+	}
+	if records := server.Records("1"); len(records) != 1 {
+		t.Errorf("エラーがあるのにレコードが書き込まれている: %d件", len(records))
+	}
+
+	want := []string{
+		"1行目 不明: 対応するフィールドがありません",
+		"2行目 金額: 数値ではありません: abc",
+		"3行目 申請日: 日付の形式が不正です: 2024/13/40",
+		"3行目 区分: 選択肢にない値です: その他",
+		"4行目 件名: 必須項目です",
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("期待されるエラー数: %d, 実際: %d (%v)", len(want), len(result.Errors), result.Errors)
+	}
+	for i, e := range result.Errors {
+		if e.Error() != want[i] {
+			t.Errorf("期待されるエラー: %s, 実際: %s", want[i], e.Error())
+		}
+	}
+
+	_, err = importer.Records(context.Background(), client.App, client.Record, strings.NewReader("申請番号,件名\nA-003,x\nA-003,y\n"), importer.RecordsParams{
+		App:      "1",
+		KeyField: "申請番号",
+	})
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Line != 3 {
+		t.Errorf("キーの重複が3行目のエラーになっていない: %v", err)
+	}
+}
+
+func TestRecordsJSONL(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(importFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	input := `{"title": "出張費", "金額": 12000, "タグ": ["海外"], "明細": [{"品名": "航空券", "単価": 10000}, {"品名": "宿泊", "単価": "2,000"}]}
+
+{"title": "文具", "金額": 500.5}
+`
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader(input), importer.RecordsParams{
+		App:     "1",
+		Format:  export.FormatJSONL,
+		Mapping: map[string]string{"title": "件名"},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Added != 2 {
+		t.Errorf("期待される追加件数: 2, 実際: %d", result.Added)
+	}
+	records := server.Records("1")
+	if len(records) != 3 {
+		t.Fatalf("期待されるレコード数: 既存1件と追加2件, 実際: %d", len(records))
+	}
+
+	rows, err := records[1].Subtable("明細")
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("期待されるテーブルの行数: 2, 実際: %d (%v)", len(rows), err)
+	}
+	if name, _ := rows[1].Record.String("品名"); name != "宿泊" {
+		t.Errorf("期待される品名: 宿泊, 実際: %s", name)
+	}
+	if price, _ := rows[1].Record.String("単価"); price != "2000" {
+		t.Errorf("期待される単価: 2000, 実際: %s", price)
+	}
+	if amount, _ := records[2].String("金額"); amount != "500.5" {
+		t.Errorf("期待される金額: 500.5, 実際: %s", amount)
+	}
+}
+
+func TestRecordsGroupBy(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(importFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+
+	input := "伝票,件名,品名,単価\n" +
+		"1,出張費,航空券,10000\n" +
+		"1,出張費,宿泊,2000\n" +
+		"2,文具,ペン,100\n"
+	result, err := importer.Records(context.Background(), client.App, client.Record, strings.NewReader(input), importer.RecordsParams{
+		App:     "1",
+		GroupBy: "伝票",
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("期待されるレコード数: 2, 実際: %d", result.Total)
+	}
+	records := server.Records("1")
+	if len(records) != 3 {
+		t.Fatalf("期待されるレコード数: 既存1件と追加2件, 実際: %d", len(records))
+	}
+	if rows, _ := records[1].Subtable("明細"); len(rows) != 2 {
+		t.Errorf("期待されるテーブルの行数: 2, 実際: %d", len(rows))
+	}
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/goqoo-on-kintone/goten/export"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// sourceRow は入力の1行（CSVの1行、またはJSONLの1行）
+type sourceRow struct {
+	line       int
+	headerLine int            // CSVのヘッダーの行番号（列の解決エラーを報告する行、JSONLは0）
+	columns    []string       // 列名（CSVはヘッダー順、JSONLはキーの出現順）
+	values     map[string]any // 列名 → 値（CSVは文字列、JSONLはJSONの値）
+}
+
+// sourceRecord は1レコード分の入力の行
+// CSVでテーブルを複数行に展開している場合は複数行になる
+type sourceRecord struct {
+	line int // 先頭行の行番号
+	rows []sourceRow
+}
+
+// decodeReader は文字コードを変換し、UTF-8のBOMを取り除いたio.Readerを返す
+func decodeReader(r io.Reader, encoding export.Encoding) (io.Reader, error) {
+	switch encoding {
+	case "", export.EncodingUTF8:
+		br := bufio.NewReader(r)
+		if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+			br.Discard(3)
+		}
+		return br, nil
+	case export.EncodingShiftJIS:
+		return transform.NewReader(r, japanese.ShiftJIS.NewDecoder()), nil
+	default:
+		return nil, fmt.Errorf("未対応の文字コードです: %s", encoding)
+	}
+}
+
+// readCSV はCSVを読み込み、ヘッダーと行を返す（行番号は1始まりでヘッダーが1行目）
+func readCSV(r io.Reader) ([]string, []sourceRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("ヘッダーがありません")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSVの読み込みエラー: %w", err)
+	}
+
+	var rows []sourceRow
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSVの読み込みエラー: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		row := sourceRow{line: line, headerLine: 1, columns: header, values: make(map[string]any, len(header))}
+		for i, column := range header {
+			if i < len(fields) {
+				row.values[column] = fields[i]
+			} else {
+				row.values[column] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+// groupCSVRows はCSVの行をレコードごとにまとめる
+// groupByを指定した場合は同じ値が続く行、先頭列がレコードの開始行の場合は「*」から次の「*」の前までの行を1レコードにする
+// どちらでもない場合は1行を1レコードにする
+func groupCSVRows(header []string, rows []sourceRow, groupBy string) []sourceRecord {
+	startColumn := ""
+	if groupBy == "" && len(header) > 0 && header[0] == export.RecordStartHeader {
+		startColumn = export.RecordStartHeader
+	}
+
+	var records []sourceRecord
+	for i, row := range rows {
+		newRecord := len(records) == 0
+		switch {
+		case groupBy != "":
+			newRecord = newRecord || row.values[groupBy] != rows[i-1].values[groupBy]
+		case startColumn != "":
+			newRecord = newRecord || row.values[startColumn] == "*"
+		default:
+			newRecord = true
+		}
+
+		if newRecord {
+			records = append(records, sourceRecord{line: row.line})
+		}
+		last := &records[len(records)-1]
+		last.rows = append(last.rows, row)
+	}
+	return records
+}
+
+// readJSONL はJSONLを読み込む（1行に1レコードのJSONオブジェクト、空行は無視する）
+func readJSONL(r io.Reader) ([]sourceRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var records []sourceRecord
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		columns, values, err := decodeObject(data)
+		if err != nil {
+			return nil, fmt.Errorf("%d行目: JSONの形式が不正です: %w", line, err)
+		}
+		records = append(records, sourceRecord{
+			line: line,
+			rows: []sourceRow{{line: line, columns: columns, values: values}},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("JSONLの読み込みエラー: %w", err)
+	}
+	return records, nil
+}
+
+// decodeObject はJSONオブジェクトをキーの出現順と値に変換する（数値はjson.Numberのまま）
+func decodeObject(data []byte) ([]string, map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if token, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	} else if token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("オブジェクトではありません")
+	}
+
+	var columns []string
+	values := map[string]any{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, dup := values[key]; !dup {
+			columns = append(columns, key)
+		}
+		values[key] = value
+	}
+	return columns, values, nil
+}
@@ -0,0 +1,157 @@
+package kintonetest
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/app"
+)
+
+// appRevision はアプリの設定のリビジョン（設定は変更できないため固定）
+const appRevision = "1"
+
+// maxApps は一度に取得できるアプリ数
+const maxApps = 100
+
+// info はアプリの基本情報を返す
+func (a *appState) info(user app.User) app.App {
+	createdAt := a.createdAt.Format(dateTimeLayout)
+	return app.App{
+		AppID:       a.ID,
+		Code:        a.Code,
+		Name:        a.Name,
+		Description: a.Description,
+		SpaceID:     a.SpaceID,
+		CreatedAt:   createdAt,
+		Creator:     user,
+		ModifiedAt:  createdAt,
+		Modifier:    user,
+	}
+}
+
+func (s *Server) getApp(body json.RawMessage) (any, error) {
+	var req struct {
+		ID flexString `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return a.info(app.User{Code: s.User.Code, Name: s.User.Name}), nil
+}
+
+func (s *Server) getApps(body json.RawMessage) (any, error) {
+	var req struct {
+		IDs      []flexString `json:"ids"`
+		Codes    []string     `json:"codes"`
+		Name     string       `json:"name"`
+		SpaceIDs []flexString `json:"spaceIds"`
+		Limit    int          `json:"limit"`
+		Offset   int          `json:"offset"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Limit == 0 {
+		req.Limit = maxApps
+	}
+	if req.Limit < 0 || req.Limit > maxApps {
+		return nil, validationError(map[string][]string{"limit": {"1以上100以下である必要があります。"}})
+	}
+
+	var ids []string
+	for id := range s.apps {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(x, y string) int { return cmp.Compare(parseID(x), parseID(y)) })
+
+	user := app.User{Code: s.User.Code, Name: s.User.Name}
+	apps := []app.App{}
+	for _, id := range ids {
+		a := s.apps[id]
+		switch {
+		case len(req.IDs) > 0 && !slices.Contains(req.IDs, flexString(a.ID)),
+			len(req.Codes) > 0 && !slices.Contains(req.Codes, a.Code),
+			len(req.SpaceIDs) > 0 && !slices.Contains(req.SpaceIDs, flexString(a.SpaceID)),
+			req.Name != "" && !strings.Contains(strings.ToLower(a.Name), strings.ToLower(req.Name)):
+			continue
+		}
+		apps = append(apps, a.info(user))
+	}
+	start := min(req.Offset, len(apps))
+	end := min(start+req.Limit, len(apps))
+	return app.GetAppsResult{Apps: apps[start:end]}, nil
+}
+
+// appParams はアプリの設定を取得するAPIのパラメータ
+type appParams struct {
+	App flexString `json:"app"`
+}
+
+func (s *Server) getFormFields(body json.RawMessage) (any, error) {
+	var req appParams
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	return app.GetFormFieldsResult{Properties: a.fields, Revision: appRevision}, nil
+}
+
+func (s *Server) getFormLayout(body json.RawMessage) (any, error) {
+	var req appParams
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	layout := a.Layout
+	if layout == nil {
+		layout = []app.LayoutElement{}
+	}
+	return app.GetFormLayoutResult{Layout: layout, Revision: appRevision}, nil
+}
+
+func (s *Server) getViews(body json.RawMessage) (any, error) {
+	var req appParams
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	views := a.Views
+	if views == nil {
+		views = map[string]app.View{}
+	}
+	return app.GetViewsResult{Views: views, Revision: appRevision}, nil
+}
+
+func (s *Server) getProcessManagement(body json.RawMessage) (any, error) {
+	var req appParams
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if a.Process == nil {
+		return app.ProcessManagement{Revision: appRevision}, nil
+	}
+	process := *a.Process
+	if process.Revision == "" {
+		process.Revision = appRevision
+	}
+	return process, nil
+}
@@ -0,0 +1,75 @@
+package kintonetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// maxBulkRequests はバルクリクエストの最大数
+const maxBulkRequests = 20
+
+// snapshot はリクエストの失敗時に戻すためのデータの複製
+type snapshot struct {
+	apps  map[string]*appState
+	files map[string]storedFile
+}
+
+// snapshot は現在のデータを複製する
+func (s *Server) snapshot() snapshot {
+	apps := make(map[string]*appState, len(s.apps))
+	for id, a := range s.apps {
+		apps[id] = a.clone()
+	}
+	return snapshot{apps: apps, files: maps.Clone(s.files)}
+}
+
+// restore はデータを複製した時点に戻す
+func (s *Server) restore(snap snapshot) {
+	s.apps = snap.apps
+	s.files = snap.files
+}
+
+// bulkRequest は複数のAPIを順に実行する
+// いずれかが失敗した場合はエラーを返し、serveHTTPが全てのリクエストの変更を取り消す
+func (s *Server) bulkRequest(body json.RawMessage) (any, error) {
+	var req struct {
+		Requests []struct {
+			Method  string          `json:"method"`
+			API     string          `json:"api"`
+			Payload json.RawMessage `json:"payload"`
+		} `json:"requests"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if len(req.Requests) == 0 || len(req.Requests) > maxBulkRequests {
+		return nil, validationError(map[string][]string{"requests": {fmt.Sprintf("1件以上%d件以下で指定してください。", maxBulkRequests)}})
+	}
+
+	results := make([]any, len(req.Requests))
+	for i, r := range req.Requests {
+		var result any
+		var err error
+		m := apiPathPattern.FindStringSubmatch(r.API)
+		switch {
+		case m == nil || m[1] == "bulkRequest" || m[1] == "file":
+			err = unsupported(r.Method, r.API)
+		case len(r.Payload) == 0:
+			result, err = s.dispatch(r.Method, m[1], json.RawMessage("{}"))
+		default:
+			result, err = s.dispatch(r.Method, m[1], r.Payload)
+		}
+		if err != nil {
+			e := toAPIError(err)
+			failed := make([]any, len(req.Requests))
+			for j := range failed {
+				failed[j] = map[string]any{}
+			}
+			failed[i] = e
+			return nil, &apiError{status: e.status, body: e.body, results: failed}
+		}
+		results[i] = result
+	}
+	return map[string]any{"results": results}, nil
+}
@@ -0,0 +1,124 @@
+package kintonetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+)
+
+// カーソルの上限
+const (
+	maxCursors        = 10
+	defaultCursorSize = 100
+	maxCursorSize     = 500
+	cursorLifetime    = 10 * time.Minute // 最後に使用してからの有効期限
+)
+
+// codeCursorNotFound はカーソルが存在しない（有効期限切れを含む）場合のエラーコード
+const codeCursorNotFound = "GAIA_RE18"
+
+// cursorState は作成したカーソル
+// 作成時点でクエリに一致したレコードを保持する
+type cursorState struct {
+	records  []map[string]any
+	size     int
+	lastUsed time.Time
+}
+
+// expireCursors は有効期限が過ぎたカーソルを削除する
+func (s *Server) expireCursors() {
+	now := s.clock()
+	for id, c := range s.cursors {
+		if now.Sub(c.lastUsed) >= cursorLifetime {
+			delete(s.cursors, id)
+		}
+	}
+}
+
+func (s *Server) createCursor(body json.RawMessage) (any, error) {
+	var req struct {
+		App    flexString `json:"app"`
+		Fields []string   `json:"fields"`
+		Query  string     `json:"query"`
+		Size   int        `json:"size"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size == 0 {
+		req.Size = defaultCursorSize
+	}
+	if req.Size < 0 || req.Size > maxCursorSize {
+		return nil, validationError(map[string][]string{"size": {fmt.Sprintf("1以上%d以下である必要があります。", maxCursorSize)}})
+	}
+	s.expireCursors()
+	if len(s.cursors) >= maxCursors {
+		return nil, newAPIError(http.StatusForbidden, kintoneError.CodeCursorLimit, fmt.Sprintf("作成できるカーソルの上限（%d個）に達しています。", maxCursors))
+	}
+
+	total, records, err := s.selectRecords(a, req.Query, false)
+	if err != nil {
+		return nil, err
+	}
+	c := &cursorState{size: req.Size, lastUsed: s.clock()}
+	for _, rec := range records {
+		c.records = append(c.records, a.render(rec, req.Fields))
+	}
+	id := s.nextKey("cursor")
+	s.cursors[id] = c
+	return map[string]any{"id": id, "totalCount": strconv.Itoa(total)}, nil
+}
+
+func (s *Server) getCursor(body json.RawMessage) (any, error) {
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	s.expireCursors()
+	c, ok := s.cursors[req.ID]
+	if !ok {
+		return nil, cursorNotFound(req.ID)
+	}
+	n := min(c.size, len(c.records))
+	records := c.records[:n]
+	c.records = c.records[n:]
+	c.lastUsed = s.clock()
+	next := len(c.records) > 0
+	if !next {
+		// 全て取得したカーソルは削除する
+		delete(s.cursors, req.ID)
+	}
+	if records == nil {
+		records = []map[string]any{}
+	}
+	return map[string]any{"records": records, "next": next}, nil
+}
+
+func (s *Server) deleteCursor(body json.RawMessage) (any, error) {
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	s.expireCursors()
+	if _, ok := s.cursors[req.ID]; !ok {
+		return nil, cursorNotFound(req.ID)
+	}
+	delete(s.cursors, req.ID)
+	return map[string]any{}, nil
+}
+
+func cursorNotFound(id string) *apiError {
+	return newAPIError(http.StatusNotFound, codeCursorNotFound, fmt.Sprintf("指定したカーソル（id: %s）が見つかりません。", id))
+}
@@ -0,0 +1,94 @@
+package kintonetest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// codeFileNotFound はファイルが存在しない場合のエラーコード
+const codeFileNotFound = "GAIA_BL01"
+
+// maxUploadBytes はアップロードできるファイルの最大サイズ
+const maxUploadBytes = 1 << 30
+
+// storedFile はアップロードしたファイル
+type storedFile struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// uploadFile はmultipart/form-dataのfileのファイルを保存してファイルキーを返す
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, badRequest(fmt.Sprintf("ファイルの読み込みエラー: %v", err)))
+		return
+	}
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, badRequest(fmt.Sprintf("ファイルの読み込みエラー: %v", err)))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxUploadBytes))
+	if err != nil {
+		writeError(w, badRequest(fmt.Sprintf("ファイルの読み込みエラー: %v", err)))
+		return
+	}
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	s.mu.Lock()
+	key := s.nextKey("file")
+	s.files[key] = storedFile{name: header.Filename, contentType: contentType, data: data}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"fileKey": key})
+}
+
+// downloadFile はファイルキーのファイルを返す
+func (s *Server) downloadFile(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("fileKey")
+	s.mu.Lock()
+	f, ok := s.files[key]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, fileNotFound(key))
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(f.data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(f.data)
+}
+
+// attachFiles は添付ファイルのフィールドの値を変換する
+// 指定したファイルキー（アップロードで取得したキー、またはレコードから取得したキー）のファイルを、ダウンロード用の新しいキーで保存する
+func (s *Server) attachFiles(value any) ([]any, error) {
+	list, _ := value.([]any)
+	files := []any{}
+	for _, item := range list {
+		m, _ := item.(map[string]any)
+		key, _ := m["fileKey"].(string)
+		f, ok := s.files[key]
+		if !ok {
+			return nil, fileNotFound(key)
+		}
+		downloadKey := s.nextKey("file")
+		s.files[downloadKey] = f
+		files = append(files, map[string]any{
+			"contentType": f.contentType,
+			"fileKey":     downloadKey,
+			"name":        f.name,
+			"size":        strconv.Itoa(len(f.data)),
+		})
+	}
+	return files, nil
+}
+
+func fileNotFound(key string) *apiError {
+	return newAPIError(http.StatusNotFound, codeFileNotFound, fmt.Sprintf("指定したファイル（fileKey: %s）が見つかりません。", key))
+}
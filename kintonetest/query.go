@@ -0,0 +1,445 @@
+package kintonetest
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/query"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// クエリの上限
+const (
+	defaultLimit = 100
+	maxLimit     = 500
+	maxOffset    = 10000
+)
+
+// codeQuery はクエリが正しくない場合のエラーコード
+const codeQuery = "GAIA_IQ11"
+
+const dateLayout = "2006-01-02"
+
+// queryError はクエリのエラーを作成する
+func queryError(message string) *apiError {
+	return newAPIError(http.StatusBadRequest, codeQuery, message)
+}
+
+// selectRecords はクエリに一致するレコード数と、取得するレコードを返す
+// pagingがtrueの場合はlimit・offsetを適用する（limitの省略時は100件）。falseの場合はlimit・offsetを指定できない
+// order byを省略した場合は$idの降順
+func (s *Server) selectRecords(a *appState, q string, paging bool) (int, []*storedRecord, error) {
+	parsed, err := query.Parse(q)
+	if err != nil {
+		return 0, nil, queryError(err.Error())
+	}
+	e := s.evaluator(a)
+	if err := e.check(parsed.Condition); err != nil {
+		return 0, nil, err
+	}
+
+	records := a.sortedRecords()
+	slices.Reverse(records)
+	matched := []*storedRecord{}
+	for _, rec := range records {
+		if parsed.Condition == nil || e.match(rec, parsed.Condition) {
+			matched = append(matched, rec)
+		}
+	}
+
+	for _, item := range parsed.OrderBy {
+		if _, ok := e.lookup(item.Field); !ok {
+			return 0, nil, fieldNotFound(item.Field)
+		}
+	}
+	slices.SortStableFunc(matched, func(x, y *storedRecord) int {
+		for _, item := range parsed.OrderBy {
+			ref, _ := e.lookup(item.Field)
+			c := compareText(first(e.candidates(x, item.Field, ref)), first(e.candidates(y, item.Field, ref)))
+			if item.Order == query.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	total := len(matched)
+	if !paging {
+		if parsed.Limit != nil || parsed.Offset != nil {
+			return 0, nil, queryError("カーソルのクエリにはlimit・offsetを指定できません。")
+		}
+		return total, matched, nil
+	}
+	limit, offset := defaultLimit, 0
+	if parsed.Limit != nil {
+		if *parsed.Limit > maxLimit {
+			return 0, nil, queryError(fmt.Sprintf("limitには%d以下の値を指定してください。", maxLimit))
+		}
+		limit = *parsed.Limit
+	}
+	if parsed.Offset != nil {
+		if *parsed.Offset > maxOffset {
+			return 0, nil, queryError(fmt.Sprintf("offsetには%d以下の値を指定してください。", maxOffset))
+		}
+		offset = *parsed.Offset
+	}
+	start := min(offset, total)
+	end := min(start+limit, total)
+	return total, matched[start:end], nil
+}
+
+// matchCondition はレコードが条件（プロセス管理のアクションの実行条件など）に一致するかを返す
+func (s *Server) matchCondition(a *appState, rec *storedRecord, condition string) (bool, error) {
+	parsed, err := query.Parse(condition)
+	if err != nil {
+		return false, queryError(err.Error())
+	}
+	e := s.evaluator(a)
+	if err := e.check(parsed.Condition); err != nil {
+		return false, err
+	}
+	return parsed.Condition == nil || e.match(rec, parsed.Condition), nil
+}
+
+func fieldNotFound(code string) *apiError {
+	return queryError(fmt.Sprintf("指定したフィールド（%s）が見つかりません。", code))
+}
+
+// evaluator はレコードがクエリの条件に一致するかを評価する
+// 日付の関数（TODAY()など）はUTCで評価する
+type evaluator struct {
+	a    *appState
+	user string
+	now  time.Time
+}
+
+func (s *Server) evaluator(a *appState) *evaluator {
+	return &evaluator{a: a, user: s.User.Code, now: s.clock()}
+}
+
+// fieldRef はクエリで指定したフィールド
+type fieldRef struct {
+	fieldType string
+	table     string // テーブル内のフィールドの場合はテーブルのフィールドコード
+}
+
+// lookup はフィールドコードのフィールドを返す（テーブル内のフィールドも含む）
+func (e *evaluator) lookup(code string) (fieldRef, bool) {
+	switch code {
+	case "$id":
+		return fieldRef{fieldType: types.FieldTypeID}, true
+	case "$revision":
+		return fieldRef{fieldType: types.FieldTypeRevision}, true
+	}
+	if prop, ok := e.a.fields[code]; ok {
+		return fieldRef{fieldType: prop.Type}, prop.Type != types.FieldTypeSubtable
+	}
+	for table, prop := range e.a.fields {
+		if inner, ok := prop.Fields[code]; ok && prop.Type == types.FieldTypeSubtable {
+			return fieldRef{fieldType: inner.Type, table: table}, true
+		}
+	}
+	return fieldRef{}, false
+}
+
+// check は条件式のフィールドが全て存在するかを確認する
+func (e *evaluator) check(expr query.Expr) error {
+	switch x := expr.(type) {
+	case *query.LogicalExpr:
+		if err := e.check(x.Left); err != nil {
+			return err
+		}
+		return e.check(x.Right)
+	case *query.ComparisonExpr:
+		if _, ok := e.lookup(x.Field); !ok {
+			return fieldNotFound(x.Field)
+		}
+	}
+	return nil
+}
+
+// match はレコードが条件式に一致するかを返す
+func (e *evaluator) match(rec *storedRecord, expr query.Expr) bool {
+	switch x := expr.(type) {
+	case *query.LogicalExpr:
+		if x.Operator == "or" {
+			return e.match(rec, x.Left) || e.match(rec, x.Right)
+		}
+		return e.match(rec, x.Left) && e.match(rec, x.Right)
+	case *query.ComparisonExpr:
+		return e.compare(rec, x)
+	}
+	return false
+}
+
+// compare はフィールドの値を比較する
+// 複数の値を持つフィールド（チェックボックス、ユーザー選択など）とテーブル内のフィールドは、いずれかの値が一致すれば一致とする
+// 否定の演算子（!=、not in、not like）は、一致する値が1つもない場合に一致とする
+func (e *evaluator) compare(rec *storedRecord, x *query.ComparisonExpr) bool {
+	ref, _ := e.lookup(x.Field)
+	values := e.candidates(rec, x.Field, ref)
+	switch x.Operator {
+	case "is empty":
+		return len(values) == 0
+	case "is not empty":
+		return len(values) > 0
+	}
+	if len(values) == 0 {
+		values = []string{""}
+	}
+
+	op, negate := x.Operator, false
+	switch op {
+	case "!=":
+		op, negate = "=", true
+	case "not in":
+		op, negate = "in", true
+	case "not like":
+		op, negate = "like", true
+	}
+	matched := slices.ContainsFunc(values, func(v string) bool {
+		return e.matchValue(ref.fieldType, v, op, x.Values)
+	})
+	return matched != negate
+}
+
+// matchValue は1つの値を比較する
+func (e *evaluator) matchValue(fieldType, v, op string, operands []query.Value) bool {
+	switch op {
+	case "in":
+		return slices.ContainsFunc(operands, func(operand query.Value) bool {
+			return e.matchValue(fieldType, v, "=", []query.Value{operand})
+		})
+	case "like":
+		return strings.Contains(strings.ToLower(v), strings.ToLower(e.text(operands[0])))
+	}
+
+	operand := operands[0]
+	switch fieldType {
+	case types.FieldTypeDate, types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime:
+		t, ok := parsePoint(v)
+		from, to, ok2 := e.interval(operand)
+		if !ok || !ok2 {
+			return op == "=" && v == operand.Text
+		}
+		switch op {
+		case "=":
+			return !t.Before(from) && t.Before(to)
+		case ">":
+			return !t.Before(to)
+		case "<":
+			return t.Before(from)
+		case ">=":
+			return !t.Before(from)
+		case "<=":
+			return t.Before(to)
+		}
+		return false
+	}
+
+	c := compareText(v, e.text(operand))
+	switch op {
+	case "=":
+		return c == 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// candidates はレコードのフィールドの値を文字列で返す（空の値は含まない）
+// ユーザー選択などはコード、添付ファイルはファイル名を返す
+func (e *evaluator) candidates(rec *storedRecord, code string, ref fieldRef) []string {
+	var raw []any
+	switch {
+	case code == "$id":
+		raw = []any{strconv.FormatInt(rec.id, 10)}
+	case code == "$revision":
+		raw = []any{strconv.FormatInt(rec.revision, 10)}
+	case ref.table != "":
+		rows, _ := rec.fields[ref.table].([]any)
+		for _, row := range rows {
+			r, _ := row.(map[string]any)
+			cells, _ := r["value"].(map[string]any)
+			cell, _ := cells[code].(map[string]any)
+			raw = append(raw, cell["value"])
+		}
+	default:
+		raw = []any{rec.fields[code]}
+	}
+
+	var values []string
+	var add func(v any)
+	add = func(v any) {
+		switch v := v.(type) {
+		case string:
+			if v != "" {
+				values = append(values, v)
+			}
+		case []any:
+			for _, item := range v {
+				add(item)
+			}
+		case map[string]any:
+			if name, ok := v["name"].(string); ok && v["fileKey"] != nil {
+				add(name)
+			} else {
+				add(v["code"])
+			}
+		}
+	}
+	for _, v := range raw {
+		add(v)
+	}
+	return values
+}
+
+// text は値を文字列で返す（LOGINUSER()はServer.Userのコード、日付の関数は日付）
+func (e *evaluator) text(v query.Value) string {
+	if v.Kind != query.FunctionValue {
+		return v.Text
+	}
+	if v.Text == "LOGINUSER" {
+		return e.user
+	}
+	if from, _, ok := e.interval(v); ok {
+		return from.Format(dateLayout)
+	}
+	return ""
+}
+
+// interval は日付・日時の値が表す期間 [from, to) を返す
+// 日付は1日、日時は1分、THIS_MONTH()などの関数は期間全体を表す
+func (e *evaluator) interval(v query.Value) (time.Time, time.Time, bool) {
+	if v.Kind != query.FunctionValue {
+		if t, err := time.Parse(dateLayout, v.Text); err == nil {
+			return t, t.AddDate(0, 0, 1), true
+		}
+		if t, ok := parseDateTime(v.Text); ok {
+			return t, t.Add(time.Minute), true
+		}
+		return time.Time{}, time.Time{}, false
+	}
+
+	now := e.now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := func(t time.Time) (time.Time, time.Time, bool) { return t, t.AddDate(0, 0, 1), true }
+	arg := ""
+	if len(v.Args) > 0 {
+		arg = v.Args[0]
+	}
+
+	switch v.Text {
+	case "NOW":
+		t := now.Truncate(time.Minute)
+		return t, t.Add(time.Minute), true
+	case "TODAY":
+		return day(today)
+	case "YESTERDAY":
+		return day(today.AddDate(0, 0, -1))
+	case "TOMORROW":
+		return day(today.AddDate(0, 0, 1))
+	case "FROM_TODAY":
+		if len(v.Args) != 2 {
+			return time.Time{}, time.Time{}, false
+		}
+		n, err := strconv.Atoi(v.Args[0])
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		switch v.Args[1] {
+		case "DAYS":
+			return day(today.AddDate(0, 0, n))
+		case "WEEKS":
+			return day(today.AddDate(0, 0, 7*n))
+		case "MONTHS":
+			return day(today.AddDate(0, n, 0))
+		case "YEARS":
+			return day(today.AddDate(n, 0, 0))
+		}
+	case "THIS_WEEK", "LAST_WEEK", "NEXT_WEEK":
+		start := today.AddDate(0, 0, -int(today.Weekday()))
+		start = start.AddDate(0, 0, 7*offsetOf(v.Text))
+		if arg == "" {
+			return start, start.AddDate(0, 0, 7), true
+		}
+		for i := range 7 {
+			if d := start.AddDate(0, 0, i); strings.EqualFold(d.Weekday().String(), arg) {
+				return day(d)
+			}
+		}
+	case "THIS_MONTH", "LAST_MONTH", "NEXT_MONTH":
+		start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, offsetOf(v.Text), 0)
+		end := start.AddDate(0, 1, 0)
+		switch arg {
+		case "":
+			return start, end, true
+		case "LAST":
+			return day(end.AddDate(0, 0, -1))
+		}
+		if n, err := strconv.Atoi(arg); err == nil {
+			return day(start.AddDate(0, 0, n-1))
+		}
+	case "THIS_YEAR", "LAST_YEAR", "NEXT_YEAR":
+		start := time.Date(today.Year()+offsetOf(v.Text), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// offsetOf はTHIS_ / LAST_ / NEXT_ の関数の期間のずれを返す
+func offsetOf(function string) int {
+	switch {
+	case strings.HasPrefix(function, "LAST_"):
+		return -1
+	case strings.HasPrefix(function, "NEXT_"):
+		return 1
+	}
+	return 0
+}
+
+// parsePoint は日付・日時の値を時刻に変換する（日付はUTCの0時）
+func parsePoint(v string) (time.Time, bool) {
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		return t, true
+	}
+	return parseDateTime(v)
+}
+
+// compareText は値を比較する（両方が数値の場合は数値として比較する）
+func compareText(x, y string) int {
+	if a, ok := parseRat(x); ok {
+		if b, ok := parseRat(y); ok {
+			return a.Cmp(b)
+		}
+	}
+	return strings.Compare(x, y)
+}
+
+func parseRat(s string) (*big.Rat, bool) {
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
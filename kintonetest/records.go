@@ -0,0 +1,1128 @@
+package kintonetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+	"github.com/goqoo-on-kintone/goten/validate"
+)
+
+// kintoneの上限
+const (
+	maxWriteRecords = 100 // 一度に追加・更新・削除できるレコード数
+	maxComments     = 10  // 一度に取得できるコメント数
+)
+
+const msgDuplicated = "値がほかのレコードと重複しています。"
+
+// dateTimeLayout は日時の値の形式
+const dateTimeLayout = "2006-01-02T15:04:05Z"
+
+// systemFields はフォームに追加するシステムのフィールド（同じタイプのフィールドがない場合）
+var systemFields = []app.FieldProperty{
+	{Type: types.FieldTypeRecordNumber, Code: "レコード番号", Label: "レコード番号"},
+	{Type: types.FieldTypeCreator, Code: "作成者", Label: "作成者"},
+	{Type: types.FieldTypeCreatedTime, Code: "作成日時", Label: "作成日時"},
+	{Type: types.FieldTypeModifier, Code: "更新者", Label: "更新者"},
+	{Type: types.FieldTypeUpdatedTime, Code: "更新日時", Label: "更新日時"},
+}
+
+// processFields はプロセス管理が有効な場合に追加するフィールド
+var processFields = []app.FieldProperty{
+	{Type: types.FieldTypeStatus, Code: "ステータス", Label: "ステータス"},
+	{Type: types.FieldTypeStatusAssignee, Code: "作業者", Label: "作業者"},
+}
+
+// writeMode はフィールドの値を設定する操作
+type writeMode int
+
+const (
+	modeSeed   writeMode = iota // 初期データ（全てのフィールドをそのまま設定する）
+	modeAdd                     // レコードの追加
+	modeUpdate                  // レコードの更新
+)
+
+// storedRecord は保存したレコード
+// fieldsの値はJSONを変換した形式（string / []any / map[string]any）で保持する
+type storedRecord struct {
+	id       int64
+	revision int64
+	fields   map[string]any
+}
+
+// appState はアプリの設定とデータ
+type appState struct {
+	App
+	fields     map[string]app.FieldProperty
+	validator  *validate.Validator
+	createdAt  time.Time
+	records    map[int64]*storedRecord
+	comments   map[int64][]record.Comment
+	commentSeq map[int64]int64
+	nextID     int64
+	nextRowID  int64
+}
+
+// newAppState はアプリを登録用に変換し、初期データのレコードを読み込む
+func (s *Server) newAppState(a App) (*appState, error) {
+	if a.ID == "" {
+		return nil, fmt.Errorf("アプリIDがありません")
+	}
+	fields := map[string]app.FieldProperty{}
+	for code, prop := range a.Fields {
+		if prop.Type == "" {
+			return nil, fmt.Errorf("フィールド%sのtypeがありません", code)
+		}
+		if prop.Code == "" {
+			prop.Code = code
+		}
+		fields[code] = prop
+	}
+	addFields := func(props []app.FieldProperty) {
+		for _, prop := range props {
+			if _, ok := fields[prop.Code]; !ok && fieldOfType(fields, prop.Type) == "" {
+				fields[prop.Code] = prop
+			}
+		}
+	}
+	addFields(systemFields)
+	if a.Process != nil && a.Process.Enable {
+		addFields(processFields)
+	}
+
+	state := &appState{
+		App:        a,
+		fields:     fields,
+		validator:  validate.New(&app.GetFormFieldsResult{Properties: fields}),
+		createdAt:  s.now(),
+		records:    map[int64]*storedRecord{},
+		comments:   map[int64][]record.Comment{},
+		commentSeq: map[int64]int64{},
+	}
+	state.Records = nil
+	for i, raw := range a.Records {
+		if err := s.seedRecord(state, raw); err != nil {
+			return nil, fmt.Errorf("レコード%dの読み込みエラー: %w", i, err)
+		}
+	}
+	return state, nil
+}
+
+// clone はアプリのデータを複製する（バルクリクエストなどの取り消し用）
+func (a *appState) clone() *appState {
+	c := *a
+	c.records = make(map[int64]*storedRecord, len(a.records))
+	for id, rec := range a.records {
+		c.records[id] = &storedRecord{id: rec.id, revision: rec.revision, fields: cloneValue(rec.fields).(map[string]any)}
+	}
+	c.comments = make(map[int64][]record.Comment, len(a.comments))
+	for id, comments := range a.comments {
+		c.comments[id] = slices.Clone(comments)
+	}
+	c.commentSeq = maps.Clone(a.commentSeq)
+	return &c
+}
+
+// sortedRecords はレコードを$idの昇順で返す
+func (a *appState) sortedRecords() []*storedRecord {
+	ids := slices.Sorted(maps.Keys(a.records))
+	records := make([]*storedRecord, len(ids))
+	for i, id := range ids {
+		records[i] = a.records[id]
+	}
+	return records
+}
+
+// render はレコードをkintoneのレスポンスの形式に変換する（fieldsを指定した場合はそのフィールドのみ）
+func (a *appState) render(rec *storedRecord, fields []string) map[string]any {
+	out := map[string]any{}
+	include := func(code string) bool { return len(fields) == 0 || slices.Contains(fields, code) }
+	if include("$id") {
+		out["$id"] = map[string]any{"type": types.FieldTypeID, "value": strconv.FormatInt(rec.id, 10)}
+	}
+	if include("$revision") {
+		out["$revision"] = map[string]any{"type": types.FieldTypeRevision, "value": strconv.FormatInt(rec.revision, 10)}
+	}
+	for code, value := range rec.fields {
+		if include(code) {
+			out[code] = map[string]any{"type": a.fields[code].Type, "value": cloneValue(value)}
+		}
+	}
+	return out
+}
+
+// findRecord は$idのレコードを返す
+func (a *appState) findRecord(id flexString) (*storedRecord, error) {
+	rec, ok := a.records[parseID(string(id))]
+	if !ok {
+		return nil, recordNotFound(id)
+	}
+	return rec, nil
+}
+
+// findByUpdateKey は重複禁止のフィールドの値でレコードを探す
+func (a *appState) findByUpdateKey(key types.UpdateKey) (*storedRecord, error) {
+	prop, ok := a.fields[key.Field]
+	if !ok || !prop.Unique {
+		return nil, validationError(map[string][]string{"updateKey.field": {"重複禁止が設定されたフィールドを指定してください。"}})
+	}
+	for _, rec := range a.records {
+		if fmt.Sprint(rec.fields[key.Field]) == key.Value {
+			return rec, nil
+		}
+	}
+	return nil, recordNotFound(key.Value)
+}
+
+// checkRevision はリビジョンが最新かどうかを確認する（省略時と-1の場合は確認しない）
+func checkRevision(rec *storedRecord, revision *flexString) error {
+	if revision == nil || *revision == "" || *revision == "-1" {
+		return nil
+	}
+	if string(*revision) != strconv.FormatInt(rec.revision, 10) {
+		return revisionConflict(string(*revision))
+	}
+	return nil
+}
+
+// seedRecord は初期データのレコードを登録する
+func (s *Server) seedRecord(a *appState, raw map[string]json.RawMessage) error {
+	rec := &storedRecord{revision: 1, fields: map[string]any{}}
+	values := map[string]types.FieldValue{}
+	for code, data := range raw {
+		var fv types.FieldValue
+		if err := json.Unmarshal(data, &fv); err != nil {
+			return fmt.Errorf("フィールド%sの形式が不正です: %w", code, err)
+		}
+		switch code {
+		case "$id":
+			rec.id = parseID(fmt.Sprint(fv.Value))
+		case "$revision":
+			rec.revision = parseID(fmt.Sprint(fv.Value))
+		default:
+			if _, ok := a.fields[code]; !ok {
+				return fmt.Errorf("フィールドが見つかりません: %s", code)
+			}
+			values[code] = fv
+		}
+	}
+	if rec.id == 0 {
+		rec.id = a.nextID + 1
+	}
+	if _, ok := a.records[rec.id]; ok {
+		return fmt.Errorf("$idが重複しています: %d", rec.id)
+	}
+	a.nextID = max(a.nextID, rec.id)
+	s.initRecord(a, rec)
+	if err := s.setFields(a, rec, values, modeSeed); err != nil {
+		return err
+	}
+	a.records[rec.id] = rec
+	return nil
+}
+
+// initRecord は新しいレコードの全てのフィールドに初期値を設定する
+func (s *Server) initRecord(a *appState, rec *storedRecord) {
+	now := s.now().Format(dateTimeLayout)
+	user := map[string]any{"code": s.User.Code, "name": s.User.Name}
+	for code, prop := range a.fields {
+		switch prop.Type {
+		case types.FieldTypeRecordNumber:
+			rec.fields[code] = strconv.FormatInt(rec.id, 10)
+		case types.FieldTypeCreator, types.FieldTypeModifier:
+			rec.fields[code] = maps.Clone(user)
+		case types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime:
+			rec.fields[code] = now
+		case types.FieldTypeStatus:
+			rec.fields[code] = a.initialStatus()
+		case types.FieldTypeSubtable:
+			rec.fields[code] = []any{}
+		default:
+			rec.fields[code] = normalizeValue(prop.Type, prop.DefaultValue)
+		}
+	}
+}
+
+// initialStatus はプロセス管理の最初のステータスを返す
+func (a *appState) initialStatus() string {
+	if a.Process == nil {
+		return ""
+	}
+	status, index := "", -1
+	for name, state := range a.Process.States {
+		n, _ := strconv.Atoi(state.Index)
+		if index < 0 || n < index {
+			status, index = name, n
+		}
+	}
+	return status
+}
+
+// setFields はフィールドの値を変換してレコードに設定する
+// 追加・更新では、自動で設定されるフィールド（レコード番号、計算、ステータスなど）の値は無視する
+func (s *Server) setFields(a *appState, rec *storedRecord, values map[string]types.FieldValue, mode writeMode) error {
+	for code, fv := range values {
+		prop, ok := a.fields[code]
+		if !ok {
+			continue
+		}
+		value, err := jsonValue(fv.Value)
+		if err != nil {
+			return badRequest(fmt.Sprintf("フィールド%sの値が不正です: %v", code, err))
+		}
+		switch prop.Type {
+		case types.FieldTypeRecordNumber, types.FieldTypeCalc, types.FieldTypeStatus, types.FieldTypeStatusAssignee, types.FieldTypeCategory:
+			if mode != modeSeed {
+				continue
+			}
+		case types.FieldTypeCreator, types.FieldTypeCreatedTime, types.FieldTypeModifier, types.FieldTypeUpdatedTime:
+			if mode == modeUpdate {
+				continue
+			}
+		case types.FieldTypeSubtable:
+			rows, err := s.subtable(a, prop, value, rec.fields[code], mode)
+			if err != nil {
+				return err
+			}
+			rec.fields[code] = rows
+			continue
+		case types.FieldTypeFile:
+			if mode != modeSeed {
+				files, err := s.attachFiles(value)
+				if err != nil {
+					return err
+				}
+				rec.fields[code] = files
+				continue
+			}
+		}
+		rec.fields[code] = normalizeValue(prop.Type, value)
+	}
+	return nil
+}
+
+// subtable はテーブルの行を変換する
+// 既存の行のIDを指定した行は、指定していないフィールドの値を引き継ぐ。それ以外の行には新しいIDを割り当てる
+func (s *Server) subtable(a *appState, prop app.FieldProperty, value, old any, mode writeMode) ([]any, error) {
+	oldRows := map[string]map[string]any{}
+	if list, ok := old.([]any); ok {
+		for _, row := range list {
+			r, _ := row.(map[string]any)
+			if cells, ok := r["value"].(map[string]any); ok {
+				oldRows[fmt.Sprint(r["id"])] = cells
+			}
+		}
+	}
+
+	list, _ := value.([]any)
+	rows := make([]any, 0, len(list))
+	for _, row := range list {
+		r, _ := row.(map[string]any)
+		given, _ := r["value"].(map[string]any)
+		id := ""
+		if r["id"] != nil {
+			id = fmt.Sprint(r["id"])
+		}
+		prev, exists := oldRows[id]
+		if mode == modeSeed && id != "" {
+			a.nextRowID = max(a.nextRowID, parseID(id))
+		} else if !exists {
+			a.nextRowID++
+			id = strconv.FormatInt(a.nextRowID, 10)
+		}
+
+		cells := map[string]any{}
+		for code, inner := range prop.Fields {
+			cell, ok := given[code].(map[string]any)
+			switch {
+			case ok && inner.Type == types.FieldTypeFile && mode != modeSeed:
+				files, err := s.attachFiles(cell["value"])
+				if err != nil {
+					return nil, err
+				}
+				cells[code] = map[string]any{"type": inner.Type, "value": files}
+			case ok:
+				cells[code] = map[string]any{"type": inner.Type, "value": normalizeValue(inner.Type, cell["value"])}
+			case exists && prev[code] != nil:
+				cells[code] = prev[code]
+			default:
+				cells[code] = map[string]any{"type": inner.Type, "value": normalizeValue(inner.Type, inner.DefaultValue)}
+			}
+		}
+		rows = append(rows, map[string]any{"id": id, "value": cells})
+	}
+	return rows, nil
+}
+
+// touch はレコードの更新者・更新日時・リビジョンを更新する
+func (s *Server) touch(a *appState, rec *storedRecord, revisions int64) {
+	now := s.now().Format(dateTimeLayout)
+	for code, prop := range a.fields {
+		switch prop.Type {
+		case types.FieldTypeModifier:
+			rec.fields[code] = map[string]any{"code": s.User.Code, "name": s.User.Name}
+		case types.FieldTypeUpdatedTime:
+			rec.fields[code] = now
+		}
+	}
+	rec.revision += revisions
+}
+
+// normalizeValue はフィールドタイプに応じて値をkintoneのレスポンスと同じ形式に揃える
+func normalizeValue(fieldType string, value any) any {
+	value, _ = jsonValue(value)
+	switch fieldType {
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeCategory:
+		list := []any{}
+		switch v := value.(type) {
+		case []any:
+			list = v
+		case string:
+			if v != "" {
+				list = []any{v}
+			}
+		}
+		return list
+
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect, types.FieldTypeStatusAssignee:
+		list := []any{}
+		items, _ := value.([]any)
+		for _, item := range items {
+			list = append(list, entity(item))
+		}
+		return list
+
+	case types.FieldTypeCreator, types.FieldTypeModifier:
+		return entity(value)
+
+	case types.FieldTypeFile:
+		if list, ok := value.([]any); ok {
+			return list
+		}
+		return []any{}
+
+	case types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime:
+		if t, ok := parseDateTime(value); ok {
+			return t.Format(dateTimeLayout)
+		}
+		return ""
+
+	case types.FieldTypeTime:
+		s, _ := value.(string)
+		if len(s) > len("15:04") {
+			s = s[:len("15:04")]
+		}
+		return s
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// entity はユーザー・組織・グループの値を{code, name}に揃える（名前がない場合はコード）
+func entity(value any) map[string]any {
+	m, _ := value.(map[string]any)
+	code, _ := m["code"].(string)
+	name, _ := m["name"].(string)
+	if name == "" {
+		name = code
+	}
+	return map[string]any{"code": code, "name": name}
+}
+
+// parseDateTime は日時の値を解析する
+func parseDateTime(value any) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Truncate(time.Minute), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// jsonValue は値をJSONに変換した形式（string / []any / map[string]any など）に揃える
+func jsonValue(value any) (any, error) {
+	switch value.(type) {
+	case nil, string, float64, []any, map[string]any:
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// cloneValue はJSONを変換した形式の値を複製する
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = cloneValue(item)
+		}
+		return c
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, item := range v {
+			c[key] = cloneValue(item)
+		}
+		return c
+	}
+	return value
+}
+
+// fieldOfType はフィールドタイプのフィールドコードを返す（ない場合は空文字列）
+func fieldOfType(fields map[string]app.FieldProperty, fieldType string) string {
+	for code, prop := range fields {
+		if prop.Type == fieldType {
+			return code
+		}
+	}
+	return ""
+}
+
+// toDynamic はレスポンスの形式のレコードをrecord.Dynamicに変換する
+func toDynamic(rec map[string]any) record.Dynamic {
+	var d record.Dynamic
+	data, _ := json.Marshal(rec)
+	json.Unmarshal(data, &d)
+	return d
+}
+
+// --- 書き込みの検証 ---
+
+// checkRecords は書き込むレコードを検証する
+// フォームの設定による検証に加えて、重複禁止のフィールドを保存済みのレコードと照合する
+// prefixesはエラーのキーの接頭辞（record、records[0] など）、selfは更新するレコードの$id（追加の場合は0）
+func (a *appState) checkRecords(err error, prefixes []string, records []map[string]types.FieldValue, self []int64) error {
+	errs := validate.Messages(err)
+	if err != nil && errs == nil {
+		return err
+	}
+	if errs == nil {
+		errs = map[string][]string{}
+	}
+	for i, rec := range records {
+		for code, fv := range rec {
+			prop := a.fields[code]
+			if !prop.Unique {
+				continue
+			}
+			value := normalizeValue(prop.Type, fv.Value)
+			if value == "" {
+				continue
+			}
+			for id, stored := range a.records {
+				if id != self[i] && stored.fields[code] == value {
+					key := prefixes[i] + "." + code + ".value"
+					if !slices.Contains(errs[key], msgDuplicated) {
+						errs[key] = append(errs[key], msgDuplicated)
+					}
+					break
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return validationError(errs)
+	}
+	return nil
+}
+
+// withoutSystemKeys は書き込むレコードから$idと$revisionを除く
+func withoutSystemKeys(rec map[string]types.FieldValue) map[string]types.FieldValue {
+	if _, ok := rec["$id"]; !ok {
+		if _, ok := rec["$revision"]; !ok {
+			return rec
+		}
+	}
+	rec = maps.Clone(rec)
+	delete(rec, "$id")
+	delete(rec, "$revision")
+	return rec
+}
+
+// --- レコードAPI ---
+
+// flexString は文字列または数値のJSONの値（kintoneはIDなどをどちらの形式でも受け付ける）
+type flexString string
+
+// UnmarshalJSON は文字列または数値から変換する
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*f = ""
+	case string:
+		*f = flexString(v)
+	case float64:
+		*f = flexString(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Errorf("文字列または数値ではありません: %s", data)
+	}
+	return nil
+}
+
+// findApp はアプリを返す
+func (s *Server) findApp(id flexString) (*appState, error) {
+	a, ok := s.apps[string(id)]
+	if !ok {
+		return nil, appNotFound(string(id))
+	}
+	return a, nil
+}
+
+func (s *Server) getRecord(body json.RawMessage) (any, error) {
+	var req struct {
+		App flexString `json:"app"`
+		ID  flexString `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"record": a.render(rec, nil)}, nil
+}
+
+func (s *Server) getRecords(body json.RawMessage) (any, error) {
+	var req struct {
+		App        flexString `json:"app"`
+		Fields     []string   `json:"fields"`
+		Query      string     `json:"query"`
+		TotalCount any        `json:"totalCount"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	matched, page, err := s.selectRecords(a, req.Query, true)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]any, len(page))
+	for i, rec := range page {
+		records[i] = a.render(rec, req.Fields)
+	}
+	var totalCount any
+	if req.TotalCount == true || req.TotalCount == "true" {
+		totalCount = strconv.Itoa(matched)
+	}
+	return map[string]any{"records": records, "totalCount": totalCount}, nil
+}
+
+// insert はレコードを追加する
+func (s *Server) insert(a *appState, values map[string]types.FieldValue) (*storedRecord, error) {
+	a.nextID++
+	rec := &storedRecord{id: a.nextID, revision: 1, fields: map[string]any{}}
+	s.initRecord(a, rec)
+	if err := s.setFields(a, rec, values, modeAdd); err != nil {
+		return nil, err
+	}
+	a.records[rec.id] = rec
+	return rec, nil
+}
+
+func (s *Server) addRecord(body json.RawMessage) (any, error) {
+	var req struct {
+		App    flexString                  `json:"app"`
+		Record map[string]types.FieldValue `json:"record"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	values := withoutSystemKeys(req.Record)
+	if err := a.checkRecords(a.validator.AddRecord(values), []string{"record"}, []map[string]types.FieldValue{values}, []int64{0}); err != nil {
+		return nil, err
+	}
+	rec, err := s.insert(a, values)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"id": strconv.FormatInt(rec.id, 10), "revision": strconv.FormatInt(rec.revision, 10)}, nil
+}
+
+func (s *Server) addRecords(body json.RawMessage) (any, error) {
+	var req struct {
+		App     flexString                    `json:"app"`
+		Records []map[string]types.FieldValue `json:"records"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(len(req.Records)); err != nil {
+		return nil, err
+	}
+	prefixes := make([]string, len(req.Records))
+	records := make([]map[string]types.FieldValue, len(req.Records))
+	for i, rec := range req.Records {
+		prefixes[i] = fmt.Sprintf("records[%d]", i)
+		records[i] = withoutSystemKeys(rec)
+	}
+	if err := a.checkRecords(a.validator.AddRecords(records), prefixes, records, make([]int64, len(records))); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(records))
+	revisions := make([]string, len(records))
+	for i, values := range records {
+		rec, err := s.insert(a, values)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = strconv.FormatInt(rec.id, 10)
+		revisions[i] = strconv.FormatInt(rec.revision, 10)
+	}
+	return map[string]any{"ids": ids, "revisions": revisions}, nil
+}
+
+// updateItem は更新するレコードの指定
+type updateItem struct {
+	ID        flexString                  `json:"id"`
+	UpdateKey *types.UpdateKey            `json:"updateKey"`
+	Record    map[string]types.FieldValue `json:"record"`
+	Revision  *flexString                 `json:"revision"`
+}
+
+// target は更新するレコードを返す
+func (item updateItem) target(a *appState) (*storedRecord, error) {
+	var rec *storedRecord
+	var err error
+	if item.UpdateKey != nil {
+		rec, err = a.findByUpdateKey(*item.UpdateKey)
+	} else {
+		rec, err = a.findRecord(item.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRevision(rec, item.Revision); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// update はレコードを更新する
+func (s *Server) update(a *appState, rec *storedRecord, values map[string]types.FieldValue) error {
+	if err := s.setFields(a, rec, values, modeUpdate); err != nil {
+		return err
+	}
+	s.touch(a, rec, 1)
+	return nil
+}
+
+func (s *Server) updateRecord(body json.RawMessage) (any, error) {
+	var req struct {
+		App flexString `json:"app"`
+		updateItem
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := req.target(a)
+	if err != nil {
+		return nil, err
+	}
+	values := withoutSystemKeys(req.Record)
+	if err := a.checkRecords(a.validator.UpdateRecord(values), []string{"record"}, []map[string]types.FieldValue{values}, []int64{rec.id}); err != nil {
+		return nil, err
+	}
+	if err := s.update(a, rec, values); err != nil {
+		return nil, err
+	}
+	return map[string]any{"revision": strconv.FormatInt(rec.revision, 10)}, nil
+}
+
+func (s *Server) updateRecords(body json.RawMessage) (any, error) {
+	var req struct {
+		App     flexString   `json:"app"`
+		Records []updateItem `json:"records"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(len(req.Records)); err != nil {
+		return nil, err
+	}
+	targets := make([]*storedRecord, len(req.Records))
+	prefixes := make([]string, len(req.Records))
+	records := make([]map[string]types.FieldValue, len(req.Records))
+	items := make([]record.UpdateRecordItem, len(req.Records))
+	self := make([]int64, len(req.Records))
+	for i, item := range req.Records {
+		if targets[i], err = item.target(a); err != nil {
+			return nil, err
+		}
+		prefixes[i] = fmt.Sprintf("records[%d].record", i)
+		records[i] = withoutSystemKeys(item.Record)
+		items[i] = record.UpdateRecordItem{Record: records[i]}
+		self[i] = targets[i].id
+	}
+	if err := a.checkRecords(a.validator.UpdateRecords(items), prefixes, records, self); err != nil {
+		return nil, err
+	}
+	results := make([]record.UpdateRecordsResultItem, len(targets))
+	for i, rec := range targets {
+		if err := s.update(a, rec, records[i]); err != nil {
+			return nil, err
+		}
+		results[i] = record.UpdateRecordsResultItem{ID: strconv.FormatInt(rec.id, 10), Revision: strconv.FormatInt(rec.revision, 10)}
+	}
+	return map[string]any{"records": results}, nil
+}
+
+func (s *Server) deleteRecords(body json.RawMessage) (any, error) {
+	var req struct {
+		App       flexString   `json:"app"`
+		IDs       []flexString `json:"ids"`
+		Revisions []flexString `json:"revisions"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(len(req.IDs)); err != nil {
+		return nil, err
+	}
+	for i, id := range req.IDs {
+		rec, err := a.findRecord(id)
+		if err != nil {
+			return nil, err
+		}
+		if i < len(req.Revisions) {
+			if err := checkRevision(rec, &req.Revisions[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, id := range req.IDs {
+		delete(a.records, parseID(string(id)))
+		delete(a.comments, parseID(string(id)))
+	}
+	return map[string]any{}, nil
+}
+
+// checkCount は一度に書き込むレコード数を確認する
+func checkCount(n int) error {
+	if n > maxWriteRecords {
+		return validationError(map[string][]string{"records": {fmt.Sprintf("%d件以下で指定してください。", maxWriteRecords)}})
+	}
+	return nil
+}
+
+// --- コメントAPI ---
+
+func (s *Server) getComments(body json.RawMessage) (any, error) {
+	var req struct {
+		App    flexString `json:"app"`
+		Record flexString `json:"record"`
+		Order  string     `json:"order"`
+		Offset int        `json:"offset"`
+		Limit  int        `json:"limit"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(req.Record)
+	if err != nil {
+		return nil, err
+	}
+	if req.Limit == 0 {
+		req.Limit = maxComments
+	}
+	if req.Limit < 0 || req.Limit > maxComments || req.Offset < 0 {
+		return nil, validationError(map[string][]string{"limit": {fmt.Sprintf("1以上%d以下である必要があります。", maxComments)}})
+	}
+
+	comments := slices.Clone(a.comments[rec.id])
+	if req.Order != "asc" {
+		slices.Reverse(comments)
+	}
+	start := min(req.Offset, len(comments))
+	end := min(start+req.Limit, len(comments))
+	page := comments[start:end]
+	if page == nil {
+		page = []record.Comment{}
+	}
+	rest, before := end < len(comments), start > 0
+	if req.Order == "asc" {
+		return record.GetRecordCommentsResult{Comments: page, Older: before, Newer: rest}, nil
+	}
+	return record.GetRecordCommentsResult{Comments: page, Older: rest, Newer: before}, nil
+}
+
+func (s *Server) addComment(body json.RawMessage) (any, error) {
+	var req struct {
+		App     flexString            `json:"app"`
+		Record  flexString            `json:"record"`
+		Comment record.CommentContent `json:"comment"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(req.Record)
+	if err != nil {
+		return nil, err
+	}
+	if req.Comment.Text == "" {
+		return nil, validationError(map[string][]string{"comment.text": {"必須です。"}})
+	}
+	mentions := req.Comment.Mentions
+	if mentions == nil {
+		mentions = []record.MentionUser{}
+	}
+	a.commentSeq[rec.id]++
+	comment := record.Comment{
+		ID:        strconv.FormatInt(a.commentSeq[rec.id], 10),
+		Text:      req.Comment.Text,
+		CreatedAt: s.now().Format(dateTimeLayout),
+		Creator:   record.CommentUser{Code: s.User.Code, Name: s.User.Name},
+		Mentions:  mentions,
+	}
+	a.comments[rec.id] = append(a.comments[rec.id], comment)
+	return map[string]any{"id": comment.ID}, nil
+}
+
+func (s *Server) deleteComment(body json.RawMessage) (any, error) {
+	var req struct {
+		App     flexString `json:"app"`
+		Record  flexString `json:"record"`
+		Comment flexString `json:"comment"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(req.Record)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(a.comments[rec.id], func(c record.Comment) bool { return c.ID == string(req.Comment) })
+	if i < 0 {
+		return nil, newAPIError(http.StatusNotFound, kintoneError.CodeRecordNotFound, fmt.Sprintf("指定したコメント（id: %s）が見つかりません。", req.Comment))
+	}
+	a.comments[rec.id] = slices.Delete(a.comments[rec.id], i, i+1)
+	return map[string]any{}, nil
+}
+
+// --- プロセス管理API ---
+
+// statusItem はステータスを更新するレコードの指定
+type statusItem struct {
+	ID       flexString  `json:"id"`
+	Action   string      `json:"action"`
+	Assignee string      `json:"assignee"`
+	Revision *flexString `json:"revision"`
+}
+
+// processEnabled はプロセス管理が有効かどうかを確認する
+func (a *appState) processEnabled() error {
+	if a.Process == nil || !a.Process.Enable {
+		return validationError(map[string][]string{"app": {"プロセス管理が有効になっていません。"}})
+	}
+	return nil
+}
+
+// changeStatus はアクションを実行してステータスと作業者を更新する（リビジョンは2つ増える）
+func (s *Server) changeStatus(a *appState, item statusItem) (*storedRecord, error) {
+	if err := a.processEnabled(); err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRevision(rec, item.Revision); err != nil {
+		return nil, err
+	}
+
+	statusField := fieldOfType(a.fields, types.FieldTypeStatus)
+	current, _ := rec.fields[statusField].(string)
+	var action *app.Action
+	for i, act := range a.Process.Actions {
+		if act.Name == item.Action && act.From == current {
+			action = &a.Process.Actions[i]
+			break
+		}
+	}
+	if action == nil {
+		return nil, validationError(map[string][]string{"action": {fmt.Sprintf("ステータス「%s」では実行できないアクションです。", current)}})
+	}
+	if action.FilterCond != "" {
+		ok, err := s.matchCondition(a, rec, action.FilterCond)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, validationError(map[string][]string{"action": {"アクションの実行条件を満たしていません。"}})
+		}
+	}
+
+	assignees := []any{}
+	if item.Assignee != "" {
+		assignees = append(assignees, map[string]any{"code": item.Assignee, "name": item.Assignee})
+	} else if state := a.Process.States[action.To]; state.Assignee != nil {
+		for _, e := range state.Assignee.Entities {
+			switch e.Entity.Type {
+			case "USER":
+				assignees = append(assignees, map[string]any{"code": e.Entity.Code, "name": e.Entity.Code})
+			case "CREATOR":
+				if creator := fieldOfType(a.fields, types.FieldTypeCreator); creator != "" {
+					assignees = append(assignees, cloneValue(rec.fields[creator]))
+				}
+			}
+		}
+	}
+	rec.fields[statusField] = action.To
+	if assigneeField := fieldOfType(a.fields, types.FieldTypeStatusAssignee); assigneeField != "" {
+		rec.fields[assigneeField] = assignees
+	}
+	s.touch(a, rec, 2)
+	return rec, nil
+}
+
+func (s *Server) updateStatus(body json.RawMessage) (any, error) {
+	var req struct {
+		App flexString `json:"app"`
+		statusItem
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := s.changeStatus(a, req.statusItem)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"revision": strconv.FormatInt(rec.revision, 10)}, nil
+}
+
+func (s *Server) updateStatuses(body json.RawMessage) (any, error) {
+	var req struct {
+		App     flexString   `json:"app"`
+		Records []statusItem `json:"records"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(len(req.Records)); err != nil {
+		return nil, err
+	}
+	results := make([]record.UpdateRecordsResultItem, len(req.Records))
+	for i, item := range req.Records {
+		rec, err := s.changeStatus(a, item)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = record.UpdateRecordsResultItem{ID: strconv.FormatInt(rec.id, 10), Revision: strconv.FormatInt(rec.revision, 10)}
+	}
+	return map[string]any{"records": results}, nil
+}
+
+func (s *Server) updateAssignees(body json.RawMessage) (any, error) {
+	var req struct {
+		App       flexString  `json:"app"`
+		ID        flexString  `json:"id"`
+		Assignees []string    `json:"assignees"`
+		Revision  *flexString `json:"revision"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	a, err := s.findApp(req.App)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.processEnabled(); err != nil {
+		return nil, err
+	}
+	rec, err := a.findRecord(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRevision(rec, req.Revision); err != nil {
+		return nil, err
+	}
+	assignees := []any{}
+	for _, code := range req.Assignees {
+		assignees = append(assignees, map[string]any{"code": code, "name": code})
+	}
+	if assigneeField := fieldOfType(a.fields, types.FieldTypeStatusAssignee); assigneeField != "" {
+		rec.fields[assigneeField] = assignees
+	}
+	s.touch(a, rec, 1)
+	return map[string]any{"revision": strconv.FormatInt(rec.revision, 10)}, nil
+}
@@ -0,0 +1,415 @@
+// Package kintonetest はテスト用にkintone REST APIを模したサーバーを提供する
+//
+// httptest.Serverでレコード・アプリ・ファイル・バルクリクエストのAPIに応答し、データはメモリに保持する。
+// kintoneの動作のうち、テストでよく使う範囲のみを再現する（アクセス権、ゲストスペースの区別、スペースのAPIなどは扱わない）
+package kintonetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten"
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// codeUnsupported は模していないAPIのエラーコード（kintoneにはないコード）
+const codeUnsupported = "KINTONETEST_UNSUPPORTED"
+
+// apiPathPattern はAPIのパス（/k/v1/records.json、/k/guest/1/v1/records.json）
+var apiPathPattern = regexp.MustCompile(`^/k/(?:guest/\d+/)?v1/(.+)\.json$`)
+
+// App はサーバーに登録するアプリ
+type App struct {
+	ID          types.AppID                  `json:"id"`
+	Code        string                       `json:"code,omitempty"`
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	SpaceID     string                       `json:"spaceId,omitempty"`
+	Fields      map[string]app.FieldProperty `json:"fields"`            // フォームのフィールド（システムのフィールドは省略時に追加する）
+	Layout      []app.LayoutElement          `json:"layout,omitempty"`  // フォームのレイアウト
+	Views       map[string]app.View          `json:"views,omitempty"`   // 一覧
+	Process     *app.ProcessManagement       `json:"process,omitempty"` // プロセス管理（省略時は無効）
+
+	// Records は初期データのレコード（GetRecordsのレスポンスと同じ形式。typeは省略できる）
+	// $idを省略した場合は1から順に割り当てる
+	Records []map[string]json.RawMessage `json:"records,omitempty"`
+}
+
+// Fixture はJSONのフィクスチャの形式
+type Fixture struct {
+	Apps []App `json:"apps"`
+}
+
+// Server はkintone REST APIを模したテスト用のサーバー
+// 全てのリクエストを順に処理するため、複数のgoroutineから同時に使用できる
+type Server struct {
+	*httptest.Server
+
+	// User はレコードの作成者・更新者、コメントの投稿者、LOGINUSER()として扱うユーザー
+	User types.Entity
+	// Now は作成日時・更新日時に使う現在時刻（省略時はtime.Now。kintoneと同様に分単位に切り捨てる）
+	Now func() time.Time
+
+	mu      sync.Mutex
+	apps    map[types.AppID]*appState
+	files   map[string]storedFile
+	cursors map[string]*cursorState
+	seq     int // ファイルキー・カーソルIDの採番
+}
+
+// NewServer は新しいServerを起動する。終了後はCloseを呼び出す
+func NewServer(apps ...App) (*Server, error) {
+	s := &Server{
+		User:    types.Entity{Code: "Administrator", Name: "Administrator"},
+		apps:    map[types.AppID]*appState{},
+		files:   map[string]storedFile{},
+		cursors: map[string]*cursorState{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	for _, a := range apps {
+		if err := s.AddApp(a); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Client はサーバーに接続するgoten.Clientを作成する
+func (s *Server) Client() *goten.Client {
+	return goten.NewClient(goten.Options{
+		BaseURL: s.URL,
+		Auth:    auth.APITokenAuth{Token: "kintonetest"},
+	})
+}
+
+// AddApp はアプリを登録する。同じIDのアプリがある場合は置き換える
+func (s *Server) AddApp(a App) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, err := s.newAppState(a)
+	if err != nil {
+		return fmt.Errorf("アプリ%sの登録エラー: %w", a.ID, err)
+	}
+	s.apps[a.ID] = state
+	return nil
+}
+
+// LoadFixture はJSONのフィクスチャ（Fixtureの形式）のアプリを登録する
+func (s *Server) LoadFixture(data []byte) error {
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return fmt.Errorf("フィクスチャの解析エラー: %w", err)
+	}
+	for _, a := range fixture.Apps {
+		if err := s.AddApp(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFixtureFile はJSONファイルのフィクスチャのアプリを登録する
+func (s *Server) LoadFixtureFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("フィクスチャの読み込みエラー: %w", err)
+	}
+	return s.LoadFixture(data)
+}
+
+// Records はアプリの全レコードを$idの昇順で返す（アプリがない場合はnil）
+func (s *Server) Records(appID types.AppID) []record.Dynamic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[appID]
+	if !ok {
+		return nil
+	}
+	var records []record.Dynamic
+	for _, rec := range a.sortedRecords() {
+		records = append(records, toDynamic(a.render(rec, nil)))
+	}
+	return records
+}
+
+// Record はアプリのレコードを返す
+func (s *Server) Record(appID types.AppID, id types.RecordID) (record.Dynamic, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[appID]
+	if !ok {
+		return record.Dynamic{}, false
+	}
+	rec, ok := a.records[parseID(id)]
+	if !ok {
+		return record.Dynamic{}, false
+	}
+	return toDynamic(a.render(rec, nil)), true
+}
+
+// Comments はレコードのコメントを投稿の古い順で返す
+func (s *Server) Comments(appID types.AppID, id types.RecordID) []record.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[appID]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(a.comments[parseID(id)])
+}
+
+// File はファイルキーのファイルの内容を返す
+func (s *Server) File(fileKey string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[fileKey]
+	return slices.Clone(f.data), ok
+}
+
+// clock は現在時刻を返す
+func (s *Server) clock() time.Time {
+	if s.Now != nil {
+		return s.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// now はレコードに記録する現在時刻（分単位に切り捨てる）を返す
+func (s *Server) now() time.Time {
+	return s.clock().Truncate(time.Minute)
+}
+
+// nextKey は新しいファイルキー・カーソルIDを返す
+func (s *Server) nextKey(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%d", prefix, s.seq)
+}
+
+// handlerFunc はAPIのリクエストボディを処理してレスポンスを返す
+type handlerFunc func(s *Server, body json.RawMessage) (any, error)
+
+// handlers はメソッドとAPI名（/k/v1/とjsonを除いたパス）ごとの処理
+var handlers map[string]handlerFunc
+
+func init() {
+	handlers = map[string]handlerFunc{
+		"GET record":            (*Server).getRecord,
+		"POST record":           (*Server).addRecord,
+		"PUT record":            (*Server).updateRecord,
+		"GET records":           (*Server).getRecords,
+		"POST records":          (*Server).addRecords,
+		"PUT records":           (*Server).updateRecords,
+		"DELETE records":        (*Server).deleteRecords,
+		"POST records/cursor":   (*Server).createCursor,
+		"GET records/cursor":    (*Server).getCursor,
+		"DELETE records/cursor": (*Server).deleteCursor,
+		"GET record/comments":   (*Server).getComments,
+		"POST record/comment":   (*Server).addComment,
+		"DELETE record/comment": (*Server).deleteComment,
+		"PUT record/status":     (*Server).updateStatus,
+		"PUT records/status":    (*Server).updateStatuses,
+		"PUT record/assignees":  (*Server).updateAssignees,
+		"GET app":               (*Server).getApp,
+		"GET apps":              (*Server).getApps,
+		"GET app/form/fields":   (*Server).getFormFields,
+		"GET app/form/layout":   (*Server).getFormLayout,
+		"GET app/views":         (*Server).getViews,
+		"GET app/status":        (*Server).getProcessManagement,
+		"POST bulkRequest":      (*Server).bulkRequest,
+	}
+}
+
+// serveHTTP はリクエストを処理する
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	m := apiPathPattern.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		writeError(w, unsupported(r.Method, r.URL.Path))
+		return
+	}
+	api := m[1]
+
+	// ファイルはJSON以外のリクエスト・レスポンス
+	if api == "file" {
+		switch r.Method {
+		case http.MethodPost:
+			s.uploadFile(w, r)
+			return
+		case http.MethodGet:
+			s.downloadFile(w, r)
+			return
+		}
+	}
+
+	body, err := requestBody(r)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	result, err := s.dispatchAtomic(r.Method, api, body)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// dispatch はAPIの処理を呼び出す（s.muを取得した状態で呼び出す）
+func (s *Server) dispatch(method, api string, body json.RawMessage) (any, error) {
+	handler, ok := handlers[method+" "+api]
+	if !ok {
+		return nil, unsupported(method, api)
+	}
+	return handler(s, body)
+}
+
+// dispatchAtomic はAPIの処理を呼び出し、失敗した場合は変更を取り消す（s.muを取得した状態で呼び出す）
+func (s *Server) dispatchAtomic(method, api string, body json.RawMessage) (any, error) {
+	if method == http.MethodGet {
+		return s.dispatch(method, api, body)
+	}
+	snap := s.snapshot()
+	result, err := s.dispatch(method, api, body)
+	if err != nil {
+		s.restore(snap)
+	}
+	return result, err
+}
+
+// requestBody はJSONのリクエストボディを返す
+// ボディがない場合は、URLのクエリパラメータをJSONのオブジェクトにする
+func requestBody(r *http.Request) (json.RawMessage, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの読み込みエラー: %w", err)
+	}
+	if len(data) > 0 {
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("リクエストのJSONが不正です")
+		}
+		return data, nil
+	}
+	params := map[string]any{}
+	for key, values := range r.URL.Query() {
+		if len(values) == 1 {
+			params[key] = values[0]
+		} else {
+			params[key] = values
+		}
+	}
+	return json.Marshal(params)
+}
+
+// decode はリクエストボディを解析する
+func decode(body json.RawMessage, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return badRequest("リクエストの形式が不正です: " + err.Error())
+	}
+	return nil
+}
+
+// --- エラー ---
+
+// apiError はkintoneのエラーレスポンス
+type apiError struct {
+	status  int
+	body    kintoneError.KintoneRestAPIError
+	results []any // バルクリクエストの場合は各リクエストの結果（失敗したリクエスト以外は空のオブジェクト）
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("[%d] [%s] %s", e.status, e.body.Code, e.body.Message)
+}
+
+// MarshalJSON はkintoneのエラーレスポンスの形式に変換する
+func (e *apiError) MarshalJSON() ([]byte, error) {
+	if e.results != nil {
+		return json.Marshal(map[string]any{"results": e.results})
+	}
+	body := map[string]any{"code": e.body.Code, "id": e.body.ID, "message": e.body.Message}
+	if len(e.body.Errors) > 0 {
+		body["errors"] = e.body.Errors
+	}
+	return json.Marshal(body)
+}
+
+func newAPIError(status int, code, message string) *apiError {
+	return &apiError{status: status, body: kintoneError.KintoneRestAPIError{Code: code, Message: message, ID: "kintonetest"}}
+}
+
+func badRequest(message string) *apiError {
+	return newAPIError(http.StatusBadRequest, "CB_IJ01", message)
+}
+
+func unsupported(method, api string) *apiError {
+	return newAPIError(http.StatusNotFound, codeUnsupported, fmt.Sprintf("kintonetestでは未対応のAPIです: %s %s", method, api))
+}
+
+func appNotFound(id types.AppID) *apiError {
+	return newAPIError(http.StatusNotFound, kintoneError.CodeAppNotFound, fmt.Sprintf("指定したアプリ（id: %s）が見つかりません。", id))
+}
+
+func recordNotFound(id any) *apiError {
+	return newAPIError(http.StatusNotFound, kintoneError.CodeRecordNotFound, fmt.Sprintf("指定したレコード（id: %v）が見つかりません。", id))
+}
+
+func revisionConflict(expected types.Revision) *apiError {
+	return newAPIError(http.StatusConflict, kintoneError.CodeRevisionConflict, fmt.Sprintf("指定したリビジョン（%s）は最新ではありません。", expected))
+}
+
+// validationError は入力エラー（CB_VA01）を作成する
+func validationError(errs map[string][]string) *apiError {
+	e := newAPIError(http.StatusBadRequest, kintoneError.CodeValidation, "入力内容が正しくありません。")
+	e.body.Errors = map[string]any{}
+	for key, messages := range errs {
+		e.body.Errors[key] = map[string]any{"messages": messages}
+	}
+	return e
+}
+
+// toAPIError はエラーをkintoneのエラーレスポンスに変換する
+func toAPIError(err error) *apiError {
+	var e *apiError
+	if errors.As(err, &e) {
+		return e
+	}
+	var restErr *kintoneError.KintoneRestAPIError
+	if errors.As(err, &restErr) {
+		return &apiError{status: restErr.Status, body: *restErr}
+	}
+	return newAPIError(http.StatusInternalServerError, "CB_IJ01", err.Error())
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e := toAPIError(err)
+	writeJSON(w, e.status, e)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// parseID は$idを数値に変換する（不正な場合は0）
+func parseID(id string) int64 {
+	n, _ := strconv.ParseInt(id, 10, 64)
+	return n
+}
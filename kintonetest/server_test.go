@@ -0,0 +1,507 @@
+package kintonetest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten"
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/bulk"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/file"
+	"github.com/goqoo-on-kintone/goten/kintonetest"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+	"github.com/goqoo-on-kintone/goten/validate"
+)
+
+const fixture = `{
+	"apps": [
+		{
+			"id": "1",
+			"name": "受注",
+			"fields": {
+				"件名": {"type": "SINGLE_LINE_TEXT", "required": true},
+				"番号": {"type": "SINGLE_LINE_TEXT", "unique": true},
+				"金額": {"type": "NUMBER"},
+				"区分": {"type": "DROP_DOWN", "options": {"通常": {}, "至急": {}}},
+				"タグ": {"type": "CHECK_BOX", "options": {"A": {}, "B": {}}},
+				"期日": {"type": "DATE"},
+				"担当者": {"type": "USER_SELECT"},
+				"添付": {"type": "FILE"},
+				"明細": {"type": "SUBTABLE", "fields": {
+					"品名": {"type": "SINGLE_LINE_TEXT"},
+					"数量": {"type": "NUMBER"}
+				}}
+			},
+			"records": [
+				{
+					"件名": {"value": "A"}, "番号": {"value": "001"}, "金額": {"value": "100"}, "区分": {"value": "通常"},
+					"タグ": {"value": ["A"]}, "期日": {"value": "2024-01-10"}, "担当者": {"value": [{"code": "sato", "name": "佐藤"}]},
+					"明細": {"value": [{"value": {"品名": {"value": "りんご"}, "数量": {"value": "3"}}}]}
+				},
+				{
+					"件名": {"value": "B"}, "番号": {"value": "002"}, "金額": {"value": "2500"}, "区分": {"value": "至急"},
+					"タグ": {"value": ["A", "B"]}, "期日": {"value": "2024-02-01"},
+					"明細": {"value": [{"value": {"品名": {"value": "みかん"}}}]}
+				},
+				{"件名": {"value": "C"}, "番号": {"value": "003"}, "金額": {"value": "50"}, "区分": {"value": "通常"}}
+			]
+		},
+		{
+			"id": "2",
+			"name": "申請",
+			"fields": {"件名": {"type": "SINGLE_LINE_TEXT"}, "金額": {"type": "NUMBER"}},
+			"process": {
+				"enable": true,
+				"states": {
+					"未処理": {"name": "未処理", "index": "0"},
+					"承認待ち": {"name": "承認待ち", "index": "1", "assignee": {"type": "ONE", "entities": [{"entity": {"type": "USER", "code": "boss"}}]}},
+					"完了": {"name": "完了", "index": "2"}
+				},
+				"actions": [
+					{"name": "申請する", "from": "未処理", "to": "承認待ち"},
+					{"name": "承認する", "from": "承認待ち", "to": "完了", "filterCond": "金額 <= 10000"}
+				]
+			},
+			"records": [{"件名": {"value": "出張"}, "金額": {"value": "5000"}}]
+		}
+	]
+}`
+
+// newServer はフィクスチャを読み込んだサーバーとクライアントを作成する
+func newServer(t *testing.T) (*kintonetest.Server, *goten.Client) {
+	t.Helper()
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	t.Cleanup(server.Close)
+	server.User = types.Entity{Code: "sato", Name: "佐藤"}
+	server.Now = func() time.Time { return time.Date(2024, 1, 15, 9, 30, 45, 0, time.UTC) }
+	if err := server.LoadFixture([]byte(fixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	return server, server.Client()
+}
+
+// ids はレコードの$idを並べて返す
+func ids(records []record.Dynamic) []string {
+	list := make([]string, len(records))
+	for i, rec := range records {
+		list[i] = rec.ID()
+	}
+	return list
+}
+
+func TestRecordCRUD(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	added, err := client.Record.AddRecord(ctx, record.AddRecordParams{
+		App:    "1",
+		Record: map[string]types.FieldValue{"件名": {Value: "D"}, "明細": {Value: []map[string]any{{"value": map[string]any{"品名": map[string]any{"value": "ぶどう"}}}}}},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if added.ID != "4" || added.Revision != "1" {
+		t.Errorf("期待される追加結果: 4 / 1, 実際: %s / %s", added.ID, added.Revision)
+	}
+
+	rec, err := record.GetRecord[record.Dynamic](ctx, client.Record, record.GetRecordParams{App: "1", ID: "4"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	number, _ := rec.String("レコード番号")
+	creator, _ := rec.Users("作成者")
+	created, _ := rec.Time("作成日時")
+	rows, _ := rec.Subtable("明細")
+	if number != "4" || len(creator) != 1 || creator[0].Code != "sato" || !created.Equal(time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("システムのフィールドが正しくない: %s %v %v", number, creator, created)
+	}
+	if len(rows) != 1 || rows[0].ID == "" {
+		t.Fatalf("テーブルの行にIDが割り当てられていない: %+v", rows)
+	}
+
+	// 行のIDを指定すると、指定していないフィールドの値を引き継ぐ
+	revision := "1"
+	updated, err := client.Record.UpdateRecord(ctx, record.UpdateRecordParams{
+		App:      "1",
+		ID:       "4",
+		Revision: &revision,
+		Record: map[string]types.FieldValue{"明細": {Value: []map[string]any{
+			{"id": rows[0].ID, "value": map[string]any{"数量": map[string]any{"value": "2"}}},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if updated.Revision != "2" {
+		t.Errorf("期待されるリビジョン: 2, 実際: %s", updated.Revision)
+	}
+	rec, _ = server.Record("1", "4")
+	rows, _ = rec.Subtable("明細")
+	name, _ := rows[0].Record.String("品名")
+	quantity, _ := rows[0].Record.String("数量")
+	if name != "ぶどう" || quantity != "2" {
+		t.Errorf("期待される行: ぶどう / 2, 実際: %s / %s", name, quantity)
+	}
+
+	// 古いリビジョンの更新は失敗する
+	_, err = client.Record.UpdateRecord(ctx, record.UpdateRecordParams{App: "1", ID: "4", Revision: &revision, Record: map[string]types.FieldValue{"件名": {Value: "E"}}})
+	if !kintoneError.HasCode(err, kintoneError.CodeRevisionConflict) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeRevisionConflict, err)
+	}
+
+	// 重複禁止のフィールドで更新する
+	if _, err := client.Record.UpdateRecord(ctx, record.UpdateRecordParams{
+		App:       "1",
+		UpdateKey: &types.UpdateKey{Field: "番号", Value: "002"},
+		Record:    map[string]types.FieldValue{"金額": {Value: "3000"}},
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	rec, _ = server.Record("1", "2")
+	if amount, _ := rec.String("金額"); amount != "3000" {
+		t.Errorf("期待される金額: 3000, 実際: %s", amount)
+	}
+
+	if err := client.Record.DeleteRecords(ctx, record.DeleteRecordsParams{App: "1", IDs: []string{"4"}}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	_, err = record.GetRecord[record.Dynamic](ctx, client.Record, record.GetRecordParams{App: "1", ID: "4"})
+	if !kintoneError.HasCode(err, kintoneError.CodeRecordNotFound) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeRecordNotFound, err)
+	}
+	_, err = record.GetRecord[record.Dynamic](ctx, client.Record, record.GetRecordParams{App: "99", ID: "1"})
+	if !kintoneError.HasCode(err, kintoneError.CodeAppNotFound) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeAppNotFound, err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{``, []string{"3", "2", "1"}},
+		{`金額 >= 100 order by 金額 desc`, []string{"2", "1"}},
+		{`タグ in ("B")`, []string{"2"}},
+		{`タグ not in ("B")`, []string{"3", "1"}},
+		{`品名 like "りん"`, []string{"1"}},
+		{`期日 is empty`, []string{"3"}},
+		{`期日 < "2024-02-01"`, []string{"1"}},
+		{`期日 = THIS_MONTH()`, []string{"1"}},
+		{`担当者 in (LOGINUSER())`, []string{"1"}},
+		{`作成日時 = TODAY() and 件名 != "B"`, []string{"3", "1"}},
+		{`区分 = "通常" and (件名 = "A" or 件名 = "C") order by $id asc limit 1 offset 1`, []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := record.GetRecords[record.Dynamic](ctx, client.Record, record.GetRecordsParams{App: "1", Query: tt.query})
+			if err != nil {
+				t.Fatalf("エラーが発生: %v", err)
+			}
+			if got := ids(result.Records); !slices.Equal(got, tt.want) {
+				t.Errorf("期待される$id: %v, 実際: %v", tt.want, got)
+			}
+		})
+	}
+
+	result, err := record.GetRecords[record.Dynamic](ctx, client.Record, record.GetRecordsParams{
+		App: "1", Fields: []string{"$id", "件名"}, Query: "limit 1", TotalCount: true,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.TotalCount == nil || *result.TotalCount != "3" {
+		t.Errorf("期待される件数: 3, 実際: %v", result.TotalCount)
+	}
+	if codes := result.Records[0].Codes(); !slices.Equal(codes, []string{"$id", "件名"}) {
+		t.Errorf("期待されるフィールド: [$id 件名], 実際: %v", codes)
+	}
+
+	_, err = record.GetRecords[record.Dynamic](ctx, client.Record, record.GetRecordsParams{App: "1", Query: `備考 = "x"`})
+	if err == nil || !strings.Contains(err.Error(), "備考") {
+		t.Errorf("期待されるエラー: 備考, 実際: %v", err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	_, err := client.Record.AddRecords(ctx, record.AddRecordsParams{
+		App: "1",
+		Records: []map[string]types.FieldValue{
+			{"件名": {Value: "D"}, "番号": {Value: "004"}},
+			{"番号": {Value: "001"}, "区分": {Value: "普通"}},
+		},
+	})
+	want := map[string][]string{
+		"records[1].件名.value": {"必須です。"},
+		"records[1].番号.value": {"値がほかのレコードと重複しています。"},
+		"records[1].区分.value": {"「普通」は選択肢にありません。"},
+	}
+	got := validate.Messages(err)
+	if len(got) != len(want) {
+		t.Fatalf("期待されるエラー: %v, 実際: %v", want, err)
+	}
+	for key, messages := range want {
+		if !slices.Equal(got[key], messages) {
+			t.Errorf("期待されるエラー（%s）: %v, 実際: %v", key, messages, got[key])
+		}
+	}
+	// 失敗した場合はどのレコードも追加しない
+	if n := len(server.Records("1")); n != 3 {
+		t.Errorf("期待されるレコード数: 3, 実際: %d", n)
+	}
+
+	// 自身の値は重複として扱わない
+	if _, err := client.Record.UpdateRecord(ctx, record.UpdateRecordParams{App: "1", ID: "1", Record: map[string]types.FieldValue{"番号": {Value: "001"}}}); err != nil {
+		t.Errorf("エラーが発生: %v", err)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	cursor, err := client.Record.CreateCursor(ctx, record.CreateCursorParams{App: "1", Query: "order by $id asc", Size: 2})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if cursor.TotalCount != "3" {
+		t.Errorf("期待される件数: 3, 実際: %s", cursor.TotalCount)
+	}
+	var got []string
+	for {
+		page, err := record.GetRecordsByCursor[record.Dynamic](ctx, client.Record, record.GetRecordsByCursorParams{ID: cursor.ID})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		got = append(got, ids(page.Records)...)
+		if !page.Next {
+			break
+		}
+	}
+	if !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Errorf("期待される$id: [1 2 3], 実際: %v", got)
+	}
+	// 全て取得したカーソルは削除済み
+	if err := client.Record.DeleteCursor(ctx, record.DeleteCursorParams{ID: cursor.ID}); err == nil {
+		t.Error("削除済みのカーソルの削除はエラーになるはず")
+	}
+
+	for i := range 10 {
+		if _, err := client.Record.CreateCursor(ctx, record.CreateCursorParams{App: "1"}); err != nil {
+			t.Fatalf("カーソル%dの作成でエラーが発生: %v", i, err)
+		}
+	}
+	_, err = client.Record.CreateCursor(ctx, record.CreateCursorParams{App: "1"})
+	if !kintoneError.HasCode(err, kintoneError.CodeCursorLimit) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeCursorLimit, err)
+	}
+
+	// 有効期限が過ぎたカーソルは削除される
+	server.Now = func() time.Time { return time.Date(2024, 1, 15, 9, 41, 0, 0, time.UTC) }
+	if _, err := client.Record.CreateCursor(ctx, record.CreateCursorParams{App: "1"}); err != nil {
+		t.Errorf("エラーが発生: %v", err)
+	}
+}
+
+func TestBulkRequestRollback(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	requests := bulk.NewBuilder().
+		AddRecord("1", map[string]any{"件名": map[string]any{"value": "D"}}).
+		UpdateRecord("1", "1", map[string]any{"件名": map[string]any{"value": "A2"}}, "").
+		UpdateRecord("1", "2", map[string]any{"件名": map[string]any{"value": "B2"}}, "99").
+		Build()
+	_, err := client.Bulk.Send(ctx, bulk.SendParams{Requests: requests})
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != kintoneError.CodeRevisionConflict || apiErr.BulkRequestIndex == nil || *apiErr.BulkRequestIndex != 2 {
+		t.Fatalf("期待されるエラー: 2番目の%s, 実際: %v", kintoneError.CodeRevisionConflict, err)
+	}
+	// 成功したリクエストの変更も取り消す
+	records := server.Records("1")
+	title, _ := records[0].String("件名")
+	if len(records) != 3 || title != "A" {
+		t.Errorf("変更が取り消されていない: %d件, %s", len(records), title)
+	}
+
+	requests = bulk.NewBuilder().
+		AddRecord("1", map[string]any{"件名": map[string]any{"value": "D"}}).
+		DeleteRecords("1", []string{"3"}, nil).
+		Build()
+	result, err := client.Bulk.Send(ctx, bulk.SendParams{Requests: requests})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Errorf("期待される結果数: 2, 実際: %d", len(result.Results))
+	}
+	if got := ids(server.Records("1")); !slices.Equal(got, []string{"1", "2", "4"}) {
+		t.Errorf("期待される$id: [1 2 4], 実際: %v", got)
+	}
+}
+
+func TestFile(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	uploaded, err := client.File.Upload(ctx, file.UploadParams{FileName: "見積書.txt", Reader: strings.NewReader("見積")})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if _, err := client.Record.UpdateRecord(ctx, record.UpdateRecordParams{
+		App: "1", ID: "1",
+		Record: map[string]types.FieldValue{"添付": {Value: types.Files{{FileKey: uploaded.FileKey}}}},
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	rec, _ := server.Record("1", "1")
+	files, _ := rec.Files("添付")
+	if len(files) != 1 || files[0].Name != "見積書.txt" || files[0].Size != "6" || files[0].FileKey == uploaded.FileKey {
+		t.Fatalf("添付ファイルが正しくない: %+v", files)
+	}
+	body, err := client.File.Download(ctx, file.DownloadParams{FileKey: files[0].FileKey})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	if string(data) != "見積" {
+		t.Errorf("期待される内容: 見積, 実際: %s", data)
+	}
+
+	// 存在しないファイルキーは添付できない
+	_, err = client.Record.UpdateRecord(ctx, record.UpdateRecordParams{
+		App: "1", ID: "1",
+		Record: map[string]types.FieldValue{"添付": {Value: types.Files{{FileKey: "unknown"}}}},
+	})
+	if err == nil {
+		t.Error("存在しないファイルキーの添付はエラーになるはず")
+	}
+}
+
+func TestCommentsAndStatus(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	for _, text := range []string{"1件目", "2件目", "3件目"} {
+		if _, err := client.Record.AddRecordComment(ctx, record.AddRecordCommentParams{App: "2", Record: "1", Comment: record.CommentContent{Text: text}}); err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+	}
+	page, err := client.Record.GetRecordComments(ctx, record.GetRecordCommentsParams{App: "2", Record: "1", Limit: 2})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(page.Comments) != 2 || page.Comments[0].Text != "3件目" || !page.Older || page.Newer {
+		t.Errorf("コメントの取得結果が正しくない: %+v", page)
+	}
+	if err := client.Record.DeleteRecordComment(ctx, record.DeleteRecordCommentParams{App: "2", Record: "1", Comment: "2"}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if n := len(server.Comments("2", "1")); n != 2 {
+		t.Errorf("期待されるコメント数: 2, 実際: %d", n)
+	}
+
+	result, err := client.Record.UpdateRecordStatus(ctx, record.UpdateRecordStatusParams{App: "2", ID: "1", Action: "申請する"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Revision != "3" {
+		t.Errorf("期待されるリビジョン: 3, 実際: %s", result.Revision)
+	}
+	rec, _ := server.Record("2", "1")
+	status, _ := rec.String("ステータス")
+	assignees, _ := rec.Users("作業者")
+	if status != "承認待ち" || len(assignees) != 1 || assignees[0].Code != "boss" {
+		t.Errorf("期待されるステータス: 承認待ち / boss, 実際: %s / %v", status, assignees)
+	}
+
+	// 実行条件を満たさないアクションは実行できない
+	if _, err := client.Record.UpdateRecord(ctx, record.UpdateRecordParams{App: "2", ID: "1", Record: map[string]types.FieldValue{"金額": {Value: "20000"}}}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	_, err = client.Record.UpdateRecordStatus(ctx, record.UpdateRecordStatusParams{App: "2", ID: "1", Action: "承認する"})
+	if !kintoneError.HasCode(err, kintoneError.CodeValidation) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeValidation, err)
+	}
+}
+
+func TestAppSettings(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+
+	fields, err := client.App.GetFormFields(ctx, app.GetFormFieldsParams{App: "2"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	for _, code := range []string{"件名", "レコード番号", "更新日時", "ステータス", "作業者"} {
+		if _, ok := fields.Properties[code]; !ok {
+			t.Errorf("フィールドがない: %s", code)
+		}
+	}
+
+	apps, err := client.App.GetApps(ctx, app.GetAppsParams{Name: "申請"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(apps.Apps) != 1 || apps.Apps[0].AppID != "2" {
+		t.Errorf("期待されるアプリ: 2, 実際: %+v", apps.Apps)
+	}
+
+	process, err := client.App.GetProcessManagement(ctx, app.GetProcessManagementParams{App: "2"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if !process.Enable || len(process.Actions) != 2 {
+		t.Errorf("プロセス管理の設定が正しくない: %+v", process)
+	}
+}
+
+func TestRecordHelpers(t *testing.T) {
+	server, client := newServer(t)
+	ctx := context.Background()
+
+	result, err := client.Record.UpsertRecords(ctx, record.UpsertRecordsParams{
+		App:      "1",
+		KeyField: "番号",
+		Records: []map[string]types.FieldValue{
+			{"番号": {Value: "002"}, "件名": {Value: "B2"}},
+			{"番号": {Value: "004"}, "件名": {Value: "D"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Records[0].Added || result.Records[0].ID != "2" || !result.Records[1].Added {
+		t.Errorf("Upsertの結果が正しくない: %+v", result.Records)
+	}
+	rec, _ := server.Record("1", "2")
+	if title, _ := rec.String("件名"); title != "B2" {
+		t.Errorf("期待される件名: B2, 実際: %s", title)
+	}
+
+	all, err := record.GetAllRecords[record.Dynamic](ctx, client.Record, record.GetAllRecordsParams{App: "1", Condition: `区分 in ("通常")`})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if got := ids(all); !slices.Equal(got, []string{"1", "3"}) {
+		t.Errorf("期待される$id: [1 3], 実際: %v", got)
+	}
+}
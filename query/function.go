@@ -0,0 +1,126 @@
+package query
+
+import "strconv"
+
+// Function はクエリで使用できる関数
+// 条件式の値として指定すると、ダブルクォートで囲まずにそのまま出力される
+type Function struct {
+	expr string
+}
+
+// String は関数を文字列で返す
+func (f Function) String() string {
+	return f.expr
+}
+
+// call は引数付きの関数を作成する
+func call(name string, args ...string) Function {
+	expr := name + "("
+	for i, arg := range args {
+		if i > 0 {
+			expr += ", "
+		}
+		expr += arg
+	}
+	return Function{expr: expr + ")"}
+}
+
+// LoginUser はLOGINUSER()（ログインユーザー）
+func LoginUser() Function { return call("LOGINUSER") }
+
+// PrimaryOrganization はPRIMARY_ORGANIZATION()（ログインユーザーの優先する組織）
+func PrimaryOrganization() Function { return call("PRIMARY_ORGANIZATION") }
+
+// Now はNOW()（現在日時）
+func Now() Function { return call("NOW") }
+
+// Today はTODAY()（今日）
+func Today() Function { return call("TODAY") }
+
+// Yesterday はYESTERDAY()（昨日）
+func Yesterday() Function { return call("YESTERDAY") }
+
+// Tomorrow はTOMORROW()（明日）
+func Tomorrow() Function { return call("TOMORROW") }
+
+// Unit はFROM_TODAYの単位
+type Unit string
+
+const (
+	Days   Unit = "DAYS"
+	Weeks  Unit = "WEEKS"
+	Months Unit = "MONTHS"
+	Years  Unit = "YEARS"
+)
+
+// FromToday はFROM_TODAY(n, unit)（今日からn単位後。負の値で前）
+func FromToday(n int, unit Unit) Function {
+	return call("FROM_TODAY", strconv.Itoa(n), string(unit))
+}
+
+// Weekday はTHIS_WEEKなどで指定する曜日
+type Weekday string
+
+const (
+	Sunday    Weekday = "SUNDAY"
+	Monday    Weekday = "MONDAY"
+	Tuesday   Weekday = "TUESDAY"
+	Wednesday Weekday = "WEDNESDAY"
+	Thursday  Weekday = "THURSDAY"
+	Friday    Weekday = "FRIDAY"
+	Saturday  Weekday = "SATURDAY"
+)
+
+// ThisWeek はTHIS_WEEK()（今週）。曜日を指定すると今週のその曜日
+func ThisWeek(day ...Weekday) Function { return weekFunction("THIS_WEEK", day) }
+
+// LastWeek はLAST_WEEK()（先週）。曜日を指定すると先週のその曜日
+func LastWeek(day ...Weekday) Function { return weekFunction("LAST_WEEK", day) }
+
+// NextWeek はNEXT_WEEK()（来週）。曜日を指定すると来週のその曜日
+func NextWeek(day ...Weekday) Function { return weekFunction("NEXT_WEEK", day) }
+
+// weekFunction は曜日を引数に取る関数を作成する
+func weekFunction(name string, day []Weekday) Function {
+	if len(day) == 0 {
+		return call(name)
+	}
+	return call(name, string(day[0]))
+}
+
+// MonthDay はTHIS_MONTHなどで指定する日
+type MonthDay string
+
+// LastDay は月末日
+const LastDay MonthDay = "LAST"
+
+// Day は日（1〜31）を指定する
+func Day(n int) MonthDay {
+	return MonthDay(strconv.Itoa(n))
+}
+
+// ThisMonth はTHIS_MONTH()（今月）。日を指定すると今月のその日
+func ThisMonth(day ...MonthDay) Function { return monthFunction("THIS_MONTH", day) }
+
+// LastMonth はLAST_MONTH()（先月）。日を指定すると先月のその日
+func LastMonth(day ...MonthDay) Function { return monthFunction("LAST_MONTH", day) }
+
+// NextMonth はNEXT_MONTH()（来月）。日を指定すると来月のその日
+func NextMonth(day ...MonthDay) Function { return monthFunction("NEXT_MONTH", day) }
+
+// monthFunction は日を引数に取る関数を作成する
+func monthFunction(name string, day []MonthDay) Function {
+	if len(day) == 0 {
+		return call(name)
+	}
+	return call(name, string(day[0]))
+}
+
+// ThisYear はTHIS_YEAR()（今年）
+func ThisYear() Function { return call("THIS_YEAR") }
+
+// LastYear はLAST_YEAR()（昨年）
+func LastYear() Function { return call("LAST_YEAR") }
+
+// NextYear はNEXT_YEAR()（来年）
+func NextYear() Function { return call("NEXT_YEAR") }
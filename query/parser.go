@@ -0,0 +1,584 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError はクエリの構文エラー
+type SyntaxError struct {
+	Pos     int    // エラー位置（先頭からの文字数、0始まり）
+	Message string // エラーメッセージ
+}
+
+// Error はerrorインターフェースを実装
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("クエリの構文エラー (位置 %d): %s", e.Pos, e.Message)
+}
+
+// Query は構文解析したクエリ
+type Query struct {
+	Condition Expr          // 条件式（省略時はnil）
+	OrderBy   []OrderByItem // 並び順
+	Limit     *int
+	Offset    *int
+}
+
+// String はクエリを文字列で返す
+func (q *Query) String() string {
+	var parts []string
+	if q.Condition != nil {
+		parts = append(parts, q.Condition.String())
+	}
+	if len(q.OrderBy) > 0 {
+		keys := make([]string, len(q.OrderBy))
+		for i, item := range q.OrderBy {
+			keys[i] = item.Field + " " + string(item.Order)
+		}
+		parts = append(parts, "order by "+strings.Join(keys, ", "))
+	}
+	if q.Limit != nil {
+		parts = append(parts, "limit "+strconv.Itoa(*q.Limit))
+	}
+	if q.Offset != nil {
+		parts = append(parts, "offset "+strconv.Itoa(*q.Offset))
+	}
+	return strings.Join(parts, " ")
+}
+
+// OrderByItem は並び順の要素
+type OrderByItem struct {
+	Field string
+	Order Order
+	Pos   int
+}
+
+// Expr は条件式のノード（*LogicalExpr または *ComparisonExpr）
+type Expr interface {
+	Position() int
+	String() string
+}
+
+// LogicalExpr はand / orで結合した条件式
+type LogicalExpr struct {
+	Operator string // and または or
+	Left     Expr
+	Right    Expr
+	Pos      int
+}
+
+// Position は条件式の位置を返す
+func (e *LogicalExpr) Position() int { return e.Pos }
+
+// String は条件式を文字列で返す
+func (e *LogicalExpr) String() string {
+	return e.operand(e.Left) + " " + e.Operator + " " + e.operand(e.Right)
+}
+
+// operand は異なる演算子の子ノードを括弧で囲む
+func (e *LogicalExpr) operand(child Expr) string {
+	if l, ok := child.(*LogicalExpr); ok && l.Operator != e.Operator {
+		return "(" + l.String() + ")"
+	}
+	return child.String()
+}
+
+// ComparisonExpr はフィールドと値を比較する条件式
+type ComparisonExpr struct {
+	Field    string
+	Operator string  // =, !=, >, <, >=, <=, like, not like, in, not in, is empty, is not empty
+	Values   []Value // in / not in の場合は複数、is empty / is not empty の場合は空
+	Pos      int
+}
+
+// Position は条件式の位置を返す
+func (e *ComparisonExpr) Position() int { return e.Pos }
+
+// String は条件式を文字列で返す
+func (e *ComparisonExpr) String() string {
+	switch e.Operator {
+	case "is empty", "is not empty":
+		return e.Field + " " + e.Operator
+	case "in", "not in":
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = v.String()
+		}
+		return e.Field + " " + e.Operator + " (" + strings.Join(values, ", ") + ")"
+	}
+	return e.Field + " " + e.Operator + " " + e.Values[0].String()
+}
+
+// ValueKind は値の種類
+type ValueKind int
+
+const (
+	StringValue   ValueKind = iota // "文字列"
+	NumberValue                    // 数値
+	FunctionValue                  // TODAY() などの関数
+)
+
+// Value は条件式の値
+type Value struct {
+	Kind ValueKind
+	Text string   // 文字列（エスケープ解除済み）、数値、関数名
+	Args []string // 関数の引数
+	Pos  int
+}
+
+// String は値を文字列で返す
+func (v Value) String() string {
+	switch v.Kind {
+	case StringValue:
+		return Quote(v.Text)
+	case FunctionValue:
+		return call(v.Text, v.Args...).String()
+	}
+	return v.Text
+}
+
+// functions はクエリで使用できる関数
+var functions = map[string]bool{
+	"LOGINUSER":            true,
+	"PRIMARY_ORGANIZATION": true,
+	"NOW":                  true,
+	"TODAY":                true,
+	"YESTERDAY":            true,
+	"TOMORROW":             true,
+	"FROM_TODAY":           true,
+	"THIS_WEEK":            true,
+	"LAST_WEEK":            true,
+	"NEXT_WEEK":            true,
+	"THIS_MONTH":           true,
+	"LAST_MONTH":           true,
+	"NEXT_MONTH":           true,
+	"THIS_YEAR":            true,
+	"LAST_YEAR":            true,
+	"NEXT_YEAR":            true,
+}
+
+// --- 字句解析 ---
+
+// tokenKind はトークンの種類
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token は字句解析の結果
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// numberPattern は数値リテラル
+var numberPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// isDelimiter は識別子の区切り文字かどうかを返す
+func isDelimiter(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '　', '(', ')', ',', '"', '=', '!', '<', '>':
+		return true
+	}
+	return false
+}
+
+// tokenize はクエリ文字列をトークンに分割する
+func tokenize(s string) ([]token, error) {
+	runes := []rune(s)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '　':
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: i})
+			i++
+		case r == '=':
+			tokens = append(tokens, token{kind: tokOperator, text: "=", pos: i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{kind: tokOperator, text: string(r) + "=", pos: i})
+				i += 2
+				continue
+			}
+			if r == '!' {
+				return nil, &SyntaxError{Pos: i, Message: "「!」の後には「=」が必要です"}
+			}
+			tokens = append(tokens, token{kind: tokOperator, text: string(r), pos: i})
+			i++
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &SyntaxError{Pos: start, Message: "文字列が閉じられていません"}
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		default:
+			start := i
+			for i < len(runes) && !isDelimiter(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := tokIdent
+			if numberPattern.MatchString(text) {
+				kind = tokNumber
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: start})
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// --- 構文解析 ---
+
+// parser はクエリの構文解析器
+type parser struct {
+	tokens []token
+	index  int
+}
+
+// Parse はクエリ文字列を構文解析する
+// 構文エラーの場合は*SyntaxErrorを返す
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.parseQuery()
+}
+
+// peek は現在のトークンを返す
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+// next は現在のトークンを返して次に進む
+func (p *parser) next() token {
+	t := p.tokens[p.index]
+	if t.kind != tokEOF {
+		p.index++
+	}
+	return t
+}
+
+// isKeyword は現在のトークンが指定したキーワードかどうかを返す
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, keyword)
+}
+
+// expectKeyword は指定したキーワードを読み進める
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		return p.unexpected(fmt.Sprintf("「%s」", keyword))
+	}
+	p.next()
+	return nil
+}
+
+// unexpected は予期しないトークンのエラーを返す
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return &SyntaxError{Pos: t.pos, Message: fmt.Sprintf("%sが必要ですが、クエリが終了しました", expected)}
+	}
+	return &SyntaxError{Pos: t.pos, Message: fmt.Sprintf("%sが必要ですが、「%s」があります", expected, t.text)}
+}
+
+// parseQuery は クエリ全体を解析する
+func (p *parser) parseQuery() (*Query, error) {
+	q := &Query{}
+
+	if p.peek().kind != tokEOF && !p.isKeyword("order") && !p.isKeyword("limit") && !p.isKeyword("offset") {
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q.Condition = cond
+	}
+
+	if p.isKeyword("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		for {
+			if p.peek().kind != tokIdent {
+				return nil, p.unexpected("並び替えるフィールドコード")
+			}
+			t := p.next()
+			item := OrderByItem{Field: t.text, Order: Asc, Pos: t.pos}
+			if p.isKeyword("asc") {
+				p.next()
+			} else if p.isKeyword("desc") {
+				p.next()
+				item.Order = Desc
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.isKeyword("limit") {
+		p.next()
+		n, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		q.Limit = &n
+	}
+
+	if p.isKeyword("offset") {
+		p.next()
+		n, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		q.Offset = &n
+	}
+
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("「and」「or」「order by」「limit」「offset」のいずれか")
+	}
+	return q, nil
+}
+
+// parseInt は0以上の整数を解析する
+func (p *parser) parseInt() (int, error) {
+	t := p.peek()
+	if t.kind != tokNumber {
+		return 0, p.unexpected("0以上の整数")
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil || n < 0 {
+		return 0, p.unexpected("0以上の整数")
+	}
+	p.next()
+	return n, nil
+}
+
+// parseOr は or で結合した条件式を解析する
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		t := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Operator: "or", Left: left, Right: right, Pos: t.pos}
+	}
+	return left, nil
+}
+
+// parseAnd は and で結合した条件式を解析する
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		t := p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Operator: "and", Left: left, Right: right, Pos: t.pos}
+	}
+	return left, nil
+}
+
+// parsePrimary は括弧で囲んだ条件式または比較条件を解析する
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.unexpected("「)」")
+		}
+		p.next()
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison は比較条件を解析する
+func (p *parser) parseComparison() (Expr, error) {
+	field := p.peek()
+	if field.kind != tokIdent {
+		return nil, p.unexpected("フィールドコード")
+	}
+	p.next()
+	expr := &ComparisonExpr{Field: field.text, Pos: field.pos}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokOperator:
+		p.next()
+		expr.Operator = t.text
+	case p.isKeyword("like"):
+		p.next()
+		expr.Operator = "like"
+	case p.isKeyword("in"):
+		p.next()
+		expr.Operator = "in"
+	case p.isKeyword("not"):
+		p.next()
+		switch {
+		case p.isKeyword("like"):
+			p.next()
+			expr.Operator = "not like"
+		case p.isKeyword("in"):
+			p.next()
+			expr.Operator = "not in"
+		default:
+			return nil, p.unexpected("「like」または「in」")
+		}
+	case p.isKeyword("is"):
+		p.next()
+		expr.Operator = "is empty"
+		if p.isKeyword("not") {
+			p.next()
+			expr.Operator = "is not empty"
+		}
+		if err := p.expectKeyword("empty"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return nil, p.unexpected("演算子")
+	}
+
+	if expr.Operator == "in" || expr.Operator == "not in" {
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		expr.Values = values
+		return expr, nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	expr.Values = []Value{value}
+	return expr, nil
+}
+
+// parseValueList は「(値, 値, ...)」を解析する
+func (p *parser) parseValueList() ([]Value, error) {
+	if p.peek().kind != tokLParen {
+		return nil, p.unexpected("「(」")
+	}
+	p.next()
+
+	var values []Value
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.peek().kind == tokRParen {
+			p.next()
+			return values, nil
+		}
+		if p.peek().kind != tokComma {
+			return nil, p.unexpected("「,」または「)」")
+		}
+		p.next()
+	}
+}
+
+// parseValue は値（文字列、数値、関数）を解析する
+func (p *parser) parseValue() (Value, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return Value{Kind: StringValue, Text: t.text, Pos: t.pos}, nil
+	case tokNumber:
+		p.next()
+		return Value{Kind: NumberValue, Text: t.text, Pos: t.pos}, nil
+	case tokIdent:
+		name := strings.ToUpper(t.text)
+		if !functions[name] {
+			return Value{}, &SyntaxError{Pos: t.pos, Message: fmt.Sprintf("不明な関数です: %s", t.text)}
+		}
+		p.next()
+		if p.peek().kind != tokLParen {
+			return Value{}, p.unexpected("「(」")
+		}
+		p.next()
+
+		value := Value{Kind: FunctionValue, Text: name, Pos: t.pos}
+		for p.peek().kind != tokRParen {
+			arg := p.peek()
+			if arg.kind != tokIdent && arg.kind != tokNumber {
+				return Value{}, p.unexpected("関数の引数")
+			}
+			p.next()
+			value.Args = append(value.Args, strings.ToUpper(arg.text))
+			if p.peek().kind == tokComma {
+				p.next()
+			} else if p.peek().kind != tokRParen {
+				return Value{}, p.unexpected("「,」または「)」")
+			}
+		}
+		p.next()
+		return value, nil
+	}
+	return Value{}, p.unexpected("値")
+}
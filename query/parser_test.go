@@ -0,0 +1,149 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/query"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"空", "", ""},
+		{"単一条件", `名前 = "テスト"`, `名前 = "テスト"`},
+		{"エスケープ", `名前 = "a\"b\\c"`, `名前 = "a\"b\\c"`},
+		{"and/or", `a = "1" and b > 2 or c like "x"`, `(a = "1" and b > 2) or c like "x"`},
+		{"括弧", `a = "1" and (b = "2" or c = "3")`, `a = "1" and (b = "2" or c = "3")`},
+		{"in", `選択 not in ("A", "B")`, `選択 not in ("A", "B")`},
+		{"関数", `日付 >= FROM_TODAY(-7, days) and 作成者 in (LOGINUSER())`, `日付 >= FROM_TODAY(-7, DAYS) and 作成者 in (LOGINUSER())`},
+		{"is empty", `名前 is not empty and メモ is empty`, `名前 is not empty and メモ is empty`},
+		{"大文字のキーワード", `a = "1" AND b NOT LIKE "x" ORDER BY $id DESC LIMIT 10 OFFSET 20`, `a = "1" and b not like "x" order by $id desc limit 10 offset 20`},
+		{"order byのみ", `order by 日付 asc, $id desc`, `order by 日付 asc, $id desc`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("エラーが発生: %v", err)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("期待されるクエリ: %s, 実際: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseBuilderOutput(t *testing.T) {
+	b := query.Where(query.Or(
+		query.Field("a").Eq(`x"y`),
+		query.And(query.Field("b").In(1, 2), query.Field("c").Lte(query.ThisMonth(query.LastDay))),
+	)).OrderBy("$id", query.Desc).Limit(500)
+
+	q, err := query.Parse(b.String())
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if q.String() != b.String() {
+		t.Errorf("期待されるクエリ: %s, 実際: %s", b.String(), q.String())
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantPos int
+	}{
+		{"演算子の誤り", `名前 == "a"`, 4},
+		{"未知の演算子", `名前 equals "a"`, 3},
+		{"閉じられていない文字列", `名前 = "abc`, 5},
+		{"閉じられていない括弧", `(a = "1"`, 8},
+		{"不明な関数", `日付 = TODAYY()`, 5},
+		{"inの括弧なし", `a in "1"`, 5},
+		{"余分なトークン", `a = "1" b = "2"`, 8},
+		{"limitが数値でない", `limit x`, 6},
+		{"!単独", `a ! "1"`, 2},
+		{"order byで終了", `order by`, 8},
+		{"order byのカンマで終了", `order by x,`, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := query.Parse(tt.input)
+			var syntaxErr *query.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("SyntaxErrorが返るはずが: %v", err)
+			}
+			if syntaxErr.Pos != tt.wantPos {
+				t.Errorf("期待されるエラー位置: %d, 実際: %d (%s)", tt.wantPos, syntaxErr.Pos, syntaxErr.Message)
+			}
+		})
+	}
+}
+
+// testFields はテスト用のフィールド定義
+var testFields = &app.GetFormFieldsResult{
+	Properties: map[string]app.FieldProperty{
+		"レコード番号": {Type: "RECORD_NUMBER", Code: "レコード番号"},
+		"名前":     {Type: "SINGLE_LINE_TEXT", Code: "名前"},
+		"メモ":     {Type: "MULTI_LINE_TEXT", Code: "メモ"},
+		"数値":     {Type: "NUMBER", Code: "数値"},
+		"日付":     {Type: "DATE", Code: "日付"},
+		"担当者":    {Type: "USER_SELECT", Code: "担当者"},
+		"優先度": {Type: "DROP_DOWN", Code: "優先度", Options: map[string]app.Option{
+			"高": {Label: "高", Index: "0"},
+			"低": {Label: "低", Index: "1"},
+		}},
+		"明細": {Type: "SUBTABLE", Code: "明細", Fields: map[string]app.FieldProperty{
+			"品名": {Type: "SINGLE_LINE_TEXT", Code: "品名"},
+		}},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		`名前 = "a" and 数値 > 10 and 日付 >= TODAY()`,
+		`担当者 in (LOGINUSER(), "user1") and 優先度 in ("高")`,
+		`品名 in ("りんご") and メモ like "x"`,
+		`$id > 100 order by 日付 desc, $id asc limit 500`,
+		`レコード番号 in (1, 2, 3)`,
+	}
+	for _, s := range valid {
+		if err := query.Validate(s, testFields); err != nil {
+			t.Errorf("エラーが発生しないはずが発生: %s: %v", s, err)
+		}
+	}
+
+	invalid := []struct {
+		input   string
+		field   string
+		wantPos int
+	}{
+		{`存在しない = "a"`, "存在しない", 0},
+		{`数値 like "1"`, "数値", 0},
+		{`メモ = "a"`, "メモ", 0},
+		{`数値 = "abc"`, "数値", 5},
+		{`優先度 in ("中")`, "優先度", 8},
+		{`品名 = "りんご"`, "品名", 0},
+		{`名前 = TODAY()`, "名前", 5},
+		{`明細 = "a"`, "明細", 0},
+		{`order by メモ asc`, "メモ", 9},
+	}
+	for _, tt := range invalid {
+		err := query.Validate(tt.input, testFields)
+		var validationErrs query.ValidationErrors
+		if !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+			t.Errorf("検証エラーが1件返るはずが: %s: %v", tt.input, err)
+			continue
+		}
+		if validationErrs[0].Field != tt.field || validationErrs[0].Pos != tt.wantPos {
+			t.Errorf("期待されるエラー: %s (位置 %d), 実際: %v", tt.field, tt.wantPos, validationErrs[0])
+		}
+	}
+}
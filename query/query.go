@@ -0,0 +1,318 @@
+// Package query はkintoneのクエリ文字列を型安全に構築する機能を提供する
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order は並び順
+type Order string
+
+const (
+	Asc  Order = "asc"  // 昇順
+	Desc Order = "desc" // 降順
+)
+
+// Condition はクエリの条件式
+// Field(...).Eq(...) などで作成し、And / Or で組み合わせる
+type Condition struct {
+	expr     string      // 単一の条件式
+	raw      bool        // exprが任意の条件式かどうか
+	operator string      // and または or（複合条件の場合）
+	terms    []Condition // 複合条件の要素
+}
+
+// String は条件式を文字列で返す
+func (c Condition) String() string {
+	return c.build(false)
+}
+
+// IsZero は条件式が空かどうかを返す
+func (c Condition) IsZero() bool {
+	return c.expr == "" && len(c.terms) == 0
+}
+
+// build は条件式を構築する。nestedがtrueの場合、複合条件を括弧で囲む
+func (c Condition) build(nested bool) string {
+	if c.operator == "" {
+		// 任意の条件式はand/orを含む可能性があるため括弧で囲む
+		if nested && c.raw {
+			return "(" + c.expr + ")"
+		}
+		return c.expr
+	}
+
+	parts := make([]string, 0, len(c.terms))
+	for _, term := range c.terms {
+		parts = append(parts, term.build(true))
+	}
+	s := strings.Join(parts, " "+c.operator+" ")
+	if nested && len(parts) > 1 {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// And は条件式をandで結合する
+func (c Condition) And(others ...Condition) Condition {
+	return And(append([]Condition{c}, others...)...)
+}
+
+// Or は条件式をorで結合する
+func (c Condition) Or(others ...Condition) Condition {
+	return Or(append([]Condition{c}, others...)...)
+}
+
+// And は複数の条件式をandで結合する
+// 空の条件式は無視する
+func And(conditions ...Condition) Condition {
+	return combine("and", conditions)
+}
+
+// Or は複数の条件式をorで結合する
+// 空の条件式は無視する
+func Or(conditions ...Condition) Condition {
+	return combine("or", conditions)
+}
+
+// combine は条件式を指定した演算子で結合する
+func combine(operator string, conditions []Condition) Condition {
+	var terms []Condition
+	for _, c := range conditions {
+		if c.IsZero() {
+			continue
+		}
+		// 同じ演算子の複合条件は平坦化する
+		if c.operator == operator {
+			terms = append(terms, c.terms...)
+			continue
+		}
+		terms = append(terms, c)
+	}
+
+	switch len(terms) {
+	case 0:
+		return Condition{}
+	case 1:
+		return terms[0]
+	}
+	return Condition{operator: operator, terms: terms}
+}
+
+// FieldRef は条件式の左辺となるフィールド
+type FieldRef struct {
+	code string
+}
+
+// Field はフィールドコードを指定して条件式の左辺を作成する
+// $id、レコード番号、ステータスなどのフィールドコードも指定できる
+func Field(code string) FieldRef {
+	return FieldRef{code: code}
+}
+
+// compare は比較演算子の条件式を作成する
+func (f FieldRef) compare(operator string, value any) Condition {
+	return Condition{expr: f.code + " " + operator + " " + formatValue(value)}
+}
+
+// Eq は「=」の条件式を作成する
+func (f FieldRef) Eq(value any) Condition { return f.compare("=", value) }
+
+// NotEq は「!=」の条件式を作成する
+func (f FieldRef) NotEq(value any) Condition { return f.compare("!=", value) }
+
+// Gt は「>」の条件式を作成する
+func (f FieldRef) Gt(value any) Condition { return f.compare(">", value) }
+
+// Lt は「<」の条件式を作成する
+func (f FieldRef) Lt(value any) Condition { return f.compare("<", value) }
+
+// Gte は「>=」の条件式を作成する
+func (f FieldRef) Gte(value any) Condition { return f.compare(">=", value) }
+
+// Lte は「<=」の条件式を作成する
+func (f FieldRef) Lte(value any) Condition { return f.compare("<=", value) }
+
+// Like は「like」の条件式を作成する（部分一致）
+func (f FieldRef) Like(value string) Condition { return f.compare("like", value) }
+
+// NotLike は「not like」の条件式を作成する
+func (f FieldRef) NotLike(value string) Condition { return f.compare("not like", value) }
+
+// In は「in」の条件式を作成する
+// 値を指定しない場合は、どのレコードにも一致しない条件式になる
+func (f FieldRef) In(values ...any) Condition {
+	if len(values) == 0 {
+		return never
+	}
+	return f.list("in", values)
+}
+
+// NotIn は「not in」の条件式を作成する
+// 値を指定しない場合は、全てのレコードに一致する条件式になる
+func (f FieldRef) NotIn(values ...any) Condition {
+	if len(values) == 0 {
+		return always
+	}
+	return f.list("not in", values)
+}
+
+// 値のリストが空の場合の条件式（「in ()」はクエリの構文エラーになるため$idで代用する）
+var (
+	never  = Condition{expr: "$id < 1"} // どのレコードにも一致しない
+	always = Condition{expr: "$id > 0"} // 全てのレコードに一致する
+)
+
+// IsEmpty は「is empty」の条件式を作成する
+func (f FieldRef) IsEmpty() Condition {
+	return Condition{expr: f.code + " is empty"}
+}
+
+// IsNotEmpty は「is not empty」の条件式を作成する
+func (f FieldRef) IsNotEmpty() Condition {
+	return Condition{expr: f.code + " is not empty"}
+}
+
+// list は値のリストを取る条件式を作成する
+func (f FieldRef) list(operator string, values []any) Condition {
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = formatValue(v)
+	}
+	return Condition{expr: f.code + " " + operator + " (" + strings.Join(formatted, ", ") + ")"}
+}
+
+// Raw はクエリ文字列をそのまま条件式として扱う
+// 一覧のfilterCondなど、既存の条件式と組み合わせる場合に使用する
+func Raw(expr string) Condition {
+	return Condition{expr: strings.TrimSpace(expr), raw: true}
+}
+
+// Escape は文字列をクエリ用にエスケープする（前後のダブルクォートは付与しない）
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// Quote は文字列をエスケープしてダブルクォートで囲む
+func Quote(s string) string {
+	return `"` + Escape(s) + `"`
+}
+
+// Date は日付フィールドの条件式の値を返す（例: "2024-01-10"）
+// time.Timeをそのまま指定すると日時（RFC3339）の形式になり、日付フィールドでは使用できない
+func Date(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// formatValue は値をクエリ用の文字列に変換する
+// 関数はそのまま、数値はそのまま、それ以外は文字列としてダブルクォートで囲む
+// time.Timeは日時フィールド用にRFC3339の形式にする（日付フィールドにはDateを使用する）
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case Function:
+		return v.String()
+	case string:
+		return Quote(v)
+	case int:
+		return strconv.Itoa(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return Quote(v.Format(time.RFC3339))
+	case fmt.Stringer:
+		return Quote(v.String())
+	default:
+		return Quote(fmt.Sprint(v))
+	}
+}
+
+// sortKey はorder byの要素
+type sortKey struct {
+	field string
+	order Order
+}
+
+// Builder はクエリ文字列のビルダー
+type Builder struct {
+	condition Condition
+	orderBy   []sortKey
+	limit     *int
+	offset    *int
+}
+
+// New は条件式なしのBuilderを作成する
+func New() *Builder {
+	return &Builder{}
+}
+
+// Where は条件式を指定してBuilderを作成する
+func Where(condition Condition) *Builder {
+	return &Builder{condition: condition}
+}
+
+// Where は条件式をandで追加する
+func (b *Builder) Where(condition Condition) *Builder {
+	b.condition = And(b.condition, condition)
+	return b
+}
+
+// OrderBy は並び順を追加する
+func (b *Builder) OrderBy(field string, order Order) *Builder {
+	b.orderBy = append(b.orderBy, sortKey{field: field, order: order})
+	return b
+}
+
+// Limit は取得件数を指定する（最大500）
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = &n
+	return b
+}
+
+// Offset は取得開始位置を指定する（最大10,000）
+func (b *Builder) Offset(n int) *Builder {
+	b.offset = &n
+	return b
+}
+
+// ConditionString は条件式のみを返す
+// GetAllRecordsParams.Conditionに指定する
+func (b *Builder) ConditionString() string {
+	return b.condition.String()
+}
+
+// OrderByString は「order by」を除いた並び順を返す
+// GetAllRecordsParams.OrderByに指定する
+func (b *Builder) OrderByString() string {
+	keys := make([]string, len(b.orderBy))
+	for i, k := range b.orderBy {
+		keys[i] = k.field + " " + string(k.order)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// String はクエリ文字列を返す
+// GetRecordsParams.Query、CreateCursorParams.Queryに指定する
+func (b *Builder) String() string {
+	var parts []string
+	if s := b.ConditionString(); s != "" {
+		parts = append(parts, s)
+	}
+	if s := b.OrderByString(); s != "" {
+		parts = append(parts, "order by "+s)
+	}
+	if b.limit != nil {
+		parts = append(parts, "limit "+strconv.Itoa(*b.limit))
+	}
+	if b.offset != nil {
+		parts = append(parts, "offset "+strconv.Itoa(*b.offset))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,118 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/query"
+)
+
+func TestCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition query.Condition
+		want      string
+	}{
+		{"等しい", query.Field("名前").Eq("テスト"), `名前 = "テスト"`},
+		{"等しくない", query.Field("名前").NotEq("テスト"), `名前 != "テスト"`},
+		{"より大きい", query.Field("数値").Gt(10), `数値 > 10`},
+		{"より小さい", query.Field("数値").Lt(1.5), `数値 < 1.5`},
+		{"以上", query.Field("数値").Gte(int64(3)), `数値 >= 3`},
+		{"以下", query.Field("日付").Lte(query.Today()), `日付 <= TODAY()`},
+		{"部分一致", query.Field("名前").Like("テ"), `名前 like "テ"`},
+		{"部分一致しない", query.Field("名前").NotLike("テ"), `名前 not like "テ"`},
+		{"いずれか", query.Field("選択").In("A", "B"), `選択 in ("A", "B")`},
+		{"いずれでもない", query.Field("作成者").NotIn(query.LoginUser(), "user1"), `作成者 not in (LOGINUSER(), "user1")`},
+		{"いずれか（値なし）", query.Field("選択").In(), `$id < 1`},
+		{"いずれでもない（値なし）", query.Field("選択").NotIn(), `$id > 0`},
+		{"日付", query.Field("日付").Eq(query.Date(time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC))), `日付 = "2024-01-10"`},
+		{"日時", query.Field("日時").Gt(time.Date(2024, 1, 10, 9, 30, 0, 0, time.UTC)), `日時 > "2024-01-10T09:30:00Z"`},
+		{"空", query.Field("名前").IsEmpty(), `名前 is empty`},
+		{"空でない", query.Field("名前").IsNotEmpty(), `名前 is not empty`},
+		{"エスケープ", query.Field("名前").Eq(`a"b\c`), `名前 = "a\"b\\c"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.String(); got != tt.want {
+				t.Errorf("期待される条件式: %s, 実際: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		function query.Function
+		want     string
+	}{
+		{query.Now(), "NOW()"},
+		{query.Yesterday(), "YESTERDAY()"},
+		{query.Tomorrow(), "TOMORROW()"},
+		{query.PrimaryOrganization(), "PRIMARY_ORGANIZATION()"},
+		{query.FromToday(-3, query.Days), "FROM_TODAY(-3, DAYS)"},
+		{query.ThisWeek(), "THIS_WEEK()"},
+		{query.LastWeek(query.Monday), "LAST_WEEK(MONDAY)"},
+		{query.ThisMonth(), "THIS_MONTH()"},
+		{query.NextMonth(query.Day(15)), "NEXT_MONTH(15)"},
+		{query.LastMonth(query.LastDay), "LAST_MONTH(LAST)"},
+		{query.ThisYear(), "THIS_YEAR()"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.function.String(); got != tt.want {
+			t.Errorf("期待される関数: %s, 実際: %s", tt.want, got)
+		}
+	}
+}
+
+func TestAndOr(t *testing.T) {
+	a := query.Field("a").Eq("1")
+	b := query.Field("b").Eq("2")
+	c := query.Field("c").Eq("3")
+
+	tests := []struct {
+		name      string
+		condition query.Condition
+		want      string
+	}{
+		{"and", query.And(a, b), `a = "1" and b = "2"`},
+		{"or", a.Or(b), `a = "1" or b = "2"`},
+		{"andの中のor", query.And(a, query.Or(b, c)), `a = "1" and (b = "2" or c = "3")`},
+		{"orの中のand", query.Or(query.And(a, b), c), `(a = "1" and b = "2") or c = "3"`},
+		{"同じ演算子は平坦化", query.And(query.And(a, b), c), `a = "1" and b = "2" and c = "3"`},
+		{"空の条件は無視", query.And(query.Condition{}, a), `a = "1"`},
+		{"Rawとの組み合わせ", query.Raw(`x = "1" or y = "2"`).And(a), `(x = "1" or y = "2") and a = "1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.String(); got != tt.want {
+				t.Errorf("期待される条件式: %s, 実際: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	q := query.Where(query.Field("ステータス").In("未着手", "進行中")).
+		Where(query.Field("期限").Lt(query.FromToday(7, query.Days))).
+		OrderBy("期限", query.Asc).
+		OrderBy("$id", query.Desc).
+		Limit(100).
+		Offset(200)
+
+	want := `ステータス in ("未着手", "進行中") and 期限 < FROM_TODAY(7, DAYS) order by 期限 asc, $id desc limit 100 offset 200`
+	if got := q.String(); got != want {
+		t.Errorf("期待されるクエリ: %s, 実際: %s", want, got)
+	}
+	if got := q.ConditionString(); got != `ステータス in ("未着手", "進行中") and 期限 < FROM_TODAY(7, DAYS)` {
+		t.Errorf("期待されない条件式: %s", got)
+	}
+	if got := q.OrderByString(); got != "期限 asc, $id desc" {
+		t.Errorf("期待されない並び順: %s", got)
+	}
+	if got := query.New().Limit(1).String(); got != "limit 1" {
+		t.Errorf("期待されるクエリ: limit 1, 実際: %s", got)
+	}
+}
@@ -0,0 +1,251 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/app"
+)
+
+// ValidationError はフィールド定義に対するクエリの検証エラー
+type ValidationError struct {
+	Pos     int    // エラー位置（先頭からの文字数、0始まり）
+	Field   string // フィールドコード
+	Message string // エラーメッセージ
+}
+
+// Error はerrorインターフェースを実装
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("クエリの検証エラー (位置 %d, フィールド %s): %s", e.Pos, e.Field, e.Message)
+}
+
+// ValidationErrors は検証エラーの一覧
+type ValidationErrors []ValidationError
+
+// Error はerrorインターフェースを実装
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate はクエリ文字列を構文解析し、フィールド定義に対して検証する
+// 構文エラーの場合は*SyntaxError、検証エラーの場合はValidationErrorsを返す
+// 一覧のfilterCondのように条件式のみの文字列も検証できる
+func Validate(s string, fields *app.GetFormFieldsResult) error {
+	q, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	return q.Validate(fields)
+}
+
+// 演算子の分類
+var (
+	compareOperators = []string{"=", "!=", ">", "<", ">=", "<="}
+	equalOperators   = []string{"=", "!="}
+	inOperators      = []string{"in", "not in"}
+	likeOperators    = []string{"like", "not like"}
+	emptyOperators   = []string{"is empty", "is not empty"}
+)
+
+// operatorsByType はフィールドタイプごとに使用できる演算子
+var operatorsByType = map[string][]string{
+	"__ID__":              concat(compareOperators, inOperators),
+	"RECORD_NUMBER":       concat(compareOperators, inOperators),
+	"SINGLE_LINE_TEXT":    concat(equalOperators, inOperators, likeOperators, emptyOperators),
+	"LINK":                concat(equalOperators, inOperators, likeOperators, emptyOperators),
+	"MULTI_LINE_TEXT":     concat(likeOperators, emptyOperators),
+	"RICH_TEXT":           concat(likeOperators, emptyOperators),
+	"NUMBER":              concat(compareOperators, inOperators, emptyOperators),
+	"CALC":                concat(compareOperators, inOperators, emptyOperators),
+	"DATE":                concat(compareOperators, emptyOperators),
+	"TIME":                concat(compareOperators, emptyOperators),
+	"DATETIME":            concat(compareOperators, emptyOperators),
+	"CREATED_TIME":        compareOperators,
+	"UPDATED_TIME":        compareOperators,
+	"CHECK_BOX":           concat(inOperators, emptyOperators),
+	"MULTI_SELECT":        concat(inOperators, emptyOperators),
+	"DROP_DOWN":           concat(inOperators, emptyOperators),
+	"RADIO_BUTTON":        inOperators,
+	"USER_SELECT":         concat(inOperators, emptyOperators),
+	"ORGANIZATION_SELECT": concat(inOperators, emptyOperators),
+	"GROUP_SELECT":        concat(inOperators, emptyOperators),
+	"CREATOR":             inOperators,
+	"MODIFIER":            inOperators,
+	"STATUS_ASSIGNEE":     inOperators,
+	"STATUS":              concat(equalOperators, inOperators),
+	"CATEGORY":            inOperators,
+	"FILE":                concat(likeOperators, emptyOperators),
+}
+
+// sortableTypes はorder byで指定できるフィールドタイプ
+var sortableTypes = []string{
+	"__ID__", "RECORD_NUMBER", "CREATOR", "CREATED_TIME", "MODIFIER", "UPDATED_TIME",
+	"SINGLE_LINE_TEXT", "LINK", "NUMBER", "CALC", "RADIO_BUTTON", "DROP_DOWN",
+	"DATE", "TIME", "DATETIME", "STATUS",
+}
+
+// 値の種類による分類
+var (
+	numericTypes = []string{"__ID__", "RECORD_NUMBER", "NUMBER", "CALC"}
+	dateTypes    = []string{"DATE", "TIME", "DATETIME", "CREATED_TIME", "UPDATED_TIME"}
+	userTypes    = []string{"USER_SELECT", "CREATOR", "MODIFIER", "STATUS_ASSIGNEE"}
+	optionTypes  = []string{"CHECK_BOX", "MULTI_SELECT", "DROP_DOWN", "RADIO_BUTTON"}
+)
+
+// concat は演算子の一覧を結合する
+func concat(lists ...[]string) []string {
+	var result []string
+	for _, l := range lists {
+		result = append(result, l...)
+	}
+	return result
+}
+
+// fieldInfo は検証に使うフィールド情報
+type fieldInfo struct {
+	property   app.FieldProperty
+	inSubtable bool
+}
+
+// collectFields はフィールドコードからフィールド情報を引けるようにする
+// テーブル内のフィールドも含める
+func collectFields(fields *app.GetFormFieldsResult) map[string]fieldInfo {
+	result := map[string]fieldInfo{
+		"$id": {property: app.FieldProperty{Type: "__ID__", Code: "$id"}},
+	}
+	for code, prop := range fields.Properties {
+		result[code] = fieldInfo{property: prop}
+		for subCode, subProp := range prop.Fields {
+			result[subCode] = fieldInfo{property: subProp, inSubtable: true}
+		}
+	}
+	return result
+}
+
+// Validate はクエリをフィールド定義に対して検証する
+// フィールドコードの存在、フィールドタイプと演算子の組み合わせ、値の形式、選択肢の値を確認する
+// 問題がない場合はnil、ある場合はValidationErrorsを返す
+func (q *Query) Validate(fields *app.GetFormFieldsResult) error {
+	v := &validator{fields: collectFields(fields)}
+	if q.Condition != nil {
+		v.validateExpr(q.Condition)
+	}
+	for _, item := range q.OrderBy {
+		info, ok := v.lookup(item.Field, item.Pos)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(sortableTypes, info.property.Type) || info.inSubtable {
+			v.addError(item.Pos, item.Field, fmt.Sprintf("%sフィールドはorder byに指定できません", info.property.Type))
+		}
+	}
+	if q.Limit != nil && *q.Limit > 500 {
+		v.addError(0, "", fmt.Sprintf("limitの上限は500です: %d", *q.Limit))
+	}
+	if q.Offset != nil && *q.Offset > 10000 {
+		v.addError(0, "", fmt.Sprintf("offsetの上限は10000です: %d", *q.Offset))
+	}
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// validator はクエリの検証処理
+type validator struct {
+	fields map[string]fieldInfo
+	errors ValidationErrors
+}
+
+// addError は検証エラーを追加する
+func (v *validator) addError(pos int, field, message string) {
+	v.errors = append(v.errors, ValidationError{Pos: pos, Field: field, Message: message})
+}
+
+// lookup はフィールド情報を取得する。存在しない場合はエラーを追加する
+func (v *validator) lookup(code string, pos int) (fieldInfo, bool) {
+	info, ok := v.fields[code]
+	if !ok {
+		v.addError(pos, code, "フィールドが存在しません")
+	}
+	return info, ok
+}
+
+// validateExpr は条件式を検証する
+func (v *validator) validateExpr(expr Expr) {
+	switch e := expr.(type) {
+	case *LogicalExpr:
+		v.validateExpr(e.Left)
+		v.validateExpr(e.Right)
+	case *ComparisonExpr:
+		v.validateComparison(e)
+	}
+}
+
+// validateComparison は比較条件を検証する
+func (v *validator) validateComparison(e *ComparisonExpr) {
+	info, ok := v.lookup(e.Field, e.Pos)
+	if !ok {
+		return
+	}
+	fieldType := info.property.Type
+
+	operators, searchable := operatorsByType[fieldType]
+	if !searchable {
+		v.addError(e.Pos, e.Field, fmt.Sprintf("%sフィールドは検索できません", fieldType))
+		return
+	}
+	if !slices.Contains(operators, e.Operator) {
+		v.addError(e.Pos, e.Field, fmt.Sprintf("%sフィールドに演算子「%s」は使用できません", fieldType, e.Operator))
+		return
+	}
+	if info.inSubtable && slices.Contains(equalOperators, e.Operator) {
+		v.addError(e.Pos, e.Field, fmt.Sprintf("テーブル内のフィールドに演算子「%s」は使用できません（in / not inを使用してください）", e.Operator))
+		return
+	}
+
+	for _, value := range e.Values {
+		v.validateValue(e.Field, info.property, value)
+	}
+}
+
+// validateValue は値の形式と選択肢を検証する
+func (v *validator) validateValue(code string, prop app.FieldProperty, value Value) {
+	fieldType := prop.Type
+
+	if value.Kind == FunctionValue {
+		var allowed bool
+		switch value.Text {
+		case "LOGINUSER":
+			allowed = slices.Contains(userTypes, fieldType)
+		case "PRIMARY_ORGANIZATION":
+			allowed = fieldType == "ORGANIZATION_SELECT"
+		default:
+			allowed = slices.Contains(dateTypes, fieldType)
+		}
+		if !allowed {
+			v.addError(value.Pos, code, fmt.Sprintf("%sフィールドに関数%s()は使用できません", fieldType, value.Text))
+		}
+		return
+	}
+
+	if slices.Contains(numericTypes, fieldType) {
+		if _, err := strconv.ParseFloat(value.Text, 64); err != nil {
+			v.addError(value.Pos, code, fmt.Sprintf("数値ではありません: %s", value.String()))
+		}
+		return
+	}
+
+	if slices.Contains(optionTypes, fieldType) && value.Text != "" {
+		if _, ok := prop.Options[value.Text]; !ok {
+			v.addError(value.Pos, code, fmt.Sprintf("選択肢にない値です: %s", value.String()))
+		}
+	}
+}
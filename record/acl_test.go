@@ -0,0 +1,80 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+func TestEvaluateAllRecordAcl(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.Method != "GET" || r.URL.Path != "/k/v1/records/acl/evaluate.json" {
+			t.Errorf("期待されるリクエスト: GET /k/v1/records/acl/evaluate.json, 実際: %s %s", r.Method, r.URL.Path)
+		}
+		var reqBody struct {
+			App string   `json:"app"`
+			IDs []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		if len(reqBody.IDs) > 100 {
+			t.Errorf("1回のリクエストのID数が上限を超えている: %d", len(reqBody.IDs))
+		}
+
+		// 偶数のレコードは編集可能、フィールド「金額」は常に編集不可
+		var rights []map[string]any
+		for _, id := range reqBody.IDs {
+			n, _ := strconv.Atoi(id)
+			rights = append(rights, map[string]any{
+				"id":     id,
+				"record": map[string]any{"viewable": true, "editable": n%2 == 0, "deletable": false},
+				"fields": map[string]any{
+					"名前": map[string]any{"viewable": true, "editable": true},
+					"金額": map[string]any{"viewable": true, "editable": false},
+				},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"rights": rights})
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	ids := make([]types.RecordID, 250)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+	rights, err := client.EvaluateAllRecordAcl(context.Background(), record.EvaluateRecordAclParams{App: "1", IDs: ids})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if callCount != 3 {
+		t.Errorf("期待される呼び出し回数: 3, 実際: %d", callCount)
+	}
+	if len(rights) != 250 {
+		t.Fatalf("期待される件数: 250, 実際: %d", len(rights))
+	}
+
+	if !rights["2"].Record.Editable || rights["3"].Record.Editable {
+		t.Errorf("レコードの編集権限が不正: 2=%v, 3=%v", rights["2"].Record, rights["3"].Record)
+	}
+	if !rights["2"].CanEditField("名前") || rights["2"].CanEditField("金額") {
+		t.Error("フィールドの編集権限が不正")
+	}
+	if rights["3"].CanEditField("名前") {
+		t.Error("編集できないレコードのフィールドが編集可能になっている")
+	}
+	if !rights["2"].CanEditField("未定義") {
+		t.Error("アクセス権が返されていないフィールドはレコードの権限に従うはずが編集不可")
+	}
+}
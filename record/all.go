@@ -0,0 +1,256 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/goqoo-on-kintone/goten/bulk"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+)
+
+// recordsPerRequest は1リクエストで追加・更新・削除できるレコード数の上限
+const recordsPerRequest = 100
+
+// recordsPerBulkRequest は1回のバルクリクエストで処理できるレコード数の上限
+const recordsPerBulkRequest = recordsPerRequest * bulk.MaxRequests
+
+// bulkRequestResponse はバルクリクエストAPIのレスポンス
+type bulkRequestResponse struct {
+	Results []json.RawMessage `json:"results"`
+}
+
+// sendBulkRequest はバルクリクエストを実行し、各リクエストの結果を返す
+func (c *Client) sendBulkRequest(ctx context.Context, requests []bulk.Request) ([]json.RawMessage, error) {
+	reqBody := map[string]any{
+		"requests": requests,
+	}
+
+	body, err := c.httpClient.Post(ctx, "bulkRequest", reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response bulkRequestResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
+	}
+	if len(response.Results) != len(requests) {
+		return nil, fmt.Errorf("バルクリクエストの結果数が不正です: 期待=%d, 実際=%d", len(requests), len(response.Results))
+	}
+
+	return response.Results, nil
+}
+
+// sendInBulk はリクエストを最大20個ずつまとめたバルクリクエストで順に実行する
+// 成功したバルクリクエストの各リクエストの結果は、リクエストのインデックスとともにhandleResultに渡す
+// 戻り値はサーバーで処理済みのリクエスト数。バルクリクエストが失敗した場合は、それより前のバルクリクエストのリクエスト数とエラーを返す
+// バルクリクエストの成功後に結果の解析（handleResult）が失敗した場合は、サーバーでは処理済みのため、そのバルクリクエストの分も処理済みに数える
+func (c *Client) sendInBulk(ctx context.Context, requests []bulk.Request, handleResult func(i int, raw json.RawMessage) error) (int, error) {
+	for start := 0; start < len(requests); start += bulk.MaxRequests {
+		end := min(start+bulk.MaxRequests, len(requests))
+		results, err := c.sendBulkRequest(ctx, requests[start:end])
+		if err != nil {
+			return start, err
+		}
+		for i, raw := range results {
+			if err := handleResult(start+i, raw); err != nil {
+				return end, err
+			}
+		}
+	}
+	return len(requests), nil
+}
+
+// processInBulk はn件のレコードを100件ずつのリクエストに分割し、sendInBulkで実行する
+// 戻り値はサーバーで処理済みのレコード数
+func (c *Client) processInBulk(
+	ctx context.Context,
+	n int,
+	buildRequest func(start, end int) bulk.Request,
+	handleResult func(raw json.RawMessage) error,
+) (int, error) {
+	var requests []bulk.Request
+	for start := 0; start < n; start += recordsPerRequest {
+		requests = append(requests, buildRequest(start, min(start+recordsPerRequest, n)))
+	}
+	done, err := c.sendInBulk(ctx, requests, func(_ int, raw json.RawMessage) error {
+		return handleResult(raw)
+	})
+	return min(done*recordsPerRequest, n), err
+}
+
+// failedRequestIndex はバルクリクエストのエラーから、失敗したリクエストの全体でのインデックスを返す（特定できない場合は-1）
+// doneは失敗したバルクリクエストより前のリクエスト数（sendInBulkの戻り値）
+func failedRequestIndex(done int, err error) int {
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) || apiErr.BulkRequestIndex == nil {
+		return -1
+	}
+	return done + *apiErr.BulkRequestIndex
+}
+
+// recordIndexPattern はエラー詳細のキーからレコードのインデックスを取り出す
+var recordIndexPattern = regexp.MustCompile(`^records\[(\d+)\]`)
+
+// newAllRecordsError はKintoneAllRecordsErrorを作成する
+// エラーが発生したレコードのインデックスはバルクリクエストのエラー情報から算出する
+func newAllRecordsError(processed any, unprocessed []any, numOfProcessed, numOfAll int, err error) *kintoneError.KintoneAllRecordsError {
+	return &kintoneError.KintoneAllRecordsError{
+		ProcessedRecords:      processed,
+		UnprocessedRecords:    unprocessed,
+		Err:                   err,
+		ErrorIndex:            errorRecordIndex(numOfProcessed, err),
+		NumOfProcessedRecords: numOfProcessed,
+		NumOfAllRecords:       numOfAll,
+	}
+}
+
+// errorRecordIndex はエラーが発生したレコードの全体でのインデックスを返す（特定できない場合は-1）
+func errorRecordIndex(numOfProcessed int, err error) int {
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) || apiErr.BulkRequestIndex == nil {
+		return -1
+	}
+	index := firstErrorRecord(apiErr)
+	if index < 0 {
+		return -1
+	}
+	return numOfProcessed + *apiErr.BulkRequestIndex*recordsPerRequest + index
+}
+
+// firstErrorRecord はエラー詳細のキー（records[i]...）から、リクエスト内で最初にエラーが発生したレコードのインデックスを返す
+// 複数のレコードでエラーが発生した場合も結果が一定になるように最小のインデックスを返す（特定できない場合は-1）
+func firstErrorRecord(apiErr *kintoneError.KintoneRestAPIError) int {
+	first := -1
+	for key := range apiErr.Errors {
+		m := recordIndexPattern.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		index, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		if first < 0 || index < first {
+			first = index
+		}
+	}
+	return first
+}
+
+// toAnySlice はスライスを[]anyに変換する
+func toAnySlice[T any](s []T) []any {
+	result := make([]any, len(s))
+	for i, v := range s {
+		result[i] = v
+	}
+	return result
+}
+
+// AddAllRecords は件数の上限なくレコードを追加する
+// バルクリクエスト単位の処理と失敗時のエラーはパッケージのドキュメントを参照
+func (c *Client) AddAllRecords(ctx context.Context, params AddAllRecordsParams) (*AddAllRecordsResult, error) {
+	result := &AddAllRecordsResult{
+		IDs:       []string{},
+		Revisions: []string{},
+	}
+
+	processed, err := c.processInBulk(ctx, len(params.Records),
+		func(start, end int) bulk.Request {
+			return bulk.Request{
+				Method: "POST",
+				API:    c.httpClient.APIPath("records"),
+				Payload: map[string]any{
+					"app":     params.App,
+					"records": params.Records[start:end],
+				},
+			}
+		},
+		func(raw json.RawMessage) error {
+			var r AddRecordsResult
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return fmt.Errorf("レスポンス解析エラー: %w", err)
+			}
+			result.IDs = append(result.IDs, r.IDs...)
+			result.Revisions = append(result.Revisions, r.Revisions...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, newAllRecordsError(result, toAnySlice(params.Records[processed:]), processed, len(params.Records), err)
+	}
+
+	return result, nil
+}
+
+// UpdateAllRecords は件数の上限なくレコードを更新する
+// バルクリクエスト単位の処理と失敗時のエラーはパッケージのドキュメントを参照
+func (c *Client) UpdateAllRecords(ctx context.Context, params UpdateAllRecordsParams) (*UpdateAllRecordsResult, error) {
+	result := &UpdateAllRecordsResult{
+		Records: []UpdateRecordsResultItem{},
+	}
+
+	processed, err := c.processInBulk(ctx, len(params.Records),
+		func(start, end int) bulk.Request {
+			return bulk.Request{
+				Method: "PUT",
+				API:    c.httpClient.APIPath("records"),
+				Payload: map[string]any{
+					"app":     params.App,
+					"records": params.Records[start:end],
+				},
+			}
+		},
+		func(raw json.RawMessage) error {
+			var r UpdateRecordsResult
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return fmt.Errorf("レスポンス解析エラー: %w", err)
+			}
+			result.Records = append(result.Records, r.Records...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, newAllRecordsError(result, toAnySlice(params.Records[processed:]), processed, len(params.Records), err)
+	}
+
+	return result, nil
+}
+
+// DeleteAllRecords は件数の上限なくレコードを削除する
+// バルクリクエスト単位の処理と失敗時のエラーはパッケージのドキュメントを参照
+// ProcessedRecordsには削除済みのレコードIDが入る
+func (c *Client) DeleteAllRecords(ctx context.Context, params DeleteAllRecordsParams) error {
+	if len(params.Revisions) > 0 && len(params.Revisions) != len(params.IDs) {
+		return fmt.Errorf("IDsとRevisionsの件数が一致しません: IDs=%d, Revisions=%d", len(params.IDs), len(params.Revisions))
+	}
+
+	processed, err := c.processInBulk(ctx, len(params.IDs),
+		func(start, end int) bulk.Request {
+			payload := map[string]any{
+				"app": params.App,
+				"ids": params.IDs[start:end],
+			}
+			if len(params.Revisions) > 0 {
+				payload["revisions"] = params.Revisions[start:end]
+			}
+			return bulk.Request{
+				Method:  "DELETE",
+				API:     c.httpClient.APIPath("records"),
+				Payload: payload,
+			}
+		},
+		func(raw json.RawMessage) error {
+			return nil
+		},
+	)
+	if err != nil {
+		return newAllRecordsError(params.IDs[:processed], toAnySlice(params.IDs[processed:]), processed, len(params.IDs), err)
+	}
+
+	return nil
+}
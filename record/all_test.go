@@ -0,0 +1,260 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// bulkRequestBody はテスト用のバルクリクエストボディ
+type bulkRequestBody struct {
+	Requests []struct {
+		Method  string `json:"method"`
+		API     string `json:"api"`
+		Payload struct {
+			App     string            `json:"app"`
+			Records []json.RawMessage `json:"records"`
+			IDs     []string          `json:"ids"`
+		} `json:"payload"`
+	} `json:"requests"`
+}
+
+func newTestRecords(n int) []map[string]types.FieldValue {
+	records := make([]map[string]types.FieldValue, n)
+	for i := range records {
+		records[i] = map[string]types.FieldValue{
+			"名前": {Value: fmt.Sprintf("レコード%d", i)},
+		}
+	}
+	return records
+}
+
+func TestAddAllRecords(t *testing.T) {
+	var requestCounts []int
+	nextID := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k/v1/bulkRequest.json" {
+			t.Errorf("期待されるパス: /k/v1/bulkRequest.json, 実際: %s", r.URL.Path)
+		}
+
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		requestCounts = append(requestCounts, len(reqBody.Requests))
+
+		var results []map[string]any
+		for _, req := range reqBody.Requests {
+			if req.Method != "POST" || req.API != "/k/v1/records.json" {
+				t.Errorf("期待されるリクエスト: POST /k/v1/records.json, 実際: %s %s", req.Method, req.API)
+			}
+			if len(req.Payload.Records) > 100 {
+				t.Errorf("1リクエストのレコード数が上限を超えている: %d", len(req.Payload.Records))
+			}
+			var ids, revisions []string
+			for range req.Payload.Records {
+				ids = append(ids, fmt.Sprint(nextID))
+				revisions = append(revisions, "1")
+				nextID++
+			}
+			results = append(results, map[string]any{"ids": ids, "revisions": revisions})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	result, err := client.AddAllRecords(ctx, record.AddAllRecordsParams{
+		App:     "1",
+		Records: newTestRecords(4550),
+	})
+
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result.IDs) != 4550 || len(result.Revisions) != 4550 {
+		t.Errorf("期待される件数: 4550, 実際: ids=%d, revisions=%d", len(result.IDs), len(result.Revisions))
+	}
+	if result.IDs[4549] != "4550" {
+		t.Errorf("期待される最後のID: 4550, 実際: %s", result.IDs[4549])
+	}
+	want := []int{20, 20, 6}
+	if fmt.Sprint(requestCounts) != fmt.Sprint(want) {
+		t.Errorf("期待されるバルクリクエストごとのリクエスト数: %v, 実際: %v", want, requestCounts)
+	}
+}
+
+func TestAddAllRecordsPartialFailure(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		if callCount == 1 {
+			var results []map[string]any
+			for _, req := range reqBody.Requests {
+				ids := make([]string, len(req.Payload.Records))
+				revisions := make([]string, len(req.Payload.Records))
+				for i := range ids {
+					ids[i] = "1"
+					revisions[i] = "1"
+				}
+				results = append(results, map[string]any{"ids": ids, "revisions": revisions})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"results": results})
+			return
+		}
+
+		// 2回目のバルクリクエストは3番目のリクエストの6件目・8件目・13件目で失敗させる（最初のエラーは6件目）
+		results := make([]map[string]any, len(reqBody.Requests))
+		for i := range results {
+			results[i] = map[string]any{}
+		}
+		results[2] = map[string]any{
+			"code":    "CB_VA01",
+			"id":      "test-error-id",
+			"message": "入力内容が正しくありません。",
+			"errors": map[string]any{
+				"records[7].名前.value":  map[string]any{"messages": []string{"必須です。"}},
+				"records[5].名前.value":  map[string]any{"messages": []string{"必須です。"}},
+				"records[12].名前.value": map[string]any{"messages": []string{"必須です。"}},
+			},
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	_, err := client.AddAllRecords(ctx, record.AddAllRecordsParams{
+		App:     "1",
+		Records: newTestRecords(4500),
+	})
+
+	var allErr *kintoneError.KintoneAllRecordsError
+	if !errors.As(err, &allErr) {
+		t.Fatalf("KintoneAllRecordsErrorが返るはずが: %v", err)
+	}
+	if allErr.NumOfProcessedRecords != 2000 {
+		t.Errorf("期待される処理済み件数: 2000, 実際: %d", allErr.NumOfProcessedRecords)
+	}
+	if allErr.NumOfAllRecords != 4500 {
+		t.Errorf("期待される全件数: 4500, 実際: %d", allErr.NumOfAllRecords)
+	}
+	if len(allErr.UnprocessedRecords) != 2500 {
+		t.Errorf("期待される未処理件数: 2500, 実際: %d", len(allErr.UnprocessedRecords))
+	}
+	if allErr.ErrorIndex != 2205 {
+		t.Errorf("期待されるエラーインデックス: 2205, 実際: %d", allErr.ErrorIndex)
+	}
+	processed, ok := allErr.ProcessedRecords.(*record.AddAllRecordsResult)
+	if !ok || len(processed.IDs) != 2000 {
+		t.Errorf("期待される処理済み結果: 2000件, 実際: %v", allErr.ProcessedRecords)
+	}
+
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "CB_VA01" {
+		t.Errorf("原因のエラーが取得できない: %v", err)
+	}
+}
+
+func TestAddAllRecordsInvalidResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		// バルクリクエストは成功するが、結果の形式が不正
+		results := make([]any, len(reqBody.Requests))
+		for i := range results {
+			results[i] = map[string]any{"ids": "invalid"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	_, err := client.AddAllRecords(ctx, record.AddAllRecordsParams{
+		App:     "1",
+		Records: newTestRecords(2500),
+	})
+
+	var allErr *kintoneError.KintoneAllRecordsError
+	if !errors.As(err, &allErr) {
+		t.Fatalf("KintoneAllRecordsErrorが返るはずが: %v", err)
+	}
+	// サーバーでは追加済みのため、未処理に含めない
+	if allErr.NumOfProcessedRecords != 2000 {
+		t.Errorf("期待される処理済み件数: 2000, 実際: %d", allErr.NumOfProcessedRecords)
+	}
+	if len(allErr.UnprocessedRecords) != 500 {
+		t.Errorf("期待される未処理件数: 500, 実際: %d", len(allErr.UnprocessedRecords))
+	}
+}
+
+func TestDeleteAllRecords(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		var results []map[string]any
+		for _, req := range reqBody.Requests {
+			if req.Method != "DELETE" {
+				t.Errorf("期待されるメソッド: DELETE, 実際: %s", req.Method)
+			}
+			deleted = append(deleted, req.Payload.IDs...)
+			results = append(results, map[string]any{})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i + 1)
+	}
+
+	if err := client.DeleteAllRecords(ctx, record.DeleteAllRecordsParams{App: "1", IDs: ids}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(deleted) != 250 {
+		t.Errorf("期待される削除件数: 250, 実際: %d", len(deleted))
+	}
+
+	err := client.DeleteAllRecords(ctx, record.DeleteAllRecordsParams{
+		App:       "1",
+		IDs:       ids,
+		Revisions: []string{"1"},
+	})
+	if err == nil {
+		t.Error("IDsとRevisionsの件数不一致でエラーが発生するはずが、発生しなかった")
+	}
+}
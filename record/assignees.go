@@ -0,0 +1,120 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/goqoo-on-kintone/goten/bulk"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/query"
+)
+
+// defaultAssigneeField は作業者のフィールドコードの初期値
+const defaultAssigneeField = "作業者"
+
+// reassignTarget は作業者を付け替えるレコード
+type reassignTarget struct {
+	ID        string
+	Revision  string
+	Assignees []string
+}
+
+// ReassignRecords はFromが作業者になっているレコードの作業者をToに付け替える
+// ステータスは変更しない。Fromは外し、他の作業者はそのまま残す
+// 対象のレコードを全件取得してから、レコード単位の作業者更新を最大20件ずつバルクリクエストでアトミックに実行する
+// 取得時のリビジョンを指定するため、取得後に更新されたレコードがあるバルクリクエストは失敗する
+// 途中のバルクリクエストが失敗した場合は*error.KintoneAllRecordsErrorを返す（UnprocessedRecordsは未処理のレコードID）
+func (c *Client) ReassignRecords(ctx context.Context, params ReassignRecordsParams) (*ReassignRecordsResult, error) {
+	if params.From == "" {
+		return nil, fmt.Errorf("Fromが指定されていません")
+	}
+	field := params.AssigneeField
+	if field == "" {
+		field = defaultAssigneeField
+	}
+
+	condition := query.Field(field).In(params.From)
+	if params.Condition != "" {
+		condition = query.And(query.Raw(params.Condition), condition)
+	}
+
+	var targets []reassignTarget
+	for rec, err := range AllRecords[Dynamic](ctx, c, GetAllRecordsParams{
+		App:       params.App,
+		Fields:    []string{"$id", "$revision", field},
+		Condition: condition.String(),
+	}) {
+		if err != nil {
+			return nil, err
+		}
+		current, err := rec.Users(field)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, reassignTarget{
+			ID:        rec.ID(),
+			Revision:  rec.Revision(),
+			Assignees: replaceAssignee(current.Codes(), params.From, params.To),
+		})
+	}
+
+	requests := make([]bulk.Request, len(targets))
+	for i, target := range targets {
+		requests[i] = bulk.Request{
+			Method: "PUT",
+			API:    c.httpClient.APIPath("record/assignees"),
+			Payload: map[string]any{
+				"app":       params.App,
+				"id":        target.ID,
+				"assignees": target.Assignees,
+				"revision":  target.Revision,
+			},
+		}
+	}
+
+	result := &ReassignRecordsResult{
+		Records: []UpdateRecordsResultItem{},
+	}
+	done, err := c.sendInBulk(ctx, requests, func(i int, raw json.RawMessage) error {
+		var r UpdateRecordAssigneesResult
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("レスポンス解析エラー: %w", err)
+		}
+		result.Records = append(result.Records, UpdateRecordsResultItem{ID: targets[i].ID, Revision: r.Revision})
+		return nil
+	})
+	if err != nil {
+		unprocessed := make([]any, 0, len(targets)-done)
+		for _, target := range targets[done:] {
+			unprocessed = append(unprocessed, target.ID)
+		}
+		return nil, &kintoneError.KintoneAllRecordsError{
+			ProcessedRecords:      result,
+			UnprocessedRecords:    unprocessed,
+			Err:                   err,
+			ErrorIndex:            failedRequestIndex(done, err),
+			NumOfProcessedRecords: done,
+			NumOfAllRecords:       len(targets),
+		}
+	}
+
+	return result, nil
+}
+
+// replaceAssignee は作業者の一覧からfromを外してtoを加える（重複は除く）
+func replaceAssignee(current []string, from string, to []string) []string {
+	assignees := []string{}
+	for _, code := range current {
+		if code != from && !slices.Contains(assignees, code) {
+			assignees = append(assignees, code)
+		}
+	}
+	for _, code := range to {
+		if !slices.Contains(assignees, code) {
+			assignees = append(assignees, code)
+		}
+	}
+	return assignees
+}
@@ -0,0 +1,193 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+func TestUpdateRecordAssignees(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/k/v1/record/assignees.json" {
+			t.Errorf("期待されるリクエスト: PUT /k/v1/record/assignees.json, 実際: %s %s", r.Method, r.URL.Path)
+		}
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		if assignees, _ := json.Marshal(reqBody["assignees"]); string(assignees) != `[]` {
+			t.Errorf("期待されるassignees: [], 実際: %s", assignees)
+		}
+		if reqBody["revision"] != "3" {
+			t.Errorf("期待されるrevision: 3, 実際: %v", reqBody["revision"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"revision": "4"})
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	revision := "3"
+	result, err := client.UpdateRecordAssignees(context.Background(), record.UpdateRecordAssigneesParams{
+		App:      "1",
+		ID:       "10",
+		Revision: &revision,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Revision != "4" {
+		t.Errorf("期待されるrevision: 4, 実際: %s", result.Revision)
+	}
+}
+
+func TestReassignRecords(t *testing.T) {
+	const total = 45
+	bulkCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/k/v1/records.json" {
+			var reqBody struct {
+				Query string `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if !strings.HasPrefix(reqBody.Query, `((状態 = "対応中") and 担当 in ("leaving"))`) {
+				t.Errorf("期待されるクエリ: ((状態 = \"対応中\") and 担当 in (\"leaving\")) ..., 実際: %s", reqBody.Query)
+			}
+
+			records := []map[string]any{}
+			if strings.Contains(reqBody.Query, "$id > 0 ") {
+				for i := 1; i <= total; i++ {
+					records = append(records, map[string]any{
+						"$id":       map[string]any{"type": "__ID__", "value": strconv.Itoa(i)},
+						"$revision": map[string]any{"type": "__REVISION__", "value": "1"},
+						"担当": map[string]any{"type": "STATUS_ASSIGNEE", "value": []map[string]string{
+							{"code": "other", "name": "他のユーザー"},
+							{"code": "leaving", "name": "退職者"},
+						}},
+					})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records})
+			return
+		}
+
+		bulkCount++
+		var reqBody struct {
+			Requests []struct {
+				Method  string `json:"method"`
+				API     string `json:"api"`
+				Payload struct {
+					ID        string   `json:"id"`
+					Assignees []string `json:"assignees"`
+					Revision  string   `json:"revision"`
+				} `json:"payload"`
+			} `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		var results []map[string]any
+		for _, req := range reqBody.Requests {
+			if req.Method != "PUT" || req.API != "/k/v1/record/assignees.json" {
+				t.Errorf("期待されるリクエスト: PUT /k/v1/record/assignees.json, 実際: %s %s", req.Method, req.API)
+			}
+			if strings.Join(req.Payload.Assignees, ",") != "other,successor" {
+				t.Errorf("期待される作業者: other,successor, 実際: %v", req.Payload.Assignees)
+			}
+			if req.Payload.Revision != "1" {
+				t.Errorf("期待されるrevision: 1, 実際: %s", req.Payload.Revision)
+			}
+			results = append(results, map[string]any{"revision": "2"})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	result, err := client.ReassignRecords(context.Background(), record.ReassignRecordsParams{
+		App:           "1",
+		From:          "leaving",
+		To:            []string{"successor"},
+		Condition:     `状態 = "対応中"`,
+		AssigneeField: "担当",
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if bulkCount != 3 {
+		t.Errorf("期待されるバルクリクエスト回数: 3, 実際: %d", bulkCount)
+	}
+	if len(result.Records) != total {
+		t.Fatalf("期待される更新件数: %d, 実際: %d", total, len(result.Records))
+	}
+	if result.Records[44].ID != "45" || result.Records[44].Revision != "2" {
+		t.Errorf("期待される結果: id=45 revision=2, 実際: %+v", result.Records[44])
+	}
+}
+
+func TestReassignRecordsInvalidResult(t *testing.T) {
+	const total = 25
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/k/v1/records.json" {
+			var reqBody struct {
+				Query string `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			records := []map[string]any{}
+			if strings.Contains(reqBody.Query, "$id > 0 ") {
+				for i := 1; i <= total; i++ {
+					records = append(records, map[string]any{
+						"$id":       map[string]any{"type": "__ID__", "value": strconv.Itoa(i)},
+						"$revision": map[string]any{"type": "__REVISION__", "value": "1"},
+						"作業者":       map[string]any{"type": "STATUS_ASSIGNEE", "value": []map[string]string{{"code": "leaving"}}},
+					})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records})
+			return
+		}
+
+		// バルクリクエストは成功するが、結果の形式が不正
+		var reqBody struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		results := make([]map[string]any, len(reqBody.Requests))
+		for i := range results {
+			results[i] = map[string]any{"revision": map[string]any{}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	_, err := client.ReassignRecords(context.Background(), record.ReassignRecordsParams{
+		App:  "1",
+		From: "leaving",
+		To:   []string{"successor"},
+	})
+
+	var allErr *kintoneError.KintoneAllRecordsError
+	if !errors.As(err, &allErr) {
+		t.Fatalf("KintoneAllRecordsErrorが返るはずが: %v", err)
+	}
+	// 最初のバルクリクエストはサーバーで処理済みのため、未処理に含めない
+	if allErr.NumOfProcessedRecords != 20 || len(allErr.UnprocessedRecords) != 5 {
+		t.Errorf("期待される処理済み/未処理: 20/5, 実際: %d/%d", allErr.NumOfProcessedRecords, len(allErr.UnprocessedRecords))
+	}
+	if allErr.UnprocessedRecords[0] != "21" {
+		t.Errorf("期待される最初の未処理のレコード: 21, 実際: %v", allErr.UnprocessedRecords[0])
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/types"
 )
 
 // Client はレコード操作クライアント
@@ -88,45 +88,17 @@ func GetRecord[T any](ctx context.Context, c *Client, params GetRecordParams) (T
 }
 
 // GetAllRecords は全レコードを取得する（ページング自動処理）
-// 内部的に500件ずつ取得してすべて結合する
+// OrderByを指定しない場合は$idによるシーク（$id > 最後のID order by $id asc）で500件ずつ取得し、
+// 指定した場合はカーソルAPIで取得する。いずれもoffsetの上限（10,000件）の影響を受けない
+// 大量のレコードを扱う場合はメモリに保持しないAllRecordsの利用を推奨する
 func GetAllRecords[T any](ctx context.Context, c *Client, params GetAllRecordsParams) ([]T, error) {
-	const limit = 500
 	var allRecords []T
-	offset := 0
-
-	for {
-		// クエリを構築（offset/limitを追加）
-		query := params.Condition
-		if params.OrderBy != "" {
-			if query != "" {
-				query += " "
-			}
-			query += "order by " + params.OrderBy
-		}
-		if query != "" {
-			query += " "
-		}
-		query += fmt.Sprintf("limit %d offset %d", limit, offset)
-
-		result, err := GetRecords[T](ctx, c, GetRecordsParams{
-			App:    params.App,
-			Fields: params.Fields,
-			Query:  query,
-		})
+	for page, err := range AllRecordPages[T](ctx, c, params) {
 		if err != nil {
 			return nil, err
 		}
-
-		allRecords = append(allRecords, result.Records...)
-
-		// 取得件数が上限未満なら終了
-		if len(result.Records) < limit {
-			break
-		}
-
-		offset += limit
+		allRecords = append(allRecords, page...)
 	}
-
 	return allRecords, nil
 }
 
@@ -388,6 +360,35 @@ func (c *Client) UpdateRecordStatus(ctx context.Context, params UpdateRecordStat
 	return &result, nil
 }
 
+// UpdateRecordAssignees はステータスを変更せずにレコードの作業者を更新する
+// Assigneesを空にすると作業者をクリアする（最大100人）
+func (c *Client) UpdateRecordAssignees(ctx context.Context, params UpdateRecordAssigneesParams) (*UpdateRecordAssigneesResult, error) {
+	reqBody := map[string]any{
+		"app":       params.App,
+		"id":        params.ID,
+		"assignees": params.Assignees,
+	}
+
+	if params.Assignees == nil {
+		reqBody["assignees"] = []string{}
+	}
+	if params.Revision != nil {
+		reqBody["revision"] = *params.Revision
+	}
+
+	body, err := c.httpClient.Put(ctx, "record/assignees", reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var result UpdateRecordAssigneesResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
+	}
+
+	return &result, nil
+}
+
 // UpdateRecordsStatus は複数レコードのステータスを一括更新する
 func (c *Client) UpdateRecordsStatus(ctx context.Context, params UpdateRecordsStatusParams) (*UpdateRecordsStatusResult, error) {
 	reqBody := map[string]any{
@@ -408,52 +409,46 @@ func (c *Client) UpdateRecordsStatus(ctx context.Context, params UpdateRecordsSt
 	return &result, nil
 }
 
-// --- Upsert API ---
-
-// UpsertRecord はupdateKeyでレコードを検索し、存在すれば更新、なければ追加する
-// JS SDKと同様の便利メソッド
-func (c *Client) UpsertRecord(ctx context.Context, params UpsertRecordParams) (*UpsertRecordResult, error) {
-	// まず更新を試みる
-	updateResult, err := c.UpdateRecord(ctx, UpdateRecordParams{
-		App:       params.App,
-		UpdateKey: &params.UpdateKey,
-		Record:    params.Record,
-	})
-
-	if err == nil {
-		// 更新成功
-		return &UpsertRecordResult{
-			ID:       "", // 更新時はIDが返らないため空
-			Revision: updateResult.Revision,
-		}, nil
-	}
-
-	// エラーがGAIA_RE01（レコードが見つからない）の場合は新規追加
-	if strings.Contains(err.Error(), "GAIA_RE01") {
-		// updateKeyのフィールドをレコードに含める
-		recordWithKey := make(map[string]any)
-		for k, v := range params.Record {
-			recordWithKey[k] = v
-		}
-		// updateKeyのフィールドも追加
-		recordWithKey[params.UpdateKey.Field] = map[string]any{
-			"value": params.UpdateKey.Value,
-		}
+// --- アクセス権API ---
 
-		addResult, addErr := c.AddRecord(ctx, AddRecordParams{
-			App:    params.App,
-			Record: params.Record,
-		})
-		if addErr != nil {
-			return nil, addErr
-		}
+// recordAclEvaluateLimit は1回のアクセス権評価で指定できるレコード数の上限
+const recordAclEvaluateLimit = 100
+
+// EvaluateRecordAcl はログインユーザー（APIトークンの場合はトークン）のレコードのアクセス権を評価する（最大100件）
+func (c *Client) EvaluateRecordAcl(ctx context.Context, params EvaluateRecordAclParams) (*EvaluateRecordAclResult, error) {
+	reqBody := map[string]any{
+		"app": params.App,
+		"ids": params.IDs,
+	}
+
+	body, err := c.httpClient.GetWithBody(ctx, "records/acl/evaluate", reqBody)
+	if err != nil {
+		return nil, err
+	}
 
-		return &UpsertRecordResult{
-			ID:       addResult.ID,
-			Revision: addResult.Revision,
-		}, nil
+	var result EvaluateRecordAclResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
 	}
 
-	// その他のエラーはそのまま返す
-	return nil, err
+	return &result, nil
+}
+
+// EvaluateAllRecordAcl は件数の上限なくレコードのアクセス権を評価し、レコードIDからアクセス権を引けるmapで返す
+// 100件ずつ順にEvaluateRecordAclを実行する
+func (c *Client) EvaluateAllRecordAcl(ctx context.Context, params EvaluateRecordAclParams) (map[types.RecordID]RecordAclRight, error) {
+	rights := make(map[types.RecordID]RecordAclRight, len(params.IDs))
+	for start := 0; start < len(params.IDs); start += recordAclEvaluateLimit {
+		result, err := c.EvaluateRecordAcl(ctx, EvaluateRecordAclParams{
+			App: params.App,
+			IDs: params.IDs[start:min(start+recordAclEvaluateLimit, len(params.IDs))],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range result.Rights {
+			rights[right.ID] = right
+		}
+	}
+	return rights, nil
 }
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/goqoo-on-kintone/goten/auth"
@@ -232,6 +235,182 @@ func TestGetAllRecords(t *testing.T) {
 	}
 }
 
+// seekPattern はシーク用クエリから$idの下限を取り出す
+var seekPattern = regexp.MustCompile(`\$id > (\d+) order by \$id asc limit (\d+)$`)
+
+func TestGetAllRecordsSeek(t *testing.T) {
+	const total = 50000
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+
+		if strings.Contains(query, "offset") {
+			t.Errorf("offsetを使わないはずが使われた: %s", query)
+		}
+		if !strings.HasPrefix(query, "(名前 like \"レコード\") and ") {
+			t.Errorf("条件が引き継がれていない: %s", query)
+		}
+		fields, _ := reqBody["fields"].([]any)
+		if len(fields) != 2 || fields[1] != "$id" {
+			t.Errorf("fieldsに$idが追加されていない: %v", fields)
+		}
+
+		m := seekPattern.FindStringSubmatch(query)
+		if m == nil {
+			t.Fatalf("シーク用のクエリではない: %s", query)
+		}
+		lastID, _ := strconv.Atoi(m[1])
+		limit, _ := strconv.Atoi(m[2])
+
+		records := []map[string]any{}
+		for id := lastID + 1; id <= total && len(records) < limit; id++ {
+			records = append(records, map[string]any{
+				"$id": map[string]string{"value": strconv.Itoa(id)},
+				"名前":  map[string]string{"value": "レコード"},
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	result, err := record.GetAllRecords[TestRecord](ctx, client, record.GetAllRecordsParams{
+		App:       "1",
+		Fields:    []string{"名前"},
+		Condition: `名前 like "レコード"`,
+	})
+
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result) != total {
+		t.Errorf("期待されるレコード数: %d, 実際: %d", total, len(result))
+	}
+	if result[total-1].ID.Value != strconv.Itoa(total) {
+		t.Errorf("期待される最後のID: %d, 実際: %s", total, result[total-1].ID.Value)
+	}
+	// 50000件 / 500件 = 100回 + 0件の確認で1回
+	if callCount != 101 {
+		t.Errorf("期待されるAPI呼び出し回数: 101, 実際: %d", callCount)
+	}
+}
+
+func TestGetAllRecordsCursor(t *testing.T) {
+	const total = 12345
+	sent := 0
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k/v1/records/cursor.json" {
+			t.Fatalf("カーソルAPI以外が呼ばれた: %s", r.URL.Path)
+		}
+
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "POST":
+			if reqBody["query"] != "名前 != \"\" order by 名前 desc" {
+				t.Errorf("期待されるクエリと異なる: %v", reqBody["query"])
+			}
+			if reqBody["size"] != float64(500) {
+				t.Errorf("期待されるsize: 500, 実際: %v", reqBody["size"])
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "cursor-1", "totalCount": strconv.Itoa(total)})
+		case "GET":
+			records := []map[string]any{}
+			for ; sent < total && len(records) < 500; sent++ {
+				records = append(records, map[string]any{
+					"$id": map[string]string{"value": strconv.Itoa(sent + 1)},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records, "next": sent < total})
+		case "DELETE":
+			deleted = true
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	result, err := record.GetAllRecords[TestRecord](ctx, client, record.GetAllRecordsParams{
+		App:       "1",
+		Condition: `名前 != ""`,
+		OrderBy:   "名前 desc",
+	})
+
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result) != total {
+		t.Errorf("期待されるレコード数: %d, 実際: %d", total, len(result))
+	}
+	if deleted {
+		t.Error("最後まで取得した場合はカーソルを削除しないはずが、削除された")
+	}
+}
+
+func TestGetAllRecordsCursorDeletedOnError(t *testing.T) {
+	getCount := 0
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "POST":
+			json.NewEncoder(w).Encode(map[string]any{"id": "cursor-1", "totalCount": "1000"})
+		case "GET":
+			getCount++
+			if getCount == 2 {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]any{"code": "GAIA_XX01", "message": "エラー"})
+				return
+			}
+			records := make([]map[string]any, 500)
+			for i := range records {
+				records[i] = map[string]any{"$id": map[string]string{"value": strconv.Itoa(i + 1)}}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records, "next": true})
+		case "DELETE":
+			var reqBody map[string]any
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if reqBody["id"] != "cursor-1" {
+				t.Errorf("期待されるカーソルID: cursor-1, 実際: %v", reqBody["id"])
+			}
+			deleted = true
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	_, err := record.GetAllRecords[TestRecord](ctx, client, record.GetAllRecordsParams{
+		App:     "1",
+		OrderBy: "名前 asc",
+	})
+
+	if err == nil {
+		t.Fatal("エラーが発生するはずが、発生しなかった")
+	}
+	if !deleted {
+		t.Error("エラー時にカーソルが削除されていない")
+	}
+}
+
 func TestAPIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
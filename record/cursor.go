@@ -0,0 +1,319 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// CursorManagerの既定値
+const (
+	defaultMaxCursors          = 10               // ドメインごとに同時に開けるカーソルの数
+	defaultCursorLifetime      = 10 * time.Minute // カーソルの有効期限
+	defaultCursorRetryInterval = 5 * time.Second
+)
+
+// CursorManagerOptions はCursorManagerの設定
+type CursorManagerOptions struct {
+	MaxCursors int // 同時に開くカーソルの上限（0の場合は10）
+
+	// WaitForSlot がtrueの場合、カーソルが上限まで開いているときは空くまで待つ（falseの場合はエラーを返す）
+	// 他のプロセスのカーソルでkintoneの上限に達している場合も、RetryIntervalごとに作成を再試行する
+	WaitForSlot   bool
+	RetryInterval time.Duration // kintoneの上限に達している場合の再試行間隔（0の場合は5秒）
+
+	// CursorLifetime はカーソルの有効期限（0の場合は10分）
+	// 最後に使用してから有効期限が過ぎたカーソルの取得に失敗した場合は、期限切れとして最後の$idから取得し直す
+	CursorLifetime time.Duration
+}
+
+// OpenCursor はCursorManagerが開いているカーソル
+type OpenCursor struct {
+	ID         string
+	App        types.AppID
+	Query      string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// CursorManager はカーソルの作成・取得・削除を管理する
+// 開いているカーソルの数を上限までに制限し、取得が終わらなかったカーソル（エラー、キャンセル、break）は必ず削除する
+// 複数のgoroutineから同時に使用できる
+type CursorManager struct {
+	client *Client
+	opts   CursorManagerOptions
+	slots  chan struct{}
+
+	mu   sync.Mutex
+	open map[string]*OpenCursor
+}
+
+// NewCursorManager は新しいCursorManagerを作成する
+func NewCursorManager(c *Client, opts CursorManagerOptions) *CursorManager {
+	if opts.MaxCursors <= 0 {
+		opts.MaxCursors = defaultMaxCursors
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultCursorRetryInterval
+	}
+	if opts.CursorLifetime <= 0 {
+		opts.CursorLifetime = defaultCursorLifetime
+	}
+	return &CursorManager{
+		client: c,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxCursors),
+		open:   map[string]*OpenCursor{},
+	}
+}
+
+// OpenCursors は開いているカーソルを作成日時の順で返す
+func (m *CursorManager) OpenCursors() []OpenCursor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cursors := make([]OpenCursor, 0, len(m.open))
+	for _, cursor := range m.open {
+		cursors = append(cursors, *cursor)
+	}
+	sort.Slice(cursors, func(i, j int) bool { return cursors[i].CreatedAt.Before(cursors[j].CreatedAt) })
+	return cursors
+}
+
+// Close は開いている全てのカーソルを削除する
+// 削除に失敗したカーソルがあっても全てのカーソルの削除を試み、最初のエラーを返す
+func (m *CursorManager) Close(ctx context.Context) error {
+	var firstErr error
+	for _, cursor := range m.OpenCursors() {
+		if err := m.release(ctx, cursor.ID, true); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// create はカーソルの枠を確保してカーソルを作成する
+func (m *CursorManager) create(ctx context.Context, params CreateCursorParams) (*OpenCursor, error) {
+	if m.opts.WaitForSlot {
+		select {
+		case m.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case m.slots <- struct{}{}:
+		default:
+			return nil, fmt.Errorf("開いているカーソルが上限（%d）に達しています", m.opts.MaxCursors)
+		}
+	}
+
+	for {
+		result, err := m.client.CreateCursor(ctx, params)
+		if err == nil {
+			now := time.Now()
+			cursor := &OpenCursor{ID: result.ID, App: params.App, Query: params.Query, CreatedAt: now, LastUsedAt: now}
+			m.mu.Lock()
+			m.open[cursor.ID] = cursor
+			m.mu.Unlock()
+			return cursor, nil
+		}
+		if !m.opts.WaitForSlot || !kintoneError.HasCode(err, kintoneError.CodeCursorLimit) {
+			<-m.slots
+			return nil, err
+		}
+
+		timer := time.NewTimer(m.opts.RetryInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			<-m.slots
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// touch はカーソルの最終使用日時を更新する
+func (m *CursorManager) touch(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cursor, ok := m.open[id]; ok {
+		cursor.LastUsedAt = time.Now()
+	}
+}
+
+// expired はカーソルの取得エラーが有効期限切れによるものかどうかを返す
+// カーソルが存在しないエラーで、最後に使用してからCursorLifetimeが過ぎている場合に有効期限切れとする
+func (m *CursorManager) expired(id string, err error) bool {
+	if !kintoneError.HasCode(err, kintoneError.CodeCursorNotFound) {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cursor, ok := m.open[id]
+	return ok && time.Since(cursor.LastUsedAt) >= m.opts.CursorLifetime
+}
+
+// release はカーソルの管理を終了して枠を空ける。deleteCursorの場合はカーソルを削除する
+// キャンセル済みのcontextでも削除できるようにする
+func (m *CursorManager) release(ctx context.Context, id string, deleteCursor bool) error {
+	m.mu.Lock()
+	_, ok := m.open[id]
+	delete(m.open, id)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	defer func() { <-m.slots }()
+
+	if !deleteCursor {
+		return nil
+	}
+	return m.client.DeleteCursor(context.WithoutCancel(ctx), DeleteCursorParams{ID: id})
+}
+
+// cursorOrder はOrderByが$idのみの場合に並び順（asc / desc）を返す
+// OrderBy省略時は$idの昇順とする。それ以外の並び順の場合は空文字列を返す
+func cursorOrder(orderBy string) string {
+	switch strings.Join(strings.Fields(strings.ToLower(orderBy)), " ") {
+	case "", "$id", "$id asc":
+		return "asc"
+	case "$id desc":
+		return "desc"
+	}
+	return ""
+}
+
+// CursorRecords はCursorManagerでカーソルを作成し、全レコードを1件ずつ返すイテレータを返す
+// 取得方法はCursorPagesと同じ
+func CursorRecords[T any](ctx context.Context, m *CursorManager, params GetAllRecordsParams) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range CursorPages[T](ctx, m, params) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, rec := range page {
+				if !yield(rec, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CursorPages はCursorManagerでカーソルを作成し、全レコードを最大500件のページ単位で返すイテレータを返す
+//
+// OrderBy省略時は$idの昇順で取得する。最後まで取得せずに終了した場合（エラー、キャンセル、break）はカーソルを削除する。
+// OrderByが$idのみの場合は、カーソルが有効期限切れになっていたら、最後に取得した$idの続きから新しいカーソルで取得し直す
+// （Fieldsを指定した場合は$idを追加する）。それ以外の並び順では、期限切れの場合もエラーを返す
+func CursorPages[T any](ctx context.Context, m *CursorManager, params GetAllRecordsParams) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		order := cursorOrder(params.OrderBy)
+		orderBy := params.OrderBy
+		fields := params.Fields
+		if order != "" {
+			orderBy = "$id " + order
+			fields = fieldsWithID(fields)
+		}
+
+		lastID := ""
+		for {
+			condition := params.Condition
+			if lastID != "" {
+				operator := ">"
+				if order == "desc" {
+					operator = "<"
+				}
+				condition = fmt.Sprintf("$id %s %s", operator, lastID)
+				if params.Condition != "" {
+					condition = "(" + params.Condition + ") and " + condition
+				}
+			}
+			query := condition
+			if query != "" {
+				query += " "
+			}
+			query += "order by " + orderBy
+
+			cursor, err := m.create(ctx, CreateCursorParams{App: params.App, Fields: fields, Query: query, Size: getAllRecordsLimit})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !cursorPages(ctx, m, cursor.ID, order != "", &lastID, yield) {
+				return
+			}
+		}
+	}
+}
+
+// cursorPages はカーソルのページを返す
+// カーソルが有効期限切れで、続きから取得し直す場合はtrueを返す
+// lastIDには最後に返したレコードの$idを記録する（trackIDの場合のみ）
+func cursorPages[T any](ctx context.Context, m *CursorManager, id string, trackID bool, lastID *string, yield func([]T, error) bool) bool {
+	completed := false
+	defer func() {
+		if !completed {
+			m.release(ctx, id, true)
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return false
+		}
+
+		result, err := GetRecordsByCursor[json.RawMessage](ctx, m.client, GetRecordsByCursorParams{ID: id})
+		if err != nil {
+			if trackID && m.expired(id, err) {
+				// 期限切れのカーソルは削除済み
+				completed = true
+				m.release(ctx, id, false)
+				return true
+			}
+			yield(nil, err)
+			return false
+		}
+		m.touch(id)
+		if !result.Next {
+			// 最後まで取得するとカーソルは自動的に削除される
+			completed = true
+			m.release(ctx, id, false)
+		}
+
+		page := make([]T, len(result.Records))
+		for i, raw := range result.Records {
+			if err := json.Unmarshal(raw, &page[i]); err != nil {
+				yield(nil, fmt.Errorf("レスポンス解析エラー: %w", err))
+				return false
+			}
+		}
+		if trackID && len(result.Records) > 0 {
+			var last recordIDOnly
+			if err := json.Unmarshal(result.Records[len(result.Records)-1], &last); err != nil || last.ID.Value == "" {
+				yield(nil, fmt.Errorf("レコードに$idが含まれていません"))
+				return false
+			}
+			*lastID = last.ID.Value
+		}
+
+		if len(page) > 0 && !yield(page, nil) {
+			return false
+		}
+		if !result.Next {
+			return false
+		}
+	}
+}
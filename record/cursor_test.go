@@ -0,0 +1,316 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+// cursorServer はカーソルAPIに応答するテストサーバー
+// 作成したカーソルごとにpagesの$idをページとして返す
+type cursorServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	queries   []string // 作成時のクエリ
+	deleted   []string // 削除したカーソルID
+	pages     map[string][][]int
+	createErr []string       // 作成時に順に返すエラーコード
+	getErr    map[string]int // カーソルIDごとに、何回目の取得でエラーを返すか
+	getCode   string         // 取得時に返すエラーコード（省略時はカーソルが存在しないエラー）
+	gets      map[string]int
+}
+
+// newCursorServer はカーソルを作成するたびにpagesを順に割り当てるテストサーバーを作成する
+func newCursorServer(t *testing.T, pages ...[][]int) *cursorServer {
+	t.Helper()
+	s := &cursorServer{pages: map[string][][]int{}, getErr: map[string]int{}, gets: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		switch r.Method {
+		case "POST":
+			if len(s.createErr) > 0 {
+				code := s.createErr[0]
+				s.createErr = s.createErr[1:]
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"code": %q, "id": "error-id", "message": "エラー"}`, code)
+				return
+			}
+			query, _ := reqBody["query"].(string)
+			s.queries = append(s.queries, query)
+			id := fmt.Sprintf("cursor-%d", len(s.queries))
+			if len(s.queries) <= len(pages) {
+				s.pages[id] = pages[len(s.queries)-1]
+			}
+			fmt.Fprintf(w, `{"id": %q, "totalCount": "0"}`, id)
+
+		case "GET":
+			id, _ := reqBody["id"].(string)
+			s.gets[id]++
+			if n, ok := s.getErr[id]; ok && n == s.gets[id] {
+				code := s.getCode
+				if code == "" {
+					code = kintoneError.CodeCursorNotFound
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"code": %q, "id": "error-id", "message": "エラー"}`, code)
+				return
+			}
+			remaining := s.pages[id]
+			records := []map[string]any{}
+			if len(remaining) > 0 {
+				for _, recordID := range remaining[0] {
+					records = append(records, map[string]any{"$id": map[string]string{"value": fmt.Sprint(recordID)}})
+				}
+				s.pages[id] = remaining[1:]
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records, "next": len(s.pages[id]) > 0})
+
+		case "DELETE":
+			id, _ := reqBody["id"].(string)
+			s.deleted = append(s.deleted, id)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	return s
+}
+
+// newCursorManager はテストサーバーに接続するCursorManagerを作成する
+func newCursorManager(serverURL string, opts record.CursorManagerOptions) *record.CursorManager {
+	httpClient := gotenhttp.NewDefaultClient(serverURL, auth.APITokenAuth{Token: "test-token"})
+	return record.NewCursorManager(record.NewClient(httpClient), opts)
+}
+
+// collectIDs はイテレータのレコードの$idを集める
+func collectIDs(t *testing.T, seq iter.Seq2[idRecord, error]) []string {
+	t.Helper()
+	var ids []string
+	for rec, err := range seq {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		ids = append(ids, rec.ID.Value)
+	}
+	return ids
+}
+
+func TestCursorRecords(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1, 2}, {3}})
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{})
+
+	ids := collectIDs(t, record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{
+		App:       "1",
+		Condition: `status = "done"`,
+	}))
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("期待される$id: 1,2,3, 実際: %v", ids)
+	}
+	if server.queries[0] != `status = "done" order by $id asc` {
+		t.Errorf("期待されるクエリ: status = \"done\" order by $id asc, 実際: %s", server.queries[0])
+	}
+	// 最後まで取得したカーソルは自動的に削除されるため、削除しない
+	if len(server.deleted) != 0 {
+		t.Errorf("カーソルを削除しないはず: %v", server.deleted)
+	}
+	if open := m.OpenCursors(); len(open) != 0 {
+		t.Errorf("開いているカーソルがないはず: %+v", open)
+	}
+}
+
+func TestCursorRecordsBreakDeletesCursor(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1, 2}, {3}})
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{})
+
+	for _, err := range record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{App: "1"}) {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if len(m.OpenCursors()) != 1 {
+			t.Errorf("期待される開いているカーソル数: 1, 実際: %d", len(m.OpenCursors()))
+		}
+		break
+	}
+	if len(server.deleted) != 1 || server.deleted[0] != "cursor-1" {
+		t.Errorf("期待される削除: cursor-1, 実際: %v", server.deleted)
+	}
+	if open := m.OpenCursors(); len(open) != 0 {
+		t.Errorf("開いているカーソルがないはず: %+v", open)
+	}
+}
+
+func TestCursorRecordsResumeExpired(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1, 2}, {3}}, [][]int{{3}, {4}})
+	server.getErr["cursor-1"] = 2
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{CursorLifetime: time.Nanosecond})
+
+	ids := collectIDs(t, record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{
+		App:       "1",
+		Condition: `status = "done"`,
+	}))
+	if strings.Join(ids, ",") != "1,2,3,4" {
+		t.Errorf("期待される$id: 1,2,3,4, 実際: %v", ids)
+	}
+	if len(server.queries) != 2 || server.queries[1] != `(status = "done") and $id > 2 order by $id asc` {
+		t.Errorf("期待される再作成のクエリ: (status = \"done\") and $id > 2 order by $id asc, 実際: %v", server.queries)
+	}
+}
+
+func TestCursorRecordsExpiredOtherOrder(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1, 2}, {3}})
+	server.getErr["cursor-1"] = 2
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{CursorLifetime: time.Nanosecond})
+
+	var gotErr error
+	for _, err := range record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{App: "1", OrderBy: "金額 desc"}) {
+		if err != nil {
+			gotErr = err
+		}
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "GAIA_RE18") {
+		t.Errorf("期待されるエラー: GAIA_RE18, 実際: %v", gotErr)
+	}
+	if len(server.queries) != 1 {
+		t.Errorf("カーソルを作成し直さないはず: %v", server.queries)
+	}
+	if len(server.deleted) != 1 {
+		t.Errorf("エラーの場合はカーソルを削除するはず: %v", server.deleted)
+	}
+}
+
+func TestCursorRecordsOtherErrorAfterLifetime(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1, 2}, {3}}, [][]int{{3}})
+	server.getErr["cursor-1"] = 2
+	server.getCode = kintoneError.CodeNoPermission
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{CursorLifetime: time.Nanosecond})
+
+	// 有効期限を過ぎていても、カーソルが存在しないエラー以外はそのまま返す
+	var gotErr error
+	for _, err := range record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{App: "1"}) {
+		if err != nil {
+			gotErr = err
+		}
+	}
+	if !kintoneError.HasCode(gotErr, kintoneError.CodeNoPermission) {
+		t.Errorf("期待されるエラー: %s, 実際: %v", kintoneError.CodeNoPermission, gotErr)
+	}
+	if len(server.queries) != 1 {
+		t.Errorf("カーソルを作成し直さないはず: %v", server.queries)
+	}
+}
+
+func TestCursorManagerLimit(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1}, {2}}, [][]int{{1}})
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{MaxCursors: 1})
+
+	params := record.GetAllRecordsParams{App: "1"}
+	for _, err := range record.CursorPages[idRecord](context.Background(), m, params) {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		for _, err := range record.CursorPages[idRecord](context.Background(), m, params) {
+			if err == nil || !strings.Contains(err.Error(), "上限") {
+				t.Errorf("期待されるエラー: 上限, 実際: %v", err)
+			}
+		}
+		break
+	}
+
+	// 枠が空いた後は作成できる
+	if ids := collectIDs(t, record.CursorRecords[idRecord](context.Background(), m, params)); len(ids) != 1 {
+		t.Errorf("期待される件数: 1, 実際: %v", ids)
+	}
+}
+
+func TestCursorManagerWaitForSlot(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1}, {2}}, [][]int{{3}})
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{MaxCursors: 1, WaitForSlot: true})
+	params := record.GetAllRecordsParams{App: "1"}
+
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		for range record.CursorPages[idRecord](context.Background(), m, params) {
+			close(holding)
+			<-release
+			break
+		}
+	}()
+	<-holding
+
+	done := make(chan []string)
+	go func() {
+		var ids []string
+		for rec, err := range record.CursorRecords[idRecord](context.Background(), m, params) {
+			if err == nil {
+				ids = append(ids, rec.ID.Value)
+			}
+		}
+		done <- ids
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("カーソルの枠が空くまで待つはず")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if ids := <-done; strings.Join(ids, ",") != "3" {
+		t.Errorf("期待される$id: 3, 実際: %v", ids)
+	}
+}
+
+func TestCursorManagerRetryOnDomainLimit(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1}})
+	server.createErr = []string{"GAIA_TM12", "GAIA_TM12"}
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{WaitForSlot: true, RetryInterval: time.Millisecond})
+
+	ids := collectIDs(t, record.CursorRecords[idRecord](context.Background(), m, record.GetAllRecordsParams{App: "1"}))
+	if strings.Join(ids, ",") != "1" {
+		t.Errorf("期待される$id: 1, 実際: %v", ids)
+	}
+}
+
+func TestCursorManagerClose(t *testing.T) {
+	server := newCursorServer(t, [][]int{{1}, {2}})
+	defer server.Close()
+	m := newCursorManager(server.URL, record.CursorManagerOptions{})
+
+	next, stop := iter.Pull2(record.CursorPages[idRecord](context.Background(), m, record.GetAllRecordsParams{App: "1"}))
+	defer stop()
+	if _, err, ok := next(); !ok || err != nil {
+		t.Fatalf("最初のページを取得できない: %v", err)
+	}
+
+	if err := m.Close(context.Background()); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(server.deleted) != 1 || len(m.OpenCursors()) != 0 {
+		t.Errorf("開いているカーソルを削除するはず: 削除 %v, 開いている %+v", server.deleted, m.OpenCursors())
+	}
+}
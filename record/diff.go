@@ -0,0 +1,144 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// diffRow は差分更新用のテーブルの行
+// 変更のない行はIDのみを送信する
+type diffRow struct {
+	ID    string                     `json:"id,omitempty"`
+	Value map[string]json.RawMessage `json:"value,omitempty"`
+}
+
+// recordMeta はレコードの$idと$revision
+type recordMeta struct {
+	ID       struct{ Value string } `json:"$id"`
+	Revision struct{ Value string } `json:"$revision"`
+}
+
+// Diff は変更前と変更後のレコードを比較し、変更されたフィールドのみを含む更新パラメータを作成する
+// 構造体、Dynamicのいずれも指定できる。書き込みできないフィールドはToFieldValuesと同様に除外される
+//
+// IDとRevisionには変更前のレコードの$idと$revisionを設定する（$revisionがない場合はnil）
+// テーブルは行IDで対応付け、変更のない行はIDのみ、変更された行は変更されたセルのみ、
+// 追加された行は全セルを送信する。削除された行は送信しないことでkintone側で削除される
+// 変更がない場合、RecordはnilになるためHasChangesで判定できる
+//
+// originalにあってmodifiedにないフィールドは変更なしとして扱い、値を空にする更新は作成しない
+// （fieldsを指定して取得したレコードや、omitemptyで省略したフィールドを空にしないため）
+// 値を空にする場合は、modifiedのフィールドに空の値を設定する
+func Diff[T any](app types.AppID, original, modified T) (UpdateRecordParams, error) {
+	params := UpdateRecordParams{App: app}
+
+	var meta recordMeta
+	data, err := json.Marshal(original)
+	if err != nil {
+		return params, fmt.Errorf("レコードのJSONエンコードエラー: %w", err)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return params, fmt.Errorf("レコードはJSONオブジェクトである必要があります: %w", err)
+	}
+	params.ID = meta.ID.Value
+	if meta.Revision.Value != "" {
+		revision := meta.Revision.Value
+		params.Revision = &revision
+	}
+
+	before, err := ToFieldValues(original)
+	if err != nil {
+		return params, err
+	}
+	after, err := ToFieldValues(modified)
+	if err != nil {
+		return params, err
+	}
+
+	for code, field := range after {
+		value := field.Value.(json.RawMessage)
+		prev, ok := before[code]
+		if !ok {
+			params.Record = setDiffField(params.Record, code, value)
+			continue
+		}
+		prevValue := prev.Value.(json.RawMessage)
+		if jsonEqual(prevValue, value) {
+			continue
+		}
+
+		var prevRows, rows []rawSubtableRow
+		if isSubtableValue(prevValue, &prevRows) && isSubtableValue(value, &rows) {
+			diffValue, err := diffSubtable(prevRows, rows)
+			if err != nil {
+				return params, fmt.Errorf("テーブルの差分作成エラー: %s: %w", code, err)
+			}
+			value = diffValue
+		}
+		params.Record = setDiffField(params.Record, code, value)
+	}
+
+	return params, nil
+}
+
+// HasChanges は更新パラメータに変更するフィールドがあるかどうかを返す
+func (p UpdateRecordParams) HasChanges() bool {
+	return len(p.Record) > 0
+}
+
+// setDiffField は差分のフィールドを追加する
+func setDiffField(record map[string]types.FieldValue, code string, value json.RawMessage) map[string]types.FieldValue {
+	if record == nil {
+		record = map[string]types.FieldValue{}
+	}
+	record[code] = types.FieldValue{Value: value}
+	return record
+}
+
+// diffSubtable はテーブルの行を行IDで対応付けて差分を作成する
+func diffSubtable(before, after []rawSubtableRow) (json.RawMessage, error) {
+	prevRows := make(map[string]rawSubtableRow, len(before))
+	for _, row := range before {
+		if row.ID != "" {
+			prevRows[row.ID] = row
+		}
+	}
+
+	rows := make([]diffRow, len(after))
+	for i, row := range after {
+		prev, ok := prevRows[row.ID]
+		if row.ID == "" || !ok {
+			// 追加された行（変更前に存在しない行IDは新規の行として扱う）
+			rows[i] = diffRow{Value: row.Value}
+			continue
+		}
+
+		rows[i] = diffRow{ID: row.ID}
+		for subCode, cell := range row.Value {
+			if prevCell, ok := prev.Value[subCode]; ok && jsonEqual(prevCell, cell) {
+				continue
+			}
+			if rows[i].Value == nil {
+				rows[i].Value = map[string]json.RawMessage{}
+			}
+			rows[i].Value[subCode] = cell
+		}
+	}
+
+	return json.Marshal(rows)
+}
+
+// jsonEqual はJSONの値が等しいかどうかを返す（キーの順序や空白は無視する）
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
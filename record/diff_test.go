@@ -0,0 +1,132 @@
+package record_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// diffItem はテスト用のテーブルの行
+type diffItem struct {
+	Product  types.SingleLineTextField `json:"品名"`
+	Quantity types.NumberField         `json:"数量"`
+	Subtotal types.CalcField           `json:"小計"`
+}
+
+// DiffRecord はテスト用のレコード構造体
+type DiffRecord struct {
+	ID       types.IDField                 `json:"$id"`
+	Revision types.RevisionField           `json:"$revision"`
+	Name     types.SingleLineTextField     `json:"名前"`
+	Amount   types.NumberField             `json:"金額"`
+	Tags     types.CheckBoxField           `json:"タグ"`
+	Items    types.SubtableField[diffItem] `json:"明細"`
+}
+
+func newDiffRecord() DiffRecord {
+	row := func(id, product, quantity string) types.SubtableRow[diffItem] {
+		return types.SubtableRow[diffItem]{ID: id, Value: diffItem{
+			Product:  types.SingleLineTextField{Type: "SINGLE_LINE_TEXT", Value: product},
+			Quantity: types.NumberField{Type: "NUMBER", Value: types.Decimal(quantity)},
+			Subtotal: types.CalcField{Type: "CALC", Value: "0"},
+		}}
+	}
+	return DiffRecord{
+		ID:       types.IDField{Type: "__ID__", Value: "10"},
+		Revision: types.RevisionField{Type: "__REVISION__", Value: "5"},
+		Name:     types.SingleLineTextField{Type: "SINGLE_LINE_TEXT", Value: "テスト"},
+		Amount:   types.NumberField{Type: "NUMBER", Value: "100"},
+		Tags:     types.CheckBoxField{Type: "CHECK_BOX", Value: types.Strings{"A"}},
+		Items: types.SubtableField[diffItem]{Type: "SUBTABLE", Value: []types.SubtableRow[diffItem]{
+			row("1", "りんご", "1"),
+			row("2", "みかん", "2"),
+			row("3", "ぶどう", "3"),
+		}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	original := newDiffRecord()
+	modified := newDiffRecord()
+	modified.Amount.Value = "200"
+	modified.Items.Value[1].Value.Quantity.Value = "5"
+	modified.Items.Value[1].Value.Subtotal.Value = "999"
+	modified.Items.Value = append(modified.Items.Value[:2], types.SubtableRow[diffItem]{Value: diffItem{
+		Product:  types.SingleLineTextField{Value: "もも"},
+		Quantity: types.NumberField{Value: "1"},
+		Subtotal: types.CalcField{Type: "CALC"},
+	}})
+
+	params, err := record.Diff("1", original, modified)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if params.App != "1" || params.ID != "10" {
+		t.Errorf("期待されるapp/id: 1/10, 実際: %s/%s", params.App, params.ID)
+	}
+	if params.Revision == nil || *params.Revision != "5" {
+		t.Errorf("期待されるrevision: 5, 実際: %v", params.Revision)
+	}
+
+	data, _ := json.Marshal(params.Record)
+	want := `{"明細":{"value":[{"id":"1"},{"id":"2","value":{"数量":{"value":"5"}}},{"value":{"品名":{"value":"もも"},"数量":{"value":"1"}}}]},"金額":{"value":"200"}}`
+	if string(data) != want {
+		t.Errorf("期待される差分: %s, 実際: %s", want, data)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	original := newDiffRecord()
+	modified := newDiffRecord()
+	modified.Items.Value[0].Value.Subtotal.Value = "123" // 計算フィールドの変更は無視される
+
+	params, err := record.Diff("1", original, modified)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if params.HasChanges() {
+		t.Errorf("変更がないはずが差分あり: %v", params.Record)
+	}
+}
+
+func TestDiffDynamic(t *testing.T) {
+	original := newDynamicRecord(t)
+	modified := newDynamicRecord(t)
+	modified.SetStrings("タグ", "A", "B")
+
+	params, err := record.Diff("1", original, modified)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	data, _ := json.Marshal(params.Record)
+	if want := `{"タグ":{"value":["A","B"]}}`; string(data) != want {
+		t.Errorf("期待される差分: %s, 実際: %s", want, data)
+	}
+	if params.ID != "7" || *params.Revision != "3" {
+		t.Errorf("期待されるid/revision: 7/3, 実際: %s/%v", params.ID, *params.Revision)
+	}
+}
+
+func TestDiffMissingField(t *testing.T) {
+	original := newDynamicRecord(t)
+	var modified record.Dynamic
+	if err := json.Unmarshal([]byte(`{
+		"$id": {"type": "__ID__", "value": "7"},
+		"$revision": {"type": "__REVISION__", "value": "3"},
+		"名前": {"type": "SINGLE_LINE_TEXT", "value": "変更"}
+	}`), &modified); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	// modifiedにないフィールドは空にせず、更新に含めない
+	params, err := record.Diff("1", original, modified)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	data, _ := json.Marshal(params.Record)
+	if want := `{"名前":{"value":"変更"}}`; string(data) != want {
+		t.Errorf("期待される差分: %s, 実際: %s", want, data)
+	}
+}
@@ -0,0 +1,399 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// dynamicField は型情報付きのフィールド値
+type dynamicField struct {
+	Type  string          `json:"type,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Dynamic はスキーマを事前に定義しないレコード
+// kintoneが返すtype属性をもとに、フィールドコードを指定して値を取得・設定できる
+// GetRecords[record.Dynamic] のように、型パラメータとしてそのまま使用できる
+//
+// Set系のメソッドで変更したフィールドは記録され、Changesで更新用のフィールド値を取得できる
+type Dynamic struct {
+	fields  map[string]dynamicField
+	changed map[string]bool
+}
+
+// DynamicRow はDynamicのテーブルの行
+// IDを空にした行は新規の行として追加される
+type DynamicRow struct {
+	ID     string
+	Record Dynamic
+}
+
+// UnmarshalJSON はkintoneのレコード形式のJSONから変換する
+func (d *Dynamic) UnmarshalJSON(data []byte) error {
+	var fields map[string]dynamicField
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("レコードの形式が不正です: %w", err)
+	}
+	d.fields = fields
+	d.changed = nil
+	return nil
+}
+
+// MarshalJSON はkintoneのレコード形式のJSONに変換する
+func (d Dynamic) MarshalJSON() ([]byte, error) {
+	if d.fields == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(d.fields)
+}
+
+// Codes はフィールドコードの一覧を昇順で返す
+func (d Dynamic) Codes() []string {
+	codes := make([]string, 0, len(d.fields))
+	for code := range d.fields {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Has はフィールドが存在するかどうかを返す
+func (d Dynamic) Has(code string) bool {
+	_, ok := d.fields[code]
+	return ok
+}
+
+// Type はフィールドタイプを返す（存在しない場合は空文字列）
+func (d Dynamic) Type(code string) string {
+	return d.fields[code].Type
+}
+
+// Raw はフィールド値のJSONを返す（存在しない場合はnil）
+func (d Dynamic) Raw(code string) json.RawMessage {
+	return d.fields[code].Value
+}
+
+// ID はレコードID（$id）を返す
+func (d Dynamic) ID() types.RecordID {
+	id, _ := d.String("$id")
+	return id
+}
+
+// Revision はリビジョン（$revision）を返す
+func (d Dynamic) Revision() types.Revision {
+	revision, _ := d.String("$revision")
+	return revision
+}
+
+// field はフィールドを取得し、フィールドタイプを確認する
+// allowedが空の場合はフィールドタイプを確認しない
+func (d Dynamic) field(code string, allowed ...string) (dynamicField, error) {
+	f, ok := d.fields[code]
+	if !ok {
+		return dynamicField{}, fmt.Errorf("フィールドが存在しません: %s", code)
+	}
+	if len(allowed) > 0 && f.Type != "" && !slices.Contains(allowed, f.Type) {
+		return dynamicField{}, fmt.Errorf("%sフィールドの値は取得できません: %s", f.Type, code)
+	}
+	return f, nil
+}
+
+// decode はフィールド値をvに変換する
+func (d Dynamic) decode(code string, v any, allowed ...string) error {
+	f, err := d.field(code, allowed...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(f.Value, v); err != nil {
+		return fmt.Errorf("フィールド値の変換エラー: %s: %w", code, err)
+	}
+	return nil
+}
+
+// String は文字列として値を返す
+// 文字列・数値・日付・選択肢など、値が文字列のフィールドで使用できる
+func (d Dynamic) String(code string) (string, error) {
+	var s *string
+	if err := d.decode(code, &s); err != nil {
+		return "", err
+	}
+	if s == nil {
+		return "", nil
+	}
+	return *s, nil
+}
+
+// Number は数値として値を返す（未入力の場合は空のDecimal）
+// 数値、計算、$id、$revisionのフィールドで使用できる
+func (d Dynamic) Number(code string) (types.Decimal, error) {
+	var n types.Decimal
+	err := d.decode(code, &n, types.FieldTypeNumber, types.FieldTypeCalc, types.FieldTypeID, types.FieldTypeRevision)
+	return n, err
+}
+
+// Time は日付・時刻・日時の値を返す（未入力の場合はゼロ値）
+// 日付はUTCの0時、時刻は0年1月1日の時刻として返す
+func (d Dynamic) Time(code string) (time.Time, error) {
+	f, err := d.field(code, types.FieldTypeDate, types.FieldTypeTime, types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch f.Type {
+	case types.FieldTypeDate:
+		var v types.Date
+		err = json.Unmarshal(f.Value, &v)
+		return v.Time, wrapDecodeError(code, err)
+	case types.FieldTypeTime:
+		var v types.TimeOfDay
+		err = json.Unmarshal(f.Value, &v)
+		return v.Time, wrapDecodeError(code, err)
+	default:
+		var v types.DateTime
+		err = json.Unmarshal(f.Value, &v)
+		return v.Time, wrapDecodeError(code, err)
+	}
+}
+
+// Strings は文字列の配列として値を返す
+// チェックボックス、複数選択、カテゴリーのフィールドで使用できる
+func (d Dynamic) Strings(code string) (types.Strings, error) {
+	var v types.Strings
+	err := d.decode(code, &v, types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeCategory)
+	return v, err
+}
+
+// Users はユーザー・組織・グループの配列として値を返す
+// ユーザー選択、組織選択、グループ選択、作業者のほか、作成者・更新者（1件の配列）でも使用できる
+func (d Dynamic) Users(code string) (types.Entities, error) {
+	f, err := d.field(code,
+		types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect,
+		types.FieldTypeStatusAssignee, types.FieldTypeCreator, types.FieldTypeModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(f.Value); len(trimmed) > 0 && trimmed[0] == '{' {
+		var entity types.Entity
+		if err := json.Unmarshal(trimmed, &entity); err != nil {
+			return nil, wrapDecodeError(code, err)
+		}
+		return types.Entities{entity}, nil
+	}
+	var v types.Entities
+	err = json.Unmarshal(f.Value, &v)
+	return v, wrapDecodeError(code, err)
+}
+
+// Files は添付ファイルの配列として値を返す
+func (d Dynamic) Files(code string) (types.Files, error) {
+	var v types.Files
+	err := d.decode(code, &v, types.FieldTypeFile)
+	return v, err
+}
+
+// Subtable はテーブルの行を返す
+// 行を変更した場合は、SetSubtableで全行を設定し直す
+func (d Dynamic) Subtable(code string) ([]DynamicRow, error) {
+	var rows []struct {
+		ID    string  `json:"id"`
+		Value Dynamic `json:"value"`
+	}
+	if err := d.decode(code, &rows, types.FieldTypeSubtable); err != nil {
+		return nil, err
+	}
+	result := make([]DynamicRow, len(rows))
+	for i, row := range rows {
+		result[i] = DynamicRow{ID: row.ID, Record: row.Value}
+	}
+	return result, nil
+}
+
+// wrapDecodeError はフィールド値の変換エラーにフィールドコードを付加する
+func wrapDecodeError(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("フィールド値の変換エラー: %s: %w", code, err)
+}
+
+// --- 値の設定 ---
+
+// set はフィールド値を設定し、変更を記録する
+// 既存のフィールドの場合はフィールドタイプを確認する。新しいフィールドはfieldTypeを使う
+func (d *Dynamic) set(code string, value any, fieldType string, allowed ...string) error {
+	f, exists := d.fields[code]
+	if exists && f.Type != "" {
+		if slices.Contains(readOnlyFieldTypes, f.Type) {
+			return fmt.Errorf("%sフィールドには書き込みできません: %s", f.Type, code)
+		}
+		if len(allowed) > 0 && !slices.Contains(allowed, f.Type) {
+			return fmt.Errorf("%sフィールドには設定できない値です: %s", f.Type, code)
+		}
+	} else {
+		f.Type = fieldType
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("フィールド値のJSONエンコードエラー: %s: %w", code, err)
+	}
+	f.Value = data
+
+	if d.fields == nil {
+		d.fields = map[string]dynamicField{}
+	}
+	if d.changed == nil {
+		d.changed = map[string]bool{}
+	}
+	d.fields[code] = f
+	d.changed[code] = true
+	return nil
+}
+
+// Set は任意の値をフィールドに設定する
+// valueはJSONに変換してそのままkintoneに送信される（例: "abc"、[]string{"A"}、types.Date）
+func (d *Dynamic) Set(code string, value any) error {
+	return d.set(code, value, "")
+}
+
+// SetString は文字列の値を設定する
+// 文字列・リンク・ラジオボタン・ドロップダウンのフィールドで使用できる
+func (d *Dynamic) SetString(code, value string) error {
+	return d.set(code, value, "",
+		types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText,
+		types.FieldTypeLink, types.FieldTypeRadioButton, types.FieldTypeDropDown)
+}
+
+// SetNumber は数値の値を設定する（空のDecimalで未入力にする）
+func (d *Dynamic) SetNumber(code string, value types.Decimal) error {
+	return d.set(code, value, types.FieldTypeNumber, types.FieldTypeNumber)
+}
+
+// SetTime は日付・時刻・日時の値を設定する（ゼロ値で未入力にする）
+// フィールドタイプに合わせた形式に変換するため、フィールドタイプが不明なフィールドには使用できない
+func (d *Dynamic) SetTime(code string, value time.Time) error {
+	var v any
+	switch fieldType := d.Type(code); fieldType {
+	case types.FieldTypeDate:
+		if !value.IsZero() {
+			value = time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		v = types.Date{Time: value}
+	case types.FieldTypeTime:
+		v = types.TimeOfDay{Time: value, Valid: !value.IsZero()}
+	case types.FieldTypeDateTime:
+		v = types.DateTime{Time: value}
+	case "":
+		return fmt.Errorf("フィールドタイプが不明なため日時の形式を決められません: %s", code)
+	default:
+		return fmt.Errorf("%sフィールドには日時を設定できません: %s", fieldType, code)
+	}
+	return d.set(code, v, "")
+}
+
+// SetStrings は文字列の配列を設定する
+// チェックボックス、複数選択のフィールドで使用できる
+func (d *Dynamic) SetStrings(code string, values ...string) error {
+	return d.set(code, types.Strings(values), "", types.FieldTypeCheckBox, types.FieldTypeMultiSelect)
+}
+
+// SetUsers はユーザー・組織・グループのコードを設定する
+// ユーザー選択、組織選択、グループ選択のフィールドで使用できる
+func (d *Dynamic) SetUsers(code string, codes ...string) error {
+	entities := make(types.Entities, len(codes))
+	for i, c := range codes {
+		entities[i] = types.Entity{Code: c}
+	}
+	return d.set(code, entities, "",
+		types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect)
+}
+
+// SetFiles は添付ファイルを設定する
+// fileKeysにはアップロードで取得したファイルキーを指定する
+func (d *Dynamic) SetFiles(code string, fileKeys ...string) error {
+	files := make(types.Files, len(fileKeys))
+	for i, key := range fileKeys {
+		files[i] = types.File{FileKey: key}
+	}
+	return d.set(code, files, types.FieldTypeFile, types.FieldTypeFile)
+}
+
+// SetSubtable はテーブルの行を設定する
+// kintoneの仕様により、指定しなかった既存の行は削除される
+func (d *Dynamic) SetSubtable(code string, rows []DynamicRow) error {
+	value := make([]struct {
+		ID    string  `json:"id,omitempty"`
+		Value Dynamic `json:"value"`
+	}, len(rows))
+	for i, row := range rows {
+		value[i].ID = row.ID
+		value[i].Value = row.Record
+	}
+	return d.set(code, value, types.FieldTypeSubtable, types.FieldTypeSubtable)
+}
+
+// Changes はSet系のメソッドで変更したフィールドを更新用のフィールド値として返す
+// テーブル内の書き込みできないフィールドは除外される
+func (d Dynamic) Changes() (map[string]types.FieldValue, error) {
+	result := make(map[string]types.FieldValue, len(d.changed))
+	for code := range d.changed {
+		data, err := json.Marshal(d.fields[code])
+		if err != nil {
+			return nil, fmt.Errorf("フィールド値のJSONエンコードエラー: %s: %w", code, err)
+		}
+		value, writable, err := writableValue(code, data)
+		if err != nil {
+			return nil, err
+		}
+		if writable {
+			result[code] = types.FieldValue{Value: value}
+		}
+	}
+	return result, nil
+}
+
+// HasChanges は変更したフィールドがあるかどうかを返す
+func (d Dynamic) HasChanges() bool {
+	return len(d.changed) > 0
+}
+
+// ResetChanges は変更の記録を消去する（値はそのまま）
+func (d *Dynamic) ResetChanges() {
+	d.changed = nil
+}
+
+// --- 構造体との変換 ---
+
+// ToDynamic はレコードの構造体をDynamicに変換する
+// 構造体にtype属性がない場合、変換後のフィールドタイプは空になる
+func ToDynamic[T any](rec T) (Dynamic, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return Dynamic{}, fmt.Errorf("レコードのJSONエンコードエラー: %w", err)
+	}
+	var d Dynamic
+	if err := json.Unmarshal(data, &d); err != nil {
+		return Dynamic{}, err
+	}
+	return d, nil
+}
+
+// FromDynamic はDynamicをレコードの構造体に変換する
+func FromDynamic[T any](d Dynamic) (T, error) {
+	var rec T
+	data, err := json.Marshal(d)
+	if err != nil {
+		return rec, fmt.Errorf("レコードのJSONエンコードエラー: %w", err)
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("レコードの変換エラー: %w", err)
+	}
+	return rec, nil
+}
@@ -0,0 +1,209 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// dynamicRecordJSON はテスト用のレコード
+const dynamicRecordJSON = `{
+	"$id": {"type": "__ID__", "value": "7"},
+	"$revision": {"type": "__REVISION__", "value": "3"},
+	"作成者": {"type": "CREATOR", "value": {"code": "user1", "name": "ユーザー1"}},
+	"名前": {"type": "SINGLE_LINE_TEXT", "value": "テスト"},
+	"金額": {"type": "NUMBER", "value": "1500.5"},
+	"合計": {"type": "CALC", "value": "3001"},
+	"期限": {"type": "DATE", "value": "2024-03-31"},
+	"開始": {"type": "TIME", "value": "09:30"},
+	"日時": {"type": "DATETIME", "value": null},
+	"タグ": {"type": "CHECK_BOX", "value": ["A"]},
+	"担当者": {"type": "USER_SELECT", "value": [{"code": "user2", "name": "ユーザー2"}]},
+	"添付": {"type": "FILE", "value": [{"fileKey": "k1", "name": "a.txt"}]},
+	"明細": {"type": "SUBTABLE", "value": [
+		{"id": "100", "value": {
+			"品名": {"type": "SINGLE_LINE_TEXT", "value": "りんご"},
+			"小計": {"type": "CALC", "value": "100"}
+		}}
+	]}
+}`
+
+func newDynamicRecord(t *testing.T) record.Dynamic {
+	t.Helper()
+	var d record.Dynamic
+	if err := json.Unmarshal([]byte(dynamicRecordJSON), &d); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	return d
+}
+
+func TestDynamicAccessors(t *testing.T) {
+	d := newDynamicRecord(t)
+
+	if d.ID() != "7" || d.Revision() != "3" {
+		t.Errorf("期待されるID/revision: 7/3, 実際: %s/%s", d.ID(), d.Revision())
+	}
+	if d.Type("金額") != types.FieldTypeNumber {
+		t.Errorf("期待されるフィールドタイプ: NUMBER, 実際: %s", d.Type("金額"))
+	}
+	if s, _ := d.String("名前"); s != "テスト" {
+		t.Errorf("期待される名前: テスト, 実際: %s", s)
+	}
+	if n, _ := d.Number("金額"); n != "1500.5" {
+		t.Errorf("期待される金額: 1500.5, 実際: %s", n)
+	}
+	if due, _ := d.Time("期限"); !due.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("期待される期限: 2024-03-31, 実際: %v", due)
+	}
+	if start, _ := d.Time("開始"); start.Hour() != 9 || start.Minute() != 30 {
+		t.Errorf("期待される開始: 09:30, 実際: %v", start)
+	}
+	if dt, err := d.Time("日時"); err != nil || !dt.IsZero() {
+		t.Errorf("未入力の日時がゼロ値にならない: %v, %v", dt, err)
+	}
+	if tags, _ := d.Strings("タグ"); len(tags) != 1 || tags[0] != "A" {
+		t.Errorf("期待されるタグ: [A], 実際: %v", tags)
+	}
+	if users, _ := d.Users("担当者"); len(users) != 1 || users[0].Code != "user2" {
+		t.Errorf("期待される担当者: user2, 実際: %v", users)
+	}
+	if creator, _ := d.Users("作成者"); len(creator) != 1 || creator[0].Code != "user1" {
+		t.Errorf("期待される作成者: user1, 実際: %v", creator)
+	}
+	if files, _ := d.Files("添付"); len(files) != 1 || files[0].FileKey != "k1" {
+		t.Errorf("期待される添付: k1, 実際: %v", files)
+	}
+	rows, err := d.Subtable("明細")
+	if err != nil || len(rows) != 1 || rows[0].ID != "100" {
+		t.Fatalf("期待される行ID: 100, 実際: %v, %v", rows, err)
+	}
+	if product, _ := rows[0].Record.String("品名"); product != "りんご" {
+		t.Errorf("期待される品名: りんご, 実際: %s", product)
+	}
+
+	if _, err := d.Number("名前"); err == nil {
+		t.Error("文字列フィールドを数値として取得できてしまう")
+	}
+	if _, err := d.String("存在しない"); err == nil {
+		t.Error("存在しないフィールドでエラーが発生しない")
+	}
+}
+
+func TestDynamicChanges(t *testing.T) {
+	d := newDynamicRecord(t)
+	if d.HasChanges() {
+		t.Error("変更していないのに変更ありになっている")
+	}
+
+	if err := d.SetString("名前", "変更後"); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if err := d.SetTime("期限", time.Date(2024, 4, 1, 15, 0, 0, 0, time.Local)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if err := d.SetUsers("担当者", "user3"); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	rows, _ := d.Subtable("明細")
+	rows[0].Record.SetString("品名", "みかん")
+	rows = append(rows, record.DynamicRow{})
+	rows[1].Record.SetString("品名", "ぶどう")
+	if err := d.SetSubtable("明細", rows); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	if err := d.SetString("合計", "1"); err == nil {
+		t.Error("計算フィールドに書き込めてしまう")
+	}
+	if err := d.SetString("金額", "1"); err == nil {
+		t.Error("数値フィールドに文字列を設定できてしまう")
+	}
+
+	changes, err := d.Changes()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	data, _ := json.Marshal(changes)
+	want := `{"名前":{"value":"変更後"},"担当者":{"value":[{"code":"user3"}]},"明細":{"value":[{"id":"100","value":{"品名":{"value":"みかん"}}},{"value":{"品名":{"value":"ぶどう"}}}]},"期限":{"value":"2024-04-01"}}`
+	if string(data) != want {
+		t.Errorf("期待される更新内容: %s, 実際: %s", want, data)
+	}
+
+	d.ResetChanges()
+	if d.HasChanges() {
+		t.Error("ResetChanges後も変更ありになっている")
+	}
+	if s, _ := d.String("名前"); s != "変更後" {
+		t.Errorf("期待される名前: 変更後, 実際: %s", s)
+	}
+}
+
+func TestDynamicStructConversion(t *testing.T) {
+	d := newDynamicRecord(t)
+
+	type partial struct {
+		Name   types.SingleLineTextField `json:"名前"`
+		Amount types.NumberField         `json:"金額"`
+		Due    types.DateField           `json:"期限"`
+	}
+	rec, err := record.FromDynamic[partial](d)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if rec.Name.Value != "テスト" || rec.Amount.Value != "1500.5" || rec.Due.Value.String() != "2024-03-31" {
+		t.Errorf("構造体への変換結果が不正: %+v", rec)
+	}
+
+	back, err := record.ToDynamic(rec)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if codes := back.Codes(); len(codes) != 3 {
+		t.Errorf("期待されるフィールド数: 3, 実際: %v", codes)
+	}
+	if n, _ := back.Number("金額"); n != "1500.5" {
+		t.Errorf("期待される金額: 1500.5, 実際: %s", n)
+	}
+}
+
+func TestGetRecordsDynamic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"records": [` + dynamicRecordJSON + `], "totalCount": null}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	result, err := record.GetRecords[record.Dynamic](ctx, client, record.GetRecordsParams{App: "1"})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result.Records) != 1 || result.Records[0].ID() != "7" {
+		t.Fatalf("期待されるレコード: $id=7, 実際: %v", result.Records)
+	}
+
+	// 取得したレコードをそのまま追加に使える（書き込みできないフィールドは除外される）
+	fields, err := record.ToFieldValues(result.Records[0])
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	for _, code := range []string{"$id", "作成者", "合計"} {
+		if _, ok := fields[code]; ok {
+			t.Errorf("除外されるはずのフィールドが含まれている: %s", code)
+		}
+	}
+	if _, ok := fields["名前"]; !ok {
+		t.Error("名前が含まれていない")
+	}
+}
@@ -0,0 +1,220 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// getAllRecordsLimit は全件取得時に1回で取得するレコード数
+const getAllRecordsLimit = 500
+
+// AllRecords は全レコードを1件ずつ返すイテレータを返す
+// レコードは500件ずつ必要になった時点で取得するため、全件をメモリに保持しない
+// 取得方法はGetAllRecordsと同じ（OrderBy省略時は$idによるシーク、指定時はカーソルAPI）
+// エラーが発生した場合はエラーを返して終了する。途中でbreakした場合、開いているカーソルは削除される
+func AllRecords[T any](ctx context.Context, c *Client, params GetAllRecordsParams) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range AllRecordPages[T](ctx, c, params) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, rec := range page {
+				if !yield(rec, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllRecordPages は全レコードを最大500件のページ単位で返すイテレータを返す
+// 各ページを取得する前にcontextのキャンセルを確認する
+func AllRecordPages[T any](ctx context.Context, c *Client, params GetAllRecordsParams) iter.Seq2[[]T, error] {
+	if params.OrderBy == "" {
+		return recordPagesWithID[T](ctx, c, params)
+	}
+	return recordPagesWithCursor[T](ctx, c, params)
+}
+
+// recordIDOnly はレコードから$idのみを取り出すための構造体
+type recordIDOnly struct {
+	ID struct {
+		Value string `json:"value"`
+	} `json:"$id"`
+}
+
+// fieldsWithID はフィールド指定がある場合に$idを追加する
+func fieldsWithID(fields []string) []string {
+	if len(fields) == 0 || slices.Contains(fields, "$id") {
+		return fields
+	}
+	return append(slices.Clone(fields), "$id")
+}
+
+// seekQuery は$idによるシーク用のクエリを構築する
+func seekQuery(condition string, lastID string) string {
+	query := ""
+	if condition != "" {
+		query = "(" + condition + ") and "
+	}
+	return query + fmt.Sprintf("$id > %s order by $id asc limit %d", lastID, getAllRecordsLimit)
+}
+
+// recordPagesWithID は$idによるシークでページを取得するイテレータを返す
+func recordPagesWithID[T any](ctx context.Context, c *Client, params GetAllRecordsParams) iter.Seq2[[]T, error] {
+	return recordPagesAfterID[T](ctx, c, params, "0")
+}
+
+// recordPagesAfterID は$idがlastIDより大きいレコードを、$idによるシークでページ単位で取得するイテレータを返す
+func recordPagesAfterID[T any](ctx context.Context, c *Client, params GetAllRecordsParams, lastID string) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		fields := fieldsWithID(params.Fields)
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			result, err := GetRecords[json.RawMessage](ctx, c, GetRecordsParams{
+				App:    params.App,
+				Fields: fields,
+				Query:  seekQuery(params.Condition, lastID),
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page := make([]T, len(result.Records))
+			for i, raw := range result.Records {
+				if err := json.Unmarshal(raw, &page[i]); err != nil {
+					yield(nil, fmt.Errorf("レスポンス解析エラー: %w", err))
+					return
+				}
+			}
+
+			if len(page) > 0 && !yield(page, nil) {
+				return
+			}
+
+			// 取得件数が上限未満なら終了
+			if len(result.Records) < getAllRecordsLimit {
+				return
+			}
+
+			var last recordIDOnly
+			if err := json.Unmarshal(result.Records[len(result.Records)-1], &last); err != nil {
+				yield(nil, fmt.Errorf("レスポンス解析エラー: %w", err))
+				return
+			}
+			if last.ID.Value == "" {
+				yield(nil, fmt.Errorf("レコードに$idが含まれていません"))
+				return
+			}
+			lastID = last.ID.Value
+		}
+	}
+}
+
+// recordPagesWithCursor はカーソルAPIでページを取得するイテレータを返す
+// 最後まで取得せずに終了した場合（エラー、キャンセル、break）はカーソルを削除する
+func recordPagesWithCursor[T any](ctx context.Context, c *Client, params GetAllRecordsParams) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		query := params.Condition
+		if query != "" {
+			query += " "
+		}
+		query += "order by " + params.OrderBy
+
+		cursor, err := c.CreateCursor(ctx, CreateCursorParams{
+			App:    params.App,
+			Fields: params.Fields,
+			Query:  query,
+			Size:   getAllRecordsLimit,
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// 最後まで取得するとカーソルは自動的に削除される
+		completed := false
+		defer func() {
+			if !completed {
+				// キャンセル済みのcontextでも削除できるようにする
+				c.DeleteCursor(context.WithoutCancel(ctx), DeleteCursorParams{ID: cursor.ID})
+			}
+		}()
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			result, err := GetRecordsByCursor[T](ctx, c, GetRecordsByCursorParams{ID: cursor.ID})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			completed = !result.Next
+
+			if len(result.Records) > 0 && !yield(result.Records, nil) {
+				return
+			}
+			if completed {
+				return
+			}
+		}
+	}
+}
+
+// getRecordCommentsLimit はコメント取得時に1回で取得するコメント数（APIの上限）
+const getRecordCommentsLimit = 10
+
+// AllRecordComments はレコードの全コメントを1件ずつ返すイテレータを返す
+// コメントは10件ずつ必要になった時点で取得する。Orderは"asc"（古い順）または"desc"（新しい順、省略時）
+// 取得中に追加・削除されたコメントは、重複または欠落する可能性がある
+func (c *Client) AllRecordComments(ctx context.Context, params AllRecordCommentsParams) iter.Seq2[Comment, error] {
+	return func(yield func(Comment, error) bool) {
+		for offset := 0; ; {
+			if err := ctx.Err(); err != nil {
+				yield(Comment{}, err)
+				return
+			}
+
+			result, err := c.GetRecordComments(ctx, GetRecordCommentsParams{
+				App:    params.App,
+				Record: params.Record,
+				Order:  params.Order,
+				Offset: offset,
+				Limit:  getRecordCommentsLimit,
+			})
+			if err != nil {
+				yield(Comment{}, err)
+				return
+			}
+			for _, comment := range result.Comments {
+				if !yield(comment, nil) {
+					return
+				}
+			}
+
+			// 古い順の場合はnewer、新しい順の場合はolderが残りのコメントの有無を表す
+			hasMore := result.Older
+			if params.Order == "asc" {
+				hasMore = result.Newer
+			}
+			if !hasMore || len(result.Comments) == 0 {
+				return
+			}
+			offset += len(result.Comments)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+func TestAllRecords(t *testing.T) {
+	const total = 1200
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+
+		m := seekPattern.FindStringSubmatch(query)
+		if m == nil {
+			t.Fatalf("シーク用のクエリではない: %s", query)
+		}
+		lastID, _ := strconv.Atoi(m[1])
+		limit, _ := strconv.Atoi(m[2])
+
+		records := []map[string]any{}
+		for id := lastID + 1; id <= total && len(records) < limit; id++ {
+			records = append(records, map[string]any{
+				"$id": map[string]string{"value": strconv.Itoa(id)},
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	count := 0
+	for rec, err := range record.AllRecords[TestRecord](ctx, client, record.GetAllRecordsParams{App: "1"}) {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		count++
+		if rec.ID.Value != strconv.Itoa(count) {
+			t.Fatalf("期待されるID: %d, 実際: %s", count, rec.ID.Value)
+		}
+	}
+
+	if count != 1200 {
+		t.Errorf("期待されるレコード数: 1200, 実際: %d", count)
+	}
+	if callCount != 3 {
+		t.Errorf("期待されるAPI呼び出し回数: 3, 実際: %d", callCount)
+	}
+}
+
+func TestAllRecordsBreakStopsFetching(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		records := make([]map[string]any, 500)
+		for i := range records {
+			records[i] = map[string]any{"$id": map[string]string{"value": strconv.Itoa(i + 1)}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	count := 0
+	for _, err := range record.AllRecords[TestRecord](ctx, client, record.GetAllRecordsParams{App: "1"}) {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+
+	if callCount != 1 {
+		t.Errorf("期待されるAPI呼び出し回数: 1, 実際: %d", callCount)
+	}
+}
+
+func TestAllRecordPagesContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+		m := seekPattern.FindStringSubmatch(query)
+		if m == nil {
+			t.Fatalf("シーク用のクエリではない: %s", query)
+		}
+		lastID, _ := strconv.Atoi(m[1])
+
+		records := make([]map[string]any, 500)
+		for i := range records {
+			records[i] = map[string]any{"$id": map[string]string{"value": strconv.Itoa(lastID + i + 1)}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	pages := 0
+	var lastErr error
+	for _, err := range record.AllRecordPages[TestRecord](ctx, client, record.GetAllRecordsParams{App: "1"}) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		pages++
+		if pages == 2 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("期待されるエラー: context.Canceled, 実際: %v", lastErr)
+	}
+	if pages != 2 {
+		t.Errorf("期待されるページ数: 2, 実際: %d", pages)
+	}
+}
+
+func TestAllRecordPagesCursorDeletedOnBreak(t *testing.T) {
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "POST":
+			json.NewEncoder(w).Encode(map[string]any{"id": "cursor-1", "totalCount": "5000"})
+		case "GET":
+			records := make([]map[string]any, 500)
+			for i := range records {
+				records[i] = map[string]any{"$id": map[string]string{"value": strconv.Itoa(i + 1)}}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records, "next": true})
+		case "DELETE":
+			deleted = true
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	for page, err := range record.AllRecordPages[TestRecord](ctx, client, record.GetAllRecordsParams{
+		App:     "1",
+		OrderBy: "名前 asc",
+	}) {
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if len(page) != 500 {
+			t.Errorf("期待されるページのレコード数: 500, 実際: %d", len(page))
+		}
+		break
+	}
+
+	if !deleted {
+		t.Error("breakした場合にカーソルが削除されていない")
+	}
+}
+
+func TestAllRecordComments(t *testing.T) {
+	for _, order := range []string{"asc", "desc"} {
+		t.Run(order, func(t *testing.T) {
+			// コメントIDは古い順に1からの連番
+			const total = 25
+			callCount := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				callCount++
+				var reqBody struct {
+					Order  string `json:"order"`
+					Offset int    `json:"offset"`
+					Limit  int    `json:"limit"`
+				}
+				json.NewDecoder(r.Body).Decode(&reqBody)
+				if reqBody.Limit > 10 {
+					t.Errorf("limitが上限を超えている: %d", reqBody.Limit)
+				}
+
+				comments := []map[string]any{}
+				for i := reqBody.Offset; i < min(reqBody.Offset+reqBody.Limit, total); i++ {
+					id := total - i
+					if reqBody.Order == "asc" {
+						id = i + 1
+					}
+					comments = append(comments, map[string]any{"id": strconv.Itoa(id), "text": "コメント"})
+				}
+				hasMore := reqBody.Offset+reqBody.Limit < total
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]any{
+					"comments": comments,
+					"older":    reqBody.Order != "asc" && hasMore,
+					"newer":    reqBody.Order == "asc" && hasMore,
+				})
+			}))
+			defer server.Close()
+
+			client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+			var ids []string
+			for comment, err := range client.AllRecordComments(context.Background(), record.AllRecordCommentsParams{
+				App:    "1",
+				Record: "1",
+				Order:  order,
+			}) {
+				if err != nil {
+					t.Fatalf("エラーが発生: %v", err)
+				}
+				ids = append(ids, comment.ID)
+			}
+
+			if len(ids) != 25 || callCount != 3 {
+				t.Fatalf("期待される件数/呼び出し回数: 25/3, 実際: %d/%d", len(ids), callCount)
+			}
+			first, last := "1", "25"
+			if order == "desc" {
+				first, last = last, first
+			}
+			if ids[0] != first || ids[24] != last {
+				t.Errorf("期待される順序: %s...%s, 実際: %s...%s", first, last, ids[0], ids[24])
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// Modifyの既定値
+const (
+	defaultModifyMaxRetries = 3
+	defaultModifyBackoff    = 100 * time.Millisecond
+)
+
+// ModifyOptions はModifyWithOptionsの設定
+type ModifyOptions struct {
+	MaxRetries int           // リビジョンの競合時の再試行回数（0の場合は3、負の場合は再試行しない）
+	Backoff    time.Duration // 最初の再試行までの待機時間（0の場合は100ms）。再試行ごとに2倍にする
+	MaxBackoff time.Duration // 待機時間の上限（0の場合は上限なし）
+}
+
+// ConflictError はリビジョンの競合が再試行後も解消しなかったエラー
+type ConflictError struct {
+	App      types.AppID
+	ID       types.RecordID
+	Attempts int   // 更新を試みた回数
+	Err      error // 最後の競合エラー（*error.KintoneRestAPIError）
+}
+
+// Error はerrorインターフェースを実装
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("レコードの更新が競合しました（アプリ %s、レコード %s、%d回試行）: %v", e.App, e.ID, e.Attempts, e.Err)
+}
+
+// Unwrap は原因のエラーを返す
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Modify はレコードを取得してfnで変更し、変更されたフィールドのみを取得時のリビジョンで更新する
+// 他の更新とリビジョンが競合した場合は、レコードを再取得してfnからやり直す（最大3回）
+// fnがエラーを返した場合は更新せずにそのエラーを返す。fnは再試行のたびに呼ばれる
+// 変更がない場合は更新せず、取得時のリビジョンを返す
+func Modify[T any](ctx context.Context, c *Client, app types.AppID, id types.RecordID, fn func(*T) error) (*UpdateRecordResult, error) {
+	return ModifyWithOptions(ctx, c, app, id, ModifyOptions{}, fn)
+}
+
+// ModifyWithOptions は再試行の設定を指定してModifyを実行する
+// 再試行しても競合が解消しない場合は*ConflictErrorを返す
+func ModifyWithOptions[T any](ctx context.Context, c *Client, app types.AppID, id types.RecordID, opts ModifyOptions, fn func(*T) error) (*UpdateRecordResult, error) {
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultModifyMaxRetries
+	}
+	maxRetries = max(maxRetries, 0)
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = defaultModifyBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := modifyOnce(ctx, c, app, id, fn)
+		if err == nil {
+			return result, nil
+		}
+		if !kintoneError.HasCode(err, kintoneError.CodeRevisionConflict) {
+			return nil, err
+		}
+		if attempt > maxRetries {
+			return nil, &ConflictError{App: app, ID: id, Attempts: attempt, Err: err}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if opts.MaxBackoff > 0 {
+			backoff = min(backoff, opts.MaxBackoff)
+		}
+	}
+}
+
+// modifyOnce はレコードの取得・変更・更新を1回実行する
+func modifyOnce[T any](ctx context.Context, c *Client, app types.AppID, id types.RecordID, fn func(*T) error) (*UpdateRecordResult, error) {
+	// 構造体に$revisionがなくてもリビジョンを得られるよう、Dynamicで取得してから変換する
+	fetched, err := GetRecord[Dynamic](ctx, c, GetRecordParams{App: app, ID: id})
+	if err != nil {
+		return nil, err
+	}
+	original, err := FromDynamic[T](fetched)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := FromDynamic[T](fetched)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(&modified); err != nil {
+		return nil, err
+	}
+
+	params, err := Diff(app, original, modified)
+	if err != nil {
+		return nil, err
+	}
+	revision := fetched.Revision()
+	if !params.HasChanges() {
+		return &UpdateRecordResult{Revision: revision}, nil
+	}
+	params.ID = id
+	params.Revision = &revision
+
+	return c.UpdateRecord(ctx, params)
+}
@@ -0,0 +1,187 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// ModifyRecord はテスト用のレコード構造体（$revisionを含まない）
+type ModifyRecord struct {
+	Name  types.SingleLineTextField `json:"名前"`
+	Count types.NumberField         `json:"回数"`
+}
+
+func increment(rec *ModifyRecord) error {
+	n, err := rec.Count.Value.Int64()
+	if err != nil {
+		return err
+	}
+	rec.Count.Value = types.DecimalFromInt(n + 1)
+	return nil
+}
+
+func TestModify(t *testing.T) {
+	var lastBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(map[string]any{"record": map[string]any{
+				"$id":       map[string]any{"type": "__ID__", "value": "1"},
+				"$revision": map[string]any{"type": "__REVISION__", "value": "5"},
+				"名前":        map[string]any{"type": "SINGLE_LINE_TEXT", "value": "テスト"},
+				"回数":        map[string]any{"type": "NUMBER", "value": "1"},
+			}})
+		case "PUT":
+			json.NewDecoder(r.Body).Decode(&lastBody)
+			json.NewEncoder(w).Encode(map[string]any{"revision": "6"})
+		}
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	result, err := record.Modify(context.Background(), client, "1", "1", increment)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Revision != "6" {
+		t.Errorf("期待されるrevision: 6, 実際: %s", result.Revision)
+	}
+
+	data, _ := json.Marshal(lastBody["record"])
+	if want := `{"回数":{"value":"2"}}`; string(data) != want {
+		t.Errorf("期待される更新内容: %s, 実際: %s", want, data)
+	}
+	if lastBody["revision"] != "5" {
+		t.Errorf("期待されるrevision: 5, 実際: %v", lastBody["revision"])
+	}
+}
+
+func TestModifyRetriesOnConflict(t *testing.T) {
+	// 2回目までの更新は、他のユーザーが先に更新したものとして競合させる
+	var gets, puts int
+	var lastBody map[string]any
+	revision, count := 5, 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			gets++
+			json.NewEncoder(w).Encode(map[string]any{"record": map[string]any{
+				"$id":       map[string]any{"type": "__ID__", "value": "1"},
+				"$revision": map[string]any{"type": "__REVISION__", "value": strconv.Itoa(revision)},
+				"回数":        map[string]any{"type": "NUMBER", "value": strconv.Itoa(count)},
+			}})
+		case "PUT":
+			puts++
+			lastBody = nil
+			json.NewDecoder(r.Body).Decode(&lastBody)
+			if puts <= 2 {
+				revision++
+				count += 10
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]any{"code": "GAIA_CO02", "id": "conflict", "message": "指定したリビジョンは最新ではありません。"})
+				return
+			}
+			revision++
+			json.NewEncoder(w).Encode(map[string]any{"revision": strconv.Itoa(revision)})
+		}
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	_, err := record.ModifyWithOptions(context.Background(), client, "1", "1", record.ModifyOptions{Backoff: time.Millisecond}, increment)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if gets != 3 || puts != 3 {
+		t.Errorf("期待される取得/更新回数: 3/3, 実際: %d/%d", gets, puts)
+	}
+	// 他のユーザーの更新（+10を2回）を反映した値から更新される
+	data, _ := json.Marshal(lastBody["record"])
+	if want := `{"回数":{"value":"22"}}`; string(data) != want {
+		t.Errorf("期待される更新内容: %s, 実際: %s", want, data)
+	}
+}
+
+func TestModifyConflictError(t *testing.T) {
+	puts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(map[string]any{"record": map[string]any{
+				"$id":       map[string]any{"type": "__ID__", "value": "1"},
+				"$revision": map[string]any{"type": "__REVISION__", "value": "5"},
+				"回数":        map[string]any{"type": "NUMBER", "value": "1"},
+			}})
+		case "PUT":
+			puts++
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]any{"code": "GAIA_CO02", "id": "conflict", "message": "指定したリビジョンは最新ではありません。"})
+		}
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	_, err := record.ModifyWithOptions(context.Background(), client, "1", "1", record.ModifyOptions{MaxRetries: 2, Backoff: time.Millisecond}, increment)
+
+	var conflictErr *record.ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("ConflictErrorが返るはずが: %v", err)
+	}
+	if conflictErr.Attempts != 3 || puts != 3 {
+		t.Errorf("期待される試行回数: 3, 実際: %d (更新 %d回)", conflictErr.Attempts, puts)
+	}
+	if !kintoneError.HasCode(err, kintoneError.CodeRevisionConflict) {
+		t.Errorf("原因のエラーコードが判定できない: %v", err)
+	}
+}
+
+func TestModifyNoChangeOrError(t *testing.T) {
+	puts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(map[string]any{"record": map[string]any{
+				"$id":       map[string]any{"type": "__ID__", "value": "1"},
+				"$revision": map[string]any{"type": "__REVISION__", "value": "5"},
+				"回数":        map[string]any{"type": "NUMBER", "value": "1"},
+			}})
+		case "PUT":
+			puts++
+			json.NewEncoder(w).Encode(map[string]any{"revision": "6"})
+		}
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	ctx := context.Background()
+
+	result, err := record.Modify(ctx, client, "1", "1", func(rec *ModifyRecord) error { return nil })
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Revision != "5" || puts != 0 {
+		t.Errorf("変更がない場合は更新しないはずが: revision=%s, 更新 %d回", result.Revision, puts)
+	}
+
+	errAbort := errors.New("中止")
+	_, err = record.Modify(ctx, client, "1", "1", func(rec *ModifyRecord) error { return errAbort })
+	if !errors.Is(err, errAbort) || puts != 0 {
+		t.Errorf("fnのエラーがそのまま返るはずが: %v (更新 %d回)", err, puts)
+	}
+}
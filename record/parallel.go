@@ -0,0 +1,227 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"sync"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// defaultParallelPartitions はParallelRecordsの分割数の既定値
+const defaultParallelPartitions = 4
+
+// idRange は$idの範囲（両端を含む）
+type idRange struct {
+	first, last int64
+}
+
+// parallelPage はParallelRecordsで分割ごとに取得したページ
+type parallelPage[T any] struct {
+	records []T
+	done    bool // 分割の取得が完了した
+	err     error
+}
+
+// ParallelRecords は条件に一致する全レコードを、$idの範囲を分割して並行に取得し、1件ずつ返すイテレータを返す
+//
+// 開始時点の$idの最小値と最大値を取得してPartitions個の範囲に分割し、各範囲を$idによるシークで取得する。
+// 同時に取得する範囲の数（リクエスト数）はConcurrencyまでに制限する。
+// Orderedの場合は範囲の順に返すため、全体として$idの昇順になる（先の範囲は1ページまで先読みして待つ）。
+// Orderedでない場合は、取得したページから順に返す
+//
+// 開始後に追加された、$idが最大値より大きいレコードは含まれない。
+// $idに欠番が多い場合は、範囲によってレコード数が偏ることがある。
+// エラーが発生した場合はエラーを返して終了する。途中でbreakした場合やcontextがキャンセルされた場合は、実行中の取得を中止して終了する
+func ParallelRecords[T any](ctx context.Context, c *Client, params ParallelRecordsParams) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		partitions := params.Partitions
+		if partitions <= 0 {
+			partitions = defaultParallelPartitions
+		}
+		concurrency := params.Concurrency
+		if concurrency <= 0 {
+			concurrency = partitions
+		}
+
+		span, total, err := recordIDSpan(ctx, c, params.App, params.Condition)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if total == 0 {
+			return
+		}
+		ranges := splitIDRange(span, partitions)
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		// Orderedの場合は範囲ごと、それ以外は全範囲で共通のチャネルに送る
+		outputs := make([]chan parallelPage[T], len(ranges))
+		if params.Ordered {
+			for i := range outputs {
+				outputs[i] = make(chan parallelPage[T], 1)
+			}
+		} else {
+			shared := make(chan parallelPage[T], concurrency)
+			for i := range outputs {
+				outputs[i] = shared
+			}
+		}
+
+		jobs := make(chan int, len(ranges))
+		for i := range ranges {
+			jobs <- i
+		}
+		close(jobs)
+		for range min(concurrency, len(ranges)) {
+			wg.Go(func() {
+				for i := range jobs {
+					if !fetchPartition(ctx, c, params, ranges[i], outputs[i]) {
+						return
+					}
+				}
+			})
+		}
+
+		progress := ParallelProgress{Total: total, Partitions: len(ranges)}
+		receive := func(i int) (parallelPage[T], bool) {
+			select {
+			case page := <-outputs[i]:
+				return page, true
+			case <-ctx.Done():
+				return parallelPage[T]{}, false
+			}
+		}
+		// handle はページを返し、続ける場合はtrueを返す
+		handle := func(page parallelPage[T]) bool {
+			if page.err != nil {
+				yield(zero, page.err)
+				return false
+			}
+			if page.done {
+				progress.Completed++
+			}
+			progress.Fetched += len(page.records)
+			if params.Progress != nil {
+				params.Progress(progress)
+			}
+			for _, rec := range page.records {
+				if !yield(rec, nil) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for next := 0; progress.Completed < len(ranges); {
+			if params.Ordered {
+				next = progress.Completed
+			}
+			page, ok := receive(next)
+			if !ok {
+				yield(zero, ctx.Err())
+				return
+			}
+			if !handle(page) {
+				return
+			}
+		}
+	}
+}
+
+// fetchPartition は$idの範囲のレコードを取得してoutに送る
+// 取得を続けられない場合（エラー、キャンセル）はfalseを返す
+func fetchPartition[T any](ctx context.Context, c *Client, params ParallelRecordsParams, r idRange, out chan<- parallelPage[T]) bool {
+	send := func(page parallelPage[T]) bool {
+		select {
+		case out <- page:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	condition := fmt.Sprintf("$id <= %d", r.last)
+	if params.Condition != "" {
+		condition = "(" + params.Condition + ") and " + condition
+	}
+	for page, err := range recordPagesAfterID[T](ctx, c, GetAllRecordsParams{
+		App:       params.App,
+		Fields:    params.Fields,
+		Condition: condition,
+	}, strconv.FormatInt(r.first-1, 10)) {
+		if err != nil {
+			send(parallelPage[T]{err: err})
+			return false
+		}
+		if !send(parallelPage[T]{records: page}) {
+			return false
+		}
+	}
+	return send(parallelPage[T]{done: true})
+}
+
+// recordIDSpan は条件に一致するレコードの$idの最小値・最大値とレコード数を返す
+func recordIDSpan(ctx context.Context, c *Client, app types.AppID, condition string) (idRange, int, error) {
+	first, total, err := edgeRecordID(ctx, c, app, condition, "asc")
+	if err != nil || total == 0 {
+		return idRange{}, 0, err
+	}
+	last, _, err := edgeRecordID(ctx, c, app, condition, "desc")
+	if err != nil {
+		return idRange{}, 0, err
+	}
+	return idRange{first: first, last: last}, total, nil
+}
+
+// edgeRecordID はorder（asc / desc）で並べた先頭のレコードの$idとレコード数を返す
+func edgeRecordID(ctx context.Context, c *Client, app types.AppID, condition, order string) (int64, int, error) {
+	query := condition
+	if query != "" {
+		query += " "
+	}
+	query += "order by $id " + order + " limit 1"
+
+	result, err := GetRecords[recordIDOnly](ctx, c, GetRecordsParams{
+		App:        app,
+		Fields:     []string{"$id"},
+		Query:      query,
+		TotalCount: true,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(result.Records) == 0 {
+		return 0, 0, nil
+	}
+
+	id, err := strconv.ParseInt(result.Records[0].ID.Value, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("レスポンス解析エラー: $idが数値ではありません: %q", result.Records[0].ID.Value)
+	}
+	total := 0
+	if result.TotalCount != nil {
+		total, _ = strconv.Atoi(*result.TotalCount)
+	}
+	return id, max(total, 1), nil
+}
+
+// splitIDRange は$idの範囲を最大n個の範囲に均等に分割する
+func splitIDRange(r idRange, n int) []idRange {
+	span := r.last - r.first + 1
+	size := (span + int64(n) - 1) / int64(n)
+	var ranges []idRange
+	for first := r.first; first <= r.last; first += size {
+		ranges = append(ranges, idRange{first: first, last: min(first+size-1, r.last)})
+	}
+	return ranges
+}
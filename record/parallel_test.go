@@ -0,0 +1,217 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+// partitionPattern は範囲を指定したシーク用クエリから$idの上限と下限を取り出す
+var partitionPattern = regexp.MustCompile(`\$id <= (\d+)\) and \$id > (\d+) order by \$id asc limit (\d+)$`)
+
+// idRecord は$idのみのレコード
+type idRecord struct {
+	ID struct {
+		Value string `json:"value"`
+	} `json:"$id"`
+}
+
+func TestParallelRecords(t *testing.T) {
+	// $idが1からtotalまでのレコードについて、$idの範囲の取得と範囲を指定したシークに応答する
+	const total = 2345
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(query, "order by $id asc limit 1"), strings.HasSuffix(query, "order by $id desc limit 1"):
+			if reqBody["totalCount"] != true {
+				t.Errorf("totalCountが指定されていない: %v", reqBody)
+			}
+			id := 1
+			if strings.Contains(query, "desc") {
+				id = total
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"records":    []map[string]any{{"$id": map[string]string{"value": strconv.Itoa(id)}}},
+				"totalCount": strconv.Itoa(total),
+			})
+			return
+		}
+
+		m := partitionPattern.FindStringSubmatch(query)
+		if m == nil {
+			t.Errorf("範囲を指定したシーク用のクエリではない: %s", query)
+			return
+		}
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		mu.Lock()
+		maxInFlight = max(maxInFlight, n)
+		mu.Unlock()
+
+		upper, _ := strconv.Atoi(m[1])
+		lastID, _ := strconv.Atoi(m[2])
+		limit, _ := strconv.Atoi(m[3])
+		records := []map[string]any{}
+		for id := lastID + 1; id <= upper && len(records) < limit; id++ {
+			records = append(records, map[string]any{"$id": map[string]string{"value": strconv.Itoa(id)}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	t.Run("ordered", func(t *testing.T) {
+		var last record.ParallelProgress
+		count := 0
+		for rec, err := range record.ParallelRecords[idRecord](context.Background(), client, record.ParallelRecordsParams{
+			App:         "1",
+			Partitions:  4,
+			Concurrency: 2,
+			Ordered:     true,
+			Progress:    func(p record.ParallelProgress) { last = p },
+		}) {
+			if err != nil {
+				t.Fatalf("エラーが発生: %v", err)
+			}
+			count++
+			if rec.ID.Value != strconv.Itoa(count) {
+				t.Fatalf("期待される$id: %d, 実際: %s", count, rec.ID.Value)
+			}
+		}
+
+		if count != total {
+			t.Errorf("期待される件数: %d, 実際: %d", total, count)
+		}
+		if last.Fetched != total || last.Total != total || last.Partitions != 4 || last.Completed != 4 {
+			t.Errorf("期待される進捗: %d/%d件 4/4分割, 実際: %+v", total, total, last)
+		}
+		if maxInFlight > 2 {
+			t.Errorf("同時リクエスト数が上限を超えた: %d", maxInFlight)
+		}
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		seen := map[string]bool{}
+		for rec, err := range record.ParallelRecords[idRecord](context.Background(), client, record.ParallelRecordsParams{
+			App:        "1",
+			Partitions: 3,
+		}) {
+			if err != nil {
+				t.Fatalf("エラーが発生: %v", err)
+			}
+			if seen[rec.ID.Value] {
+				t.Fatalf("重複したレコード: %s", rec.ID.Value)
+			}
+			seen[rec.ID.Value] = true
+		}
+		if len(seen) != total {
+			t.Errorf("期待される件数: %d, 実際: %d", total, len(seen))
+		}
+	})
+
+	t.Run("break", func(t *testing.T) {
+		count := 0
+		for _, err := range record.ParallelRecords[idRecord](context.Background(), client, record.ParallelRecordsParams{
+			App:        "1",
+			Partitions: 5,
+			Ordered:    true,
+		}) {
+			if err != nil {
+				t.Fatalf("エラーが発生: %v", err)
+			}
+			count++
+			if count == 10 {
+				break
+			}
+		}
+		if count != 10 {
+			t.Errorf("期待される件数: 10, 実際: %d", count)
+		}
+	})
+}
+
+func TestParallelRecordsError(t *testing.T) {
+	// $idの範囲の取得には応答し、$idが1000より大きい範囲のシークはエラーを返す
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(query, "limit 1") {
+			id := 1
+			if strings.Contains(query, "desc") {
+				id = 2000
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"records":    []map[string]any{{"$id": map[string]string{"value": strconv.Itoa(id)}}},
+				"totalCount": "2000",
+			})
+			return
+		}
+
+		m := partitionPattern.FindStringSubmatch(query)
+		if m == nil {
+			t.Errorf("範囲を指定したシーク用のクエリではない: %s", query)
+			return
+		}
+		upper, _ := strconv.Atoi(m[1])
+		lastID, _ := strconv.Atoi(m[2])
+		limit, _ := strconv.Atoi(m[3])
+		if lastID >= 1000 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code": "CB_IJ01", "id": "error-id", "message": "内部エラー"}`))
+			return
+		}
+		records := []map[string]any{}
+		for id := lastID + 1; id <= upper && len(records) < limit; id++ {
+			records = append(records, map[string]any{"$id": map[string]string{"value": strconv.Itoa(id)}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	var gotErr error
+	for _, err := range record.ParallelRecords[idRecord](context.Background(), client, record.ParallelRecordsParams{
+		App:        "1",
+		Partitions: 4,
+	}) {
+		if err != nil {
+			gotErr = err
+		}
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "CB_IJ01") {
+		t.Errorf("期待されるエラー: CB_IJ01, 実際: %v", gotErr)
+	}
+}
+
+func TestParallelRecordsEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"records": [], "totalCount": "0"}`))
+	}))
+	defer server.Close()
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	for _, err := range record.ParallelRecords[idRecord](context.Background(), client, record.ParallelRecordsParams{App: "1"}) {
+		t.Fatalf("レコードがないはず: %v", err)
+	}
+}
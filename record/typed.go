@@ -0,0 +1,288 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// readOnlyFieldTypes は書き込みできないフィールドタイプ
+var readOnlyFieldTypes = []string{
+	"__ID__", "__REVISION__", "RECORD_NUMBER",
+	"CREATOR", "CREATED_TIME", "MODIFIER", "UPDATED_TIME",
+	"CALC", "STATUS", "STATUS_ASSIGNEE", "CATEGORY",
+}
+
+// systemFieldCodes は書き込みできないシステムフィールドの初期設定のコード
+// type属性を持たないフィールドのみ、このコードでシステムフィールドと判定する
+var systemFieldCodes = []string{
+	"$id", "$revision",
+	"レコード番号", "作成者", "作成日時", "更新者", "更新日時",
+	"ステータス", "作業者", "カテゴリー",
+}
+
+// fieldTag は構造体タグ `kintone:"..."` の設定
+type fieldTag struct {
+	readOnly  bool // readonly: 常に書き込み対象から除外する
+	omitEmpty bool // omitempty: ゼロ値の場合に除外する（部分更新用）
+	isZero    bool
+}
+
+// parseFieldTags は構造体のフィールドからkintoneタグの設定を取り出す
+// キーはJSONのフィールド名
+func parseFieldTags(v reflect.Value) map[string]fieldTag {
+	tags := map[string]fieldTag{}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return tags
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return tags
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// 埋め込み構造体はフィールドを展開する
+		if sf.Anonymous && name == "" {
+			for k, tag := range parseFieldTags(v.Field(i)) {
+				tags[k] = tag
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		var tag fieldTag
+		for _, opt := range strings.Split(sf.Tag.Get("kintone"), ",") {
+			switch strings.TrimSpace(opt) {
+			case "readonly":
+				tag.readOnly = true
+			case "omitempty":
+				tag.omitEmpty = true
+			}
+		}
+		tag.isZero = v.Field(i).IsZero()
+		tags[name] = tag
+	}
+	return tags
+}
+
+// rawField は書き込み用に解析したフィールド
+type rawField struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// rawSubtableRow はテーブルの行
+type rawSubtableRow struct {
+	ID    string                     `json:"id,omitempty"`
+	Value map[string]json.RawMessage `json:"value"`
+}
+
+// ToFieldValues はレコード（構造体やmap）を書き込み用のフィールド値に変換する
+// 以下のフィールドは自動的に除外される
+//   - type属性が書き込み不可のフィールド（__ID__、RECORD_NUMBER、CALC、STATUSなど）。テーブル内も同様
+//   - type属性がなく、コードが$id、$revision、初期設定のシステムフィールドコード（作成日時など）のフィールド
+//
+// type属性がある場合はフィールドタイプのみで判定するため、「作業者」などのコードの通常のフィールドは、typeを指定すれば書き込める
+//   - タグ `kintone:"readonly"` を付けたフィールド
+//   - タグ `kintone:"omitempty"` を付けたゼロ値のフィールド
+func ToFieldValues[T any](rec T) (map[string]types.FieldValue, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("レコードのJSONエンコードエラー: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("レコードはJSONオブジェクトである必要があります: %w", err)
+	}
+
+	tags := parseFieldTags(reflect.ValueOf(rec))
+	result := make(map[string]types.FieldValue, len(raw))
+	for code, fieldData := range raw {
+		if isSystemFieldCode(code, fieldData) {
+			continue
+		}
+		if tag, ok := tags[code]; ok && (tag.readOnly || (tag.omitEmpty && tag.isZero)) {
+			continue
+		}
+
+		value, writable, err := writableValue(code, fieldData)
+		if err != nil {
+			return nil, err
+		}
+		if !writable {
+			continue
+		}
+		result[code] = types.FieldValue{Value: value}
+	}
+
+	return result, nil
+}
+
+// isSystemFieldCode はtype属性がなく、コードがシステムフィールドの初期設定のコードかどうかを返す
+func isSystemFieldCode(code string, data json.RawMessage) bool {
+	if !slices.Contains(systemFieldCodes, code) {
+		return false
+	}
+	var field rawField
+	json.Unmarshal(data, &field)
+	return field.Type == ""
+}
+
+// writableValue はフィールドから書き込み用の値を取り出す
+// 書き込みできないフィールドの場合はfalseを返す
+func writableValue(code string, data json.RawMessage) (json.RawMessage, bool, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, false, nil
+	}
+
+	var field rawField
+	if err := json.Unmarshal(data, &field); err != nil || field.Value == nil {
+		return nil, false, fmt.Errorf("フィールド値の形式が不正です（{\"value\": ...}の形式が必要）: %s", code)
+	}
+	if slices.Contains(readOnlyFieldTypes, field.Type) {
+		return nil, false, nil
+	}
+
+	// テーブルの場合は行内のフィールドも除外する
+	var rows []rawSubtableRow
+	if field.Type == "SUBTABLE" || (field.Type == "" && isSubtableValue(field.Value, &rows)) {
+		if rows == nil {
+			if err := json.Unmarshal(field.Value, &rows); err != nil {
+				return nil, false, fmt.Errorf("テーブルの形式が不正です: %s: %w", code, err)
+			}
+		}
+		for i := range rows {
+			for subCode, subData := range rows[i].Value {
+				subValue, writable, err := writableValue(subCode, subData)
+				if err != nil {
+					return nil, false, err
+				}
+				if !writable {
+					delete(rows[i].Value, subCode)
+					continue
+				}
+				rows[i].Value[subCode], _ = json.Marshal(types.FieldValue{Value: subValue})
+			}
+		}
+		value, err := json.Marshal(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("テーブルのJSONエンコードエラー: %s: %w", code, err)
+		}
+		return value, true, nil
+	}
+
+	return field.Value, true, nil
+}
+
+// isSubtableValue は値がテーブルの行の配列かどうかを判定する
+func isSubtableValue(value json.RawMessage, rows *[]rawSubtableRow) bool {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return false
+	}
+	var parsed []rawSubtableRow
+	if err := json.Unmarshal(trimmed, &parsed); err != nil || len(parsed) == 0 {
+		return false
+	}
+	for _, row := range parsed {
+		if row.Value == nil {
+			return false
+		}
+	}
+	*rows = parsed
+	return true
+}
+
+// AddRecord はレコードを1件追加する（ジェネリクス版）
+// GetRecord[T]で取得したものと同じ構造体を指定でき、書き込みできないフィールドは自動的に除外される
+func AddRecord[T any](ctx context.Context, c *Client, params AddTypedRecordParams[T]) (*AddRecordResult, error) {
+	fields, err := ToFieldValues(params.Record)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AddRecord(ctx, AddRecordParams{
+		App:    params.App,
+		Record: fields,
+	})
+}
+
+// AddRecords はレコードを複数追加する（ジェネリクス版、最大100件）
+func AddRecords[T any](ctx context.Context, c *Client, params AddTypedRecordsParams[T]) (*AddRecordsResult, error) {
+	records := make([]map[string]types.FieldValue, len(params.Records))
+	for i, rec := range params.Records {
+		fields, err := ToFieldValues(rec)
+		if err != nil {
+			return nil, fmt.Errorf("records[%d]: %w", i, err)
+		}
+		records[i] = fields
+	}
+
+	return c.AddRecords(ctx, AddRecordsParams{
+		App:     params.App,
+		Records: records,
+	})
+}
+
+// UpdateRecord はレコードを1件更新する（ジェネリクス版）
+// IDとUpdateKeyを省略した場合はRecordの$idを使用する
+// 部分更新する場合は、更新しないフィールドをポインタのnilにするか `kintone:"omitempty"` タグを付ける
+func UpdateRecord[T any](ctx context.Context, c *Client, params UpdateTypedRecordParams[T]) (*UpdateRecordResult, error) {
+	fields, err := ToFieldValues(params.Record)
+	if err != nil {
+		return nil, err
+	}
+
+	id := params.ID
+	if id == "" && params.UpdateKey == nil {
+		id, err = recordID(params.Record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 更新キーのフィールドはrecordに含めない
+	if params.UpdateKey != nil {
+		delete(fields, params.UpdateKey.Field)
+	}
+
+	return c.UpdateRecord(ctx, UpdateRecordParams{
+		App:       params.App,
+		ID:        id,
+		UpdateKey: params.UpdateKey,
+		Record:    fields,
+		Revision:  params.Revision,
+	})
+}
+
+// recordID はレコードの$idを取り出す
+func recordID(rec any) (types.RecordID, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", fmt.Errorf("レコードのJSONエンコードエラー: %w", err)
+	}
+	var idOnly recordIDOnly
+	if err := json.Unmarshal(data, &idOnly); err != nil || idOnly.ID.Value == "" {
+		return "", fmt.Errorf("IDが指定されておらず、レコードに$idも含まれていません")
+	}
+	return idOnly.ID.Value, nil
+}
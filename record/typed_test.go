@@ -0,0 +1,199 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+)
+
+// stringField はテスト用の文字列フィールド
+type stringField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// WriteRecord はテスト用の書き込みレコード構造体
+type WriteRecord struct {
+	ID        stringField  `json:"$id"`
+	Revision  stringField  `json:"$revision"`
+	CreatedAt stringField  `json:"作成日時"`
+	Name      stringField  `json:"名前"`
+	Total     stringField  `json:"合計"`
+	Memo      stringField  `json:"メモ" kintone:"omitempty"`
+	Note      *stringField `json:"備考"`
+	Internal  stringField  `json:"内部" kintone:"readonly"`
+	Items     struct {
+		Type  string `json:"type,omitempty"`
+		Value []struct {
+			ID    string `json:"id,omitempty"`
+			Value struct {
+				Product  stringField `json:"品名"`
+				Subtotal stringField `json:"小計"`
+			} `json:"value"`
+		} `json:"value"`
+	} `json:"明細"`
+}
+
+func newWriteRecord() WriteRecord {
+	var rec WriteRecord
+	rec.ID.Value = "10"
+	rec.Revision.Value = "3"
+	rec.CreatedAt.Value = "2024-01-01T00:00:00Z"
+	rec.Name.Value = "テスト"
+	rec.Total = stringField{Type: "CALC", Value: "100"}
+	rec.Internal.Value = "x"
+	rec.Items.Type = "SUBTABLE"
+	rec.Items.Value = make([]struct {
+		ID    string `json:"id,omitempty"`
+		Value struct {
+			Product  stringField `json:"品名"`
+			Subtotal stringField `json:"小計"`
+		} `json:"value"`
+	}, 1)
+	rec.Items.Value[0].ID = "500"
+	rec.Items.Value[0].Value.Product = stringField{Type: "SINGLE_LINE_TEXT", Value: "りんご"}
+	rec.Items.Value[0].Value.Subtotal = stringField{Type: "CALC", Value: "100"}
+	return rec
+}
+
+func TestToFieldValues(t *testing.T) {
+	fields, err := record.ToFieldValues(newWriteRecord())
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	data, _ := json.Marshal(fields)
+	var got map[string]any
+	json.Unmarshal(data, &got)
+
+	for _, code := range []string{"$id", "$revision", "作成日時", "合計", "メモ", "備考", "内部"} {
+		if _, ok := got[code]; ok {
+			t.Errorf("除外されるはずのフィールドが含まれている: %s", code)
+		}
+	}
+	if got["名前"].(map[string]any)["value"] != "テスト" {
+		t.Errorf("期待される名前: テスト, 実際: %v", got["名前"])
+	}
+
+	rows := got["明細"].(map[string]any)["value"].([]any)
+	row := rows[0].(map[string]any)
+	if row["id"] != "500" {
+		t.Errorf("期待される行ID: 500, 実際: %v", row["id"])
+	}
+	cells := row["value"].(map[string]any)
+	if _, ok := cells["小計"]; ok {
+		t.Error("テーブル内の計算フィールドが除外されていない")
+	}
+	product := cells["品名"].(map[string]any)
+	if product["value"] != "りんご" {
+		t.Errorf("期待される品名: りんご, 実際: %v", product["value"])
+	}
+	if _, ok := product["type"]; ok {
+		t.Error("書き込み用の値にtypeが含まれている")
+	}
+}
+
+func TestToFieldValuesSystemFieldCode(t *testing.T) {
+	rec := map[string]stringField{
+		"作業者":  {Type: "USER_SELECT", Value: "user1"},
+		"作成者":  {Type: "CREATOR", Value: "user1"},
+		"更新日時": {Value: "2024-01-01T00:00:00Z"},
+	}
+	fields, err := record.ToFieldValues(rec)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if _, ok := fields["作業者"]; !ok {
+		t.Error("typeが書き込み可能な「作業者」のフィールドが除外されている")
+	}
+	for _, code := range []string{"作成者", "更新日時"} {
+		if _, ok := fields[code]; ok {
+			t.Errorf("除外されるはずのフィールドが含まれている: %s", code)
+		}
+	}
+}
+
+func TestToFieldValuesOmitEmpty(t *testing.T) {
+	rec := newWriteRecord()
+	rec.Memo.Value = "メモあり"
+	rec.Note = &stringField{Value: ""}
+
+	fields, err := record.ToFieldValues(rec)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if _, ok := fields["メモ"]; !ok {
+		t.Error("値のあるomitemptyフィールドが除外されている")
+	}
+	if _, ok := fields["備考"]; !ok {
+		t.Error("nilでないポインタのフィールドが除外されている")
+	}
+}
+
+func TestTypedWrites(t *testing.T) {
+	var lastBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastBody = nil
+		json.NewDecoder(r.Body).Decode(&lastBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/k/v1/record.json":
+			json.NewEncoder(w).Encode(map[string]any{"id": "11", "revision": "1"})
+		case r.Method == "POST" && r.URL.Path == "/k/v1/records.json":
+			json.NewEncoder(w).Encode(map[string]any{"ids": []string{"12", "13"}, "revisions": []string{"1", "1"}})
+		case r.Method == "PUT":
+			json.NewEncoder(w).Encode(map[string]any{"revision": "4"})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	added, err := record.AddRecord(ctx, client, record.AddTypedRecordParams[WriteRecord]{
+		App:    "1",
+		Record: newWriteRecord(),
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if added.ID != "11" {
+		t.Errorf("期待されるID: 11, 実際: %s", added.ID)
+	}
+	if _, ok := lastBody["record"].(map[string]any)["$id"]; ok {
+		t.Error("追加時に$idが送信されている")
+	}
+
+	addedAll, err := record.AddRecords(ctx, client, record.AddTypedRecordsParams[WriteRecord]{
+		App:     "1",
+		Records: []WriteRecord{newWriteRecord(), newWriteRecord()},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(addedAll.IDs) != 2 {
+		t.Errorf("期待される件数: 2, 実際: %d", len(addedAll.IDs))
+	}
+
+	updated, err := record.UpdateRecord(ctx, client, record.UpdateTypedRecordParams[WriteRecord]{
+		App:    "1",
+		Record: newWriteRecord(),
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if updated.Revision != "4" {
+		t.Errorf("期待されるrevision: 4, 実際: %s", updated.Revision)
+	}
+	if lastBody["id"] != "10" {
+		t.Errorf("レコードの$idがIDとして使われていない: %v", lastBody["id"])
+	}
+}
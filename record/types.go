@@ -1,4 +1,10 @@
 // Package record はレコード操作APIを提供する
+//
+// AddAllRecordsなど件数の上限なく書き込む関数は、レコードを100件ずつのリクエストに分け、
+// 最大20個のリクエストをまとめたバルクリクエスト（2000件）単位で順に実行する。
+// バルクリクエストはアトミックに処理されるため、途中のバルクリクエストが失敗した場合は、
+// それより前のバルクリクエストの結果は確定し、失敗したバルクリクエスト以降は書き込まれない。
+// このときは処理済み・未処理のレコードを含む*error.KintoneAllRecordsErrorを返す
 package record
 
 import "github.com/goqoo-on-kintone/goten/types"
@@ -31,6 +37,28 @@ type GetAllRecordsParams struct {
 	OrderBy   string
 }
 
+// ParallelRecordsParams はParallelRecordsのパラメータ
+type ParallelRecordsParams struct {
+	App       types.AppID
+	Fields    []string
+	Condition string
+
+	Partitions  int  // $idの範囲の分割数（省略時は4）
+	Concurrency int  // 同時に実行するリクエスト数の上限（省略時はPartitionsと同じ）
+	Ordered     bool // trueの場合は$idの昇順で返す（falseの場合は取得した順）
+
+	// Progress はページを取得するごとに呼ばれる（イテレータを実行しているgoroutineから順に呼ばれる）
+	Progress func(ParallelProgress)
+}
+
+// ParallelProgress はParallelRecordsの進捗
+type ParallelProgress struct {
+	Fetched    int // 取得したレコード数
+	Total      int // 開始時点の条件に一致するレコード数
+	Partitions int // 分割数
+	Completed  int // 取得が完了した分割の数
+}
+
 // AddRecordParams はAddRecordのパラメータ
 type AddRecordParams struct {
 	App    types.AppID
@@ -149,6 +177,13 @@ type GetRecordCommentsResult struct {
 	Newer    bool      `json:"newer"`
 }
 
+// AllRecordCommentsParams はAllRecordCommentsのパラメータ
+type AllRecordCommentsParams struct {
+	App    types.AppID
+	Record types.RecordID
+	Order  string // asc（古い順）または desc（新しい順、省略時）
+}
+
 // Comment はコメント情報
 type Comment struct {
 	ID        string        `json:"id"`
@@ -211,6 +246,35 @@ type UpdateRecordStatusResult struct {
 	Revision string `json:"revision"`
 }
 
+// UpdateRecordAssigneesParams はUpdateRecordAssigneesのパラメータ
+type UpdateRecordAssigneesParams struct {
+	App       types.AppID
+	ID        types.RecordID
+	Assignees []string        // 作業者のログイン名（最大100人、空の場合は作業者をクリア）
+	Revision  *types.Revision // リビジョン（省略可）
+}
+
+// UpdateRecordAssigneesResult はUpdateRecordAssigneesの結果
+type UpdateRecordAssigneesResult struct {
+	Revision string `json:"revision"`
+}
+
+// ReassignRecordsParams はReassignRecordsのパラメータ
+type ReassignRecordsParams struct {
+	App       types.AppID
+	From      string   // 置き換える作業者のログイン名
+	To        []string // 新しい作業者のログイン名（空の場合はFromを外すのみ）
+	Condition string   // 対象を絞り込む条件（省略可、order by・limit・offsetは指定不可）
+
+	// AssigneeField は作業者のフィールドコード（省略時は「作業者」）
+	AssigneeField string
+}
+
+// ReassignRecordsResult はReassignRecordsの結果
+type ReassignRecordsResult struct {
+	Records []UpdateRecordsResultItem // 作業者を更新したレコード
+}
+
 // UpdateRecordsStatusParams はUpdateRecordsStatusのパラメータ
 type UpdateRecordsStatusParams struct {
 	App     types.AppID
@@ -243,4 +307,115 @@ type UpsertRecordParams struct {
 type UpsertRecordResult struct {
 	ID       string `json:"id"`
 	Revision string `json:"revision"`
+	Added    bool   `json:"added"` // trueの場合は追加、falseの場合は更新
+}
+
+// UpsertRecordsParams はUpsertRecordsのパラメータ
+type UpsertRecordsParams struct {
+	App      types.AppID
+	KeyField string                        // 重複チェックに使うフィールド（重複禁止のフィールド）
+	Records  []map[string]types.FieldValue // キーのフィールドを含むレコード（件数の上限なし）
+}
+
+// UpsertRecordsResult はUpsertRecordsの結果
+type UpsertRecordsResult struct {
+	Records []UpsertRecordResult // 入力のレコードと同じ順序
+}
+
+// --- 大量レコード操作API ---
+
+// AddAllRecordsParams はAddAllRecordsのパラメータ
+type AddAllRecordsParams struct {
+	App     types.AppID
+	Records []map[string]types.FieldValue // 件数の上限なし
+}
+
+// AddAllRecordsResult はAddAllRecordsの結果
+type AddAllRecordsResult struct {
+	IDs       []string
+	Revisions []string
+}
+
+// UpdateAllRecordsParams はUpdateAllRecordsのパラメータ
+type UpdateAllRecordsParams struct {
+	App     types.AppID
+	Records []UpdateRecordItem // 件数の上限なし
+}
+
+// UpdateAllRecordsResult はUpdateAllRecordsの結果
+type UpdateAllRecordsResult struct {
+	Records []UpdateRecordsResultItem
+}
+
+// DeleteAllRecordsParams はDeleteAllRecordsのパラメータ
+type DeleteAllRecordsParams struct {
+	App       types.AppID
+	IDs       []types.RecordID // 件数の上限なし
+	Revisions []types.Revision // 省略可（指定する場合はIDsと同じ件数）
+}
+
+// --- 型付き書き込みAPI ---
+
+// AddTypedRecordParams はAddRecord[T]のパラメータ
+type AddTypedRecordParams[T any] struct {
+	App    types.AppID
+	Record T
+}
+
+// AddTypedRecordsParams はAddRecords[T]のパラメータ
+type AddTypedRecordsParams[T any] struct {
+	App     types.AppID
+	Records []T // 最大100件
+}
+
+// UpdateTypedRecordParams はUpdateRecord[T]のパラメータ
+type UpdateTypedRecordParams[T any] struct {
+	App       types.AppID
+	ID        types.RecordID   // 省略時はRecordの$idを使用する（UpdateKey指定時を除く）
+	UpdateKey *types.UpdateKey // 指定したフィールドはRecordから除外される
+	Record    T
+	Revision  *types.Revision
+}
+
+// --- アクセス権API ---
+
+// EvaluateRecordAclParams はEvaluateRecordAclのパラメータ
+type EvaluateRecordAclParams struct {
+	App types.AppID
+	IDs []types.RecordID // EvaluateRecordAclは最大100件、EvaluateAllRecordAclは上限なし
+}
+
+// EvaluateRecordAclResult はEvaluateRecordAclの結果
+type EvaluateRecordAclResult struct {
+	Rights []RecordAclRight `json:"rights"`
+}
+
+// RecordAclRight はレコードごとのアクセス権
+type RecordAclRight struct {
+	ID     types.RecordID        `json:"id"`
+	Record RecordRight           `json:"record"`
+	Fields map[string]FieldRight `json:"fields"` // キーはフィールドコード
+}
+
+// RecordRight はレコードに対する権限
+type RecordRight struct {
+	Viewable  bool `json:"viewable"`
+	Editable  bool `json:"editable"`
+	Deletable bool `json:"deletable"`
+}
+
+// FieldRight はフィールドに対する権限
+type FieldRight struct {
+	Viewable bool `json:"viewable"`
+	Editable bool `json:"editable"`
+}
+
+// CanEditField はレコードとフィールドの両方が編集可能かどうかを返す
+// フィールドのアクセス権が返されていない場合はレコードの権限に従う
+func (r RecordAclRight) CanEditField(code string) bool {
+	if !r.Record.Editable {
+		return false
+	}
+	field, ok := r.Fields[code]
+	return !ok || field.Editable
 }
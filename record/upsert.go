@@ -0,0 +1,311 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/bulk"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/query"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// upsertLookupSize は既存レコードの検索で1回のクエリに含めるキーの数
+const upsertLookupSize = 100
+
+// upsertChunk はUpsertRecordsで1リクエストにまとめるレコード
+type upsertChunk struct {
+	added   bool  // trueの場合は追加、falseの場合は更新
+	indices []int // 入力のレコードのインデックス
+}
+
+// UpsertRecord はupdateKeyでレコードを検索し、存在すれば更新、なければ追加する
+// 追加時はupdateKeyのフィールドもレコードに含める
+func (c *Client) UpsertRecord(ctx context.Context, params UpsertRecordParams) (*UpsertRecordResult, error) {
+	rec := make(map[string]types.FieldValue, len(params.Record)+1)
+	maps.Copy(rec, params.Record)
+	rec[params.UpdateKey.Field] = types.FieldValue{Value: params.UpdateKey.Value}
+
+	result, err := c.UpsertRecords(ctx, UpsertRecordsParams{
+		App:      params.App,
+		KeyField: params.UpdateKey.Field,
+		Records:  []map[string]types.FieldValue{rec},
+	})
+	if err != nil {
+		var allErr *kintoneError.KintoneAllRecordsError
+		if errors.As(err, &allErr) {
+			return nil, allErr.Err
+		}
+		return nil, err
+	}
+
+	return &result.Records[0], nil
+}
+
+// UpsertRecords はキーのフィールドで既存レコードを検索し、存在するレコードは更新、存在しないレコードは追加する
+// キーのフィールドは重複禁止に設定したフィールドを指定し、各レコードに値を含める
+// 追加と更新はAddAllRecordsなどと同じくバルクリクエスト単位で処理する（パッケージのドキュメントを参照）
+// 失敗時の*error.KintoneAllRecordsErrorのProcessedRecordsは*UpsertRecordsResultで、未処理のレコードの結果は空になる
+func (c *Client) UpsertRecords(ctx context.Context, params UpsertRecordsParams) (*UpsertRecordsResult, error) {
+	if params.KeyField == "" {
+		return nil, fmt.Errorf("KeyFieldが指定されていません")
+	}
+
+	keys := make([]string, len(params.Records))
+	seen := make(map[string]int, len(params.Records))
+	for i, rec := range params.Records {
+		field, ok := rec[params.KeyField]
+		if !ok {
+			return nil, fmt.Errorf("records[%d]: キーのフィールドがありません: %s", i, params.KeyField)
+		}
+		key, err := keyString(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("records[%d]: %w", i, err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("records[%d]: キーの値が空です: %s", i, params.KeyField)
+		}
+		if j, dup := seen[key]; dup {
+			return nil, fmt.Errorf("records[%d]: キーの値がrecords[%d]と重複しています: %s", i, j, key)
+		}
+		seen[key] = i
+		keys[i] = key
+	}
+
+	existing, fieldType, err := c.lookupRecordIDs(ctx, params.App, params.KeyField, keys)
+	if err != nil {
+		return nil, err
+	}
+	// 表記が異なっても同じ既存レコードに一致するキー（数値の"0123"と"123"など）は重複とする
+	if fieldType != "" {
+		normalized := make(map[string]int, len(keys))
+		for i, key := range keys {
+			nk := NormalizeKey(fieldType, key)
+			if j, dup := normalized[nk]; dup {
+				return nil, fmt.Errorf("records[%d]: キーの値がrecords[%d]と重複しています: %s", i, j, key)
+			}
+			normalized[nk] = i
+		}
+	}
+
+	var adds, updates []int
+	for i, key := range keys {
+		if _, ok := existing[key]; ok {
+			updates = append(updates, i)
+		} else {
+			adds = append(adds, i)
+		}
+	}
+	chunks := append(splitChunks(adds, true), splitChunks(updates, false)...)
+
+	requests := make([]bulk.Request, len(chunks))
+	for i, chunk := range chunks {
+		requests[i] = c.upsertRequest(params, chunk, keys, existing)
+	}
+	result := &UpsertRecordsResult{
+		Records: make([]UpsertRecordResult, len(params.Records)),
+	}
+	done, err := c.sendInBulk(ctx, requests, func(i int, raw json.RawMessage) error {
+		return handleUpsertResult(result, chunks[i], raw)
+	})
+	if err != nil {
+		processed := make([]bool, len(params.Records))
+		numOfProcessed := 0
+		for _, chunk := range chunks[:done] {
+			for _, i := range chunk.indices {
+				processed[i] = true
+			}
+			numOfProcessed += len(chunk.indices)
+		}
+		var unprocessed []any
+		for i, rec := range params.Records {
+			if !processed[i] {
+				unprocessed = append(unprocessed, rec)
+			}
+		}
+		return nil, &kintoneError.KintoneAllRecordsError{
+			ProcessedRecords:      result,
+			UnprocessedRecords:    unprocessed,
+			Err:                   err,
+			ErrorIndex:            upsertErrorIndex(chunks, done, err),
+			NumOfProcessedRecords: numOfProcessed,
+			NumOfAllRecords:       len(params.Records),
+		}
+	}
+
+	return result, nil
+}
+
+// lookupRecordIDs はキーの値で既存レコードを検索し、キーの値からレコードIDを引けるようにする
+// 既存レコードとの照合はNormalizeKeyで正規化した値で行う。一致したレコードがある場合はキーのフィールドタイプも返す
+func (c *Client) lookupRecordIDs(ctx context.Context, app types.AppID, keyField string, keys []string) (map[string]types.RecordID, string, error) {
+	existing := make(map[string]types.RecordID, len(keys))
+	fieldType := ""
+	for start := 0; start < len(keys); start += upsertLookupSize {
+		chunk := keys[start:min(start+upsertLookupSize, len(keys))]
+		q := query.Where(query.Field(keyField).In(toAnySlice(chunk)...)).Limit(getAllRecordsLimit)
+
+		records, err := GetRecords[Dynamic](ctx, c, GetRecordsParams{
+			App:    app,
+			Fields: []string{"$id", keyField},
+			Query:  q.String(),
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("既存レコードの検索エラー: %w", err)
+		}
+		if len(records.Records) == 0 {
+			continue
+		}
+
+		fieldType = records.Records[0].Type(keyField)
+		ids := make(map[string]types.RecordID, len(records.Records))
+		for _, rec := range records.Records {
+			key, err := rec.String(keyField)
+			if err != nil {
+				return nil, "", fmt.Errorf("既存レコードの検索エラー: %w", err)
+			}
+			ids[NormalizeKey(fieldType, key)] = rec.ID()
+		}
+		for _, key := range chunk {
+			if id, ok := ids[NormalizeKey(fieldType, key)]; ok {
+				existing[key] = id
+			}
+		}
+	}
+	return existing, fieldType, nil
+}
+
+// upsertRequest は追加または更新のリクエストを作成する
+func (c *Client) upsertRequest(params UpsertRecordsParams, chunk upsertChunk, keys []string, existing map[string]types.RecordID) bulk.Request {
+	if chunk.added {
+		records := make([]map[string]types.FieldValue, len(chunk.indices))
+		for j, i := range chunk.indices {
+			records[j] = params.Records[i]
+		}
+		return bulk.Request{
+			Method: "POST",
+			API:    c.httpClient.APIPath("records"),
+			Payload: map[string]any{
+				"app":     params.App,
+				"records": records,
+			},
+		}
+	}
+
+	records := make([]UpdateRecordItem, len(chunk.indices))
+	for j, i := range chunk.indices {
+		records[j] = UpdateRecordItem{
+			ID:     existing[keys[i]],
+			Record: params.Records[i],
+		}
+	}
+	return bulk.Request{
+		Method: "PUT",
+		API:    c.httpClient.APIPath("records"),
+		Payload: map[string]any{
+			"app":     params.App,
+			"records": records,
+		},
+	}
+}
+
+// handleUpsertResult はバルクリクエストの結果を入力の順序で格納する
+func handleUpsertResult(result *UpsertRecordsResult, chunk upsertChunk, raw json.RawMessage) error {
+	if chunk.added {
+		var r AddRecordsResult
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("レスポンス解析エラー: %w", err)
+		}
+		if len(r.IDs) != len(chunk.indices) || len(r.Revisions) != len(chunk.indices) {
+			return fmt.Errorf("追加結果の件数が不正です: 期待=%d, 実際=%d", len(chunk.indices), len(r.IDs))
+		}
+		for j, i := range chunk.indices {
+			result.Records[i] = UpsertRecordResult{ID: r.IDs[j], Revision: r.Revisions[j], Added: true}
+		}
+		return nil
+	}
+
+	var r UpdateRecordsResult
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return fmt.Errorf("レスポンス解析エラー: %w", err)
+	}
+	if len(r.Records) != len(chunk.indices) {
+		return fmt.Errorf("更新結果の件数が不正です: 期待=%d, 実際=%d", len(chunk.indices), len(r.Records))
+	}
+	for j, i := range chunk.indices {
+		result.Records[i] = UpsertRecordResult{ID: r.Records[j].ID, Revision: r.Records[j].Revision}
+	}
+	return nil
+}
+
+// splitChunks はレコードのインデックスを100件ずつに分割する
+func splitChunks(indices []int, added bool) []upsertChunk {
+	var chunks []upsertChunk
+	for start := 0; start < len(indices); start += recordsPerRequest {
+		chunks = append(chunks, upsertChunk{
+			added:   added,
+			indices: indices[start:min(start+recordsPerRequest, len(indices))],
+		})
+	}
+	return chunks
+}
+
+// upsertErrorIndex はエラーが発生したレコードの入力でのインデックスを返す（特定できない場合は-1）
+// doneは失敗したバルクリクエストより前のリクエスト数（sendInBulkの戻り値）
+func upsertErrorIndex(chunks []upsertChunk, done int, err error) int {
+	request := failedRequestIndex(done, err)
+	if request < 0 || request >= len(chunks) {
+		return -1
+	}
+	var apiErr *kintoneError.KintoneRestAPIError
+	errors.As(err, &apiErr)
+	index := firstErrorRecord(apiErr)
+	if index < 0 || index >= len(chunks[request].indices) {
+		return -1
+	}
+	return chunks[request].indices[index]
+}
+
+// keyString はキーのフィールド値を文字列に変換する
+func keyString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.RawMessage:
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return s, nil
+		}
+		var n json.Number
+		if err := json.Unmarshal(v, &n); err == nil {
+			return n.String(), nil
+		}
+		return "", fmt.Errorf("キーの値は文字列または数値である必要があります: %s", bytes.TrimSpace(v))
+	case fmt.Stringer:
+		return v.String(), nil
+	case int, int32, int64, uint, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("キーの値は文字列または数値である必要があります: %T", value)
+	}
+}
+
+// NormalizeKey はupdateKeyなどのキーの値を、フィールドタイプに応じて照合用に正規化する
+// 数値のフィールドは数値として比較するため有理数の表現に変換する（"0123"と"123"、"1.0"と"1"が同じ値になる）
+// それ以外のフィールドタイプ、数値として解析できない値はそのまま返す
+func NormalizeKey(fieldType, key string) string {
+	if fieldType != types.FieldTypeNumber {
+		return key
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(key))
+	if !ok {
+		return key
+	}
+	return r.RatString()
+}
@@ -0,0 +1,349 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// inValuesPattern はin条件の値を取り出す
+var inValuesPattern = regexp.MustCompile(`"([^"]*)"`)
+
+func newUpsertRecords(n int) []map[string]types.FieldValue {
+	records := make([]map[string]types.FieldValue, n)
+	for i := range records {
+		records[i] = map[string]types.FieldValue{
+			"コード": {Value: fmt.Sprintf("K%d", i)},
+			"名前":  {Value: fmt.Sprintf("レコード%d", i)},
+		}
+	}
+	return records
+}
+
+func TestUpsertRecords(t *testing.T) {
+	// 既存レコードのキーは偶数番号で、IDはキーの番号+10000
+	// failBulk回目のバルクリクエストは、2番目のリクエストの4件目のレコードでエラーにする
+	var lookups, bulks, failBulk int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/k/v1/records.json" {
+			lookups++
+			var reqBody struct {
+				Query  string   `json:"query"`
+				Fields []string `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if !strings.HasPrefix(reqBody.Query, "コード in (") {
+				t.Errorf("期待されるクエリ: コード in (...), 実際: %s", reqBody.Query)
+			}
+
+			var records []map[string]any
+			for _, m := range inValuesPattern.FindAllStringSubmatch(reqBody.Query, -1) {
+				n, _ := strconv.Atoi(strings.TrimPrefix(m[1], "K"))
+				if n%2 == 0 {
+					records = append(records, map[string]any{
+						"$id":  map[string]any{"type": "__ID__", "value": strconv.Itoa(n + 10000)},
+						"コード": map[string]any{"type": "SINGLE_LINE_TEXT", "value": m[1]},
+					})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"records": records})
+			return
+		}
+
+		bulks++
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		results := make([]map[string]any, len(reqBody.Requests))
+		for i, req := range reqBody.Requests {
+			switch req.Method {
+			case "POST":
+				var ids, revisions []string
+				for _, raw := range req.Payload.Records {
+					var rec map[string]struct{ Value string }
+					json.Unmarshal(raw, &rec)
+					n, _ := strconv.Atoi(strings.TrimPrefix(rec["コード"].Value, "K"))
+					if n%2 == 0 {
+						t.Errorf("既存レコードが追加されている: %s", rec["コード"].Value)
+					}
+					ids = append(ids, strconv.Itoa(n+20000))
+					revisions = append(revisions, "1")
+				}
+				results[i] = map[string]any{"ids": ids, "revisions": revisions}
+			case "PUT":
+				var items []map[string]any
+				for _, raw := range req.Payload.Records {
+					var item struct {
+						ID string `json:"id"`
+					}
+					json.Unmarshal(raw, &item)
+					items = append(items, map[string]any{"id": item.ID, "revision": "2"})
+				}
+				results[i] = map[string]any{"records": items}
+			}
+		}
+
+		if bulks == failBulk {
+			for i := range results {
+				results[i] = map[string]any{}
+			}
+			results[1] = map[string]any{
+				"code":    "CB_VA01",
+				"message": "入力内容が正しくありません。",
+				"errors": map[string]any{
+					"records[3].名前.value": map[string]any{"messages": []string{"必須です。"}},
+				},
+			}
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"})
+	client := record.NewClient(httpClient)
+
+	t.Run("upsert", func(t *testing.T) {
+		lookups, bulks, failBulk = 0, 0, 0
+		result, err := client.UpsertRecords(ctx, record.UpsertRecordsParams{
+			App:      "1",
+			KeyField: "コード",
+			Records:  newUpsertRecords(4250),
+		})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if lookups != 43 {
+			t.Errorf("期待される検索回数: 43, 実際: %d", lookups)
+		}
+		// 追加2125件（22リクエスト）＋更新2125件（22リクエスト）= 44リクエスト → 3回のバルクリクエスト
+		if bulks != 3 {
+			t.Errorf("期待されるバルクリクエスト回数: 3, 実際: %d", bulks)
+		}
+		if len(result.Records) != 4250 {
+			t.Fatalf("期待される結果件数: 4250, 実際: %d", len(result.Records))
+		}
+		for _, i := range []int{0, 1, 2000, 4249} {
+			got := result.Records[i]
+			wantAdded := i%2 == 1
+			wantID := strconv.Itoa(i + 10000)
+			if wantAdded {
+				wantID = strconv.Itoa(i + 20000)
+			}
+			if got.Added != wantAdded || got.ID != wantID {
+				t.Errorf("records[%d]: 期待される結果: added=%v id=%s, 実際: added=%v id=%s", i, wantAdded, wantID, got.Added, got.ID)
+			}
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		lookups, bulks, failBulk = 0, 0, 2
+		_, err := client.UpsertRecords(ctx, record.UpsertRecordsParams{
+			App:      "1",
+			KeyField: "コード",
+			Records:  newUpsertRecords(4250),
+		})
+
+		var allErr *kintoneError.KintoneAllRecordsError
+		if !errors.As(err, &allErr) {
+			t.Fatalf("KintoneAllRecordsErrorが返るはずが: %v", err)
+		}
+		if allErr.NumOfProcessedRecords != 2000 {
+			t.Errorf("期待される処理済み件数: 2000, 実際: %d", allErr.NumOfProcessedRecords)
+		}
+		if len(allErr.UnprocessedRecords) != 2250 {
+			t.Errorf("期待される未処理件数: 2250, 実際: %d", len(allErr.UnprocessedRecords))
+		}
+		// 2回目のバルクリクエストの2番目は追加の22番目のリクエスト（追加レコードの2100件目〜）
+		// その4件目は追加レコードの2103件目 = 入力の4207番目
+		if allErr.ErrorIndex != 4207 {
+			t.Errorf("期待されるエラーインデックス: 4207, 実際: %d", allErr.ErrorIndex)
+		}
+		if !kintoneError.HasCode(err, kintoneError.CodeValidation) {
+			t.Errorf("原因のエラーコードが判定できない: %v", err)
+		}
+	})
+
+	t.Run("UpsertRecord", func(t *testing.T) {
+		lookups, bulks, failBulk = 0, 0, 0
+		updated, err := client.UpsertRecord(ctx, record.UpsertRecordParams{
+			App:       "1",
+			UpdateKey: types.UpdateKey{Field: "コード", Value: "K4"},
+			Record:    map[string]types.FieldValue{"名前": {Value: "更新"}},
+		})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if updated.Added || updated.ID != "10004" || updated.Revision != "2" {
+			t.Errorf("期待される結果: 更新 id=10004, 実際: %+v", updated)
+		}
+
+		// 追加時はキーのフィールドもレコードに含まれる（含まれない場合はサーバー側でID=20000になる）
+		added, err := client.UpsertRecord(ctx, record.UpsertRecordParams{
+			App:       "1",
+			UpdateKey: types.UpdateKey{Field: "コード", Value: "K5"},
+			Record:    map[string]types.FieldValue{"名前": {Value: "追加"}},
+		})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		if !added.Added || added.ID != "20005" {
+			t.Errorf("期待される結果: 追加 id=20005, 実際: %+v", added)
+		}
+	})
+}
+
+func TestUpsertRecordsValidation(t *testing.T) {
+	client := record.NewClient(gotenhttp.NewDefaultClient("http://localhost", auth.APITokenAuth{Token: "test-token"}))
+	ctx := context.Background()
+
+	records := newUpsertRecords(3)
+	records[2]["コード"] = types.FieldValue{Value: "K0"}
+	if _, err := client.UpsertRecords(ctx, record.UpsertRecordsParams{App: "1", KeyField: "コード", Records: records}); err == nil {
+		t.Error("キーの重複でエラーが発生しない")
+	}
+
+	records = newUpsertRecords(1)
+	delete(records[0], "コード")
+	if _, err := client.UpsertRecords(ctx, record.UpsertRecordsParams{App: "1", KeyField: "コード", Records: records}); err == nil {
+		t.Error("キーのフィールドがない場合にエラーが発生しない")
+	}
+}
+
+func TestUpsertRecordsNumberKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/k/v1/records.json" {
+			// 数値のフィールドは数値として検索されるため、表記の異なる既存レコードが返る
+			json.NewEncoder(w).Encode(map[string]any{"records": []map[string]any{
+				{"$id": map[string]any{"type": "__ID__", "value": "1"}, "番号": map[string]any{"type": "NUMBER", "value": "123"}},
+				{"$id": map[string]any{"type": "__ID__", "value": "2"}, "番号": map[string]any{"type": "NUMBER", "value": "1"}},
+			}})
+			return
+		}
+
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		results := make([]map[string]any, len(reqBody.Requests))
+		for i, req := range reqBody.Requests {
+			if req.Method != "PUT" {
+				t.Errorf("既存レコードが追加されている: %s", req.Method)
+			}
+			var items []map[string]any
+			for _, raw := range req.Payload.Records {
+				var item struct {
+					ID string `json:"id"`
+				}
+				json.Unmarshal(raw, &item)
+				items = append(items, map[string]any{"id": item.ID, "revision": "2"})
+			}
+			results[i] = map[string]any{"records": items}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+
+	result, err := client.UpsertRecords(ctx, record.UpsertRecordsParams{
+		App:      "1",
+		KeyField: "番号",
+		Records: []map[string]types.FieldValue{
+			{"番号": {Value: "0123"}},
+			{"番号": {Value: "1.0"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	for i, want := range []string{"1", "2"} {
+		if got := result.Records[i]; got.Added || got.ID != want {
+			t.Errorf("期待される結果: 更新 id=%s, 実際: %+v", want, got)
+		}
+	}
+}
+
+func TestUpsertRecordsNumberKeyDuplicate(t *testing.T) {
+	bulks := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/k/v1/records.json" {
+			bulks++
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"records": []map[string]any{
+			{"$id": map[string]any{"type": "__ID__", "value": "1"}, "番号": map[string]any{"type": "NUMBER", "value": "123"}},
+		}})
+	}))
+	defer server.Close()
+
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	_, err := client.UpsertRecords(context.Background(), record.UpsertRecordsParams{
+		App:      "1",
+		KeyField: "番号",
+		Records: []map[string]types.FieldValue{
+			{"番号": {Value: "0123"}},
+			{"番号": {Value: "123"}},
+		},
+	})
+	// 同じ既存レコードに一致するキーは書き込む前にエラーにする
+	if err == nil || !strings.Contains(err.Error(), "records[1]") {
+		t.Errorf("期待されるエラー: records[1]の重複, 実際: %v", err)
+	}
+	if bulks != 0 {
+		t.Errorf("書き込まないはずが: %d回", bulks)
+	}
+}
+
+func TestUpsertRecordsInvalidResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/k/v1/records.json" {
+			json.NewEncoder(w).Encode(map[string]any{"records": []any{}})
+			return
+		}
+
+		// バルクリクエストは成功するが、結果の形式が不正
+		var reqBody bulkRequestBody
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		results := make([]map[string]any, len(reqBody.Requests))
+		for i := range results {
+			results[i] = map[string]any{"ids": "invalid"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer server.Close()
+
+	records := make([]map[string]types.FieldValue, 2500)
+	for i := range records {
+		records[i] = map[string]types.FieldValue{"コード": {Value: fmt.Sprintf("K%d", i)}}
+	}
+	client := record.NewClient(gotenhttp.NewDefaultClient(server.URL, auth.APITokenAuth{Token: "test-token"}))
+	_, err := client.UpsertRecords(context.Background(), record.UpsertRecordsParams{App: "1", KeyField: "コード", Records: records})
+
+	var allErr *kintoneError.KintoneAllRecordsError
+	if !errors.As(err, &allErr) {
+		t.Fatalf("KintoneAllRecordsErrorが返るはずが: %v", err)
+	}
+	// サーバーでは追加済みのため、未処理に含めない
+	if allErr.NumOfProcessedRecords != 2000 || len(allErr.UnprocessedRecords) != 500 {
+		t.Errorf("期待される処理済み/未処理: 2000/500, 実際: %d/%d", allErr.NumOfProcessedRecords, len(allErr.UnprocessedRecords))
+	}
+}
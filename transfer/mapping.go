@@ -0,0 +1,178 @@
+package transfer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// writableFieldTypes はコピー先として書き込みできるフィールドタイプ（テーブルを除く）
+var writableFieldTypes = []string{
+	types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText, types.FieldTypeLink,
+	types.FieldTypeNumber, types.FieldTypeDate, types.FieldTypeTime, types.FieldTypeDateTime,
+	types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeDropDown, types.FieldTypeRadioButton,
+	types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect, types.FieldTypeFile,
+}
+
+// stringFieldTypes は値が文字列のフィールドタイプ（文字列のフィールドにコピーできる）
+var stringFieldTypes = []string{
+	types.FieldTypeRecordNumber, types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText,
+	types.FieldTypeLink, types.FieldTypeNumber, types.FieldTypeCalc, types.FieldTypeDate, types.FieldTypeTime,
+	types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime,
+	types.FieldTypeDropDown, types.FieldTypeRadioButton, types.FieldTypeStatus,
+}
+
+// fieldMap はコピー元のフィールドとコピー先のフィールドの対応
+type fieldMap struct {
+	source     string
+	target     string
+	sourceType string
+	targetType string
+	inner      []fieldMap // テーブルの場合はテーブル内のフィールドの対応
+}
+
+// mapper はコピー元とコピー先のフォームのフィールド定義からフィールドの対応を作成する
+type mapper struct {
+	source  *app.GetFormFieldsResult
+	target  *app.GetFormFieldsResult
+	mapping map[string]string
+	tableOf map[string]string // コピー先のテーブル内のフィールドコード → テーブルのフィールドコード
+	used    map[string]string // コピー先のフィールドコード → コピー元のフィールドコード
+}
+
+// buildMapping はフィールドの対応を作成する
+// Mappingで指定した対応は、コピーできない場合にエラーにする。
+// 指定していないフィールドは同じフィールドコードのフィールドに対応させ、コピー先が書き込みできないフィールドやテーブル外・内が異なるフィールドは読み飛ばす
+func buildMapping(source, target *app.GetFormFieldsResult, mapping map[string]string) ([]fieldMap, error) {
+	m := &mapper{
+		source:  source,
+		target:  target,
+		mapping: mapping,
+		tableOf: map[string]string{},
+		used:    map[string]string{},
+	}
+	for code, prop := range target.Properties {
+		for inner := range prop.Fields {
+			m.tableOf[inner] = code
+		}
+	}
+
+	sourceTableOf := map[string]string{}
+	for code, prop := range source.Properties {
+		for inner := range prop.Fields {
+			sourceTableOf[inner] = code
+		}
+	}
+	for code := range mapping {
+		if _, ok := source.Properties[code]; !ok && sourceTableOf[code] == "" {
+			return nil, fmt.Errorf("コピー元にフィールドが存在しません: %s", code)
+		}
+	}
+
+	var maps []fieldMap
+	for _, code := range sortedCodes(source.Properties) {
+		prop := source.Properties[code]
+		fm, ok, err := m.field(code, prop, target.Properties, "")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if prop.Type == types.FieldTypeSubtable {
+			targetFields := target.Properties[fm.target].Fields
+			for _, inner := range sortedCodes(prop.Fields) {
+				innerMap, ok, err := m.field(inner, prop.Fields[inner], targetFields, fm.target)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					fm.inner = append(fm.inner, innerMap)
+				}
+			}
+			if len(fm.inner) == 0 {
+				continue
+			}
+		}
+		maps = append(maps, fm)
+	}
+
+	// テーブルをコピーしない場合、テーブル内のフィールドのMappingは使われない
+	for code, targetCode := range mapping {
+		if _, ok := m.used[targetCode]; targetCode != "" && !ok {
+			return nil, fmt.Errorf("テーブル内のフィールドは、テーブルをコピーする場合のみ指定できます: %s", code)
+		}
+	}
+	return maps, nil
+}
+
+// field はコピー元のフィールドに対応するコピー先のフィールドを返す
+// targets はコピー先で対応させるフィールド定義（テーブル内のフィールドの場合はコピー先のテーブル内のフィールド）
+func (m *mapper) field(code string, prop app.FieldProperty, targets map[string]app.FieldProperty, table string) (fieldMap, bool, error) {
+	targetCode, explicit := m.mapping[code]
+	if !explicit {
+		targetCode = code
+	}
+	if targetCode == "" {
+		return fieldMap{}, false, nil
+	}
+
+	targetProp, ok := targets[targetCode]
+	if !ok {
+		if !explicit {
+			return fieldMap{}, false, nil
+		}
+		if _, exists := m.target.Properties[targetCode]; exists || m.tableOf[targetCode] != "" {
+			if table == "" {
+				return fieldMap{}, false, fmt.Errorf("テーブル外のフィールドはテーブル外のフィールドに対応させてください: %s → %s", code, targetCode)
+			}
+			return fieldMap{}, false, fmt.Errorf("テーブル内のフィールドは対応するテーブル（%s）内のフィールドに対応させてください: %s → %s", table, code, targetCode)
+		}
+		return fieldMap{}, false, fmt.Errorf("コピー先にフィールドが存在しません: %s → %s", code, targetCode)
+	}
+
+	if targetProp.Type != types.FieldTypeSubtable && !slices.Contains(writableFieldTypes, targetProp.Type) {
+		if !explicit {
+			return fieldMap{}, false, nil
+		}
+		return fieldMap{}, false, fmt.Errorf("%sフィールドには書き込みできません: %s → %s", targetProp.Type, code, targetCode)
+	}
+	if !compatible(prop.Type, targetProp.Type) {
+		return fieldMap{}, false, fmt.Errorf("フィールドタイプが対応していません: %s（%s） → %s（%s）", code, prop.Type, targetCode, targetProp.Type)
+	}
+	if source, dup := m.used[targetCode]; dup {
+		return fieldMap{}, false, fmt.Errorf("コピー先のフィールドが重複しています: %s, %s → %s", source, code, targetCode)
+	}
+	m.used[targetCode] = code
+
+	return fieldMap{source: code, target: targetCode, sourceType: prop.Type, targetType: targetProp.Type}, true, nil
+}
+
+// compatible はコピー元のフィールドタイプの値をコピー先のフィールドタイプに書き込めるかどうかを返す
+func compatible(sourceType, targetType string) bool {
+	if sourceType == targetType {
+		return true
+	}
+	switch targetType {
+	case types.FieldTypeSingleLineText, types.FieldTypeMultiLineText:
+		return slices.Contains(stringFieldTypes, sourceType)
+	case types.FieldTypeDateTime:
+		return sourceType == types.FieldTypeCreatedTime || sourceType == types.FieldTypeUpdatedTime
+	case types.FieldTypeUserSelect:
+		return sourceType == types.FieldTypeCreator || sourceType == types.FieldTypeModifier
+	}
+	return false
+}
+
+// sortedCodes はフィールドコードを昇順で返す
+func sortedCodes(props map[string]app.FieldProperty) []string {
+	codes := make([]string, 0, len(props))
+	for code := range props {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,347 @@
+// Package transfer はアプリ間でレコードをコピーする機能を提供する
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/goqoo-on-kintone/goten"
+	"github.com/goqoo-on-kintone/goten/app"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/file"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// defaultChunkSize は1回に書き込むレコード数の既定値
+const defaultChunkSize = 500
+
+// Endpoint はコピー元またはコピー先のアプリと、アプリにアクセスするクライアント
+// コピー元とコピー先で別のクライアントを指定すると、異なるドメインや認証情報のアプリ間でコピーできる
+type Endpoint struct {
+	App    types.AppID
+	Form   *app.Client    // フォームのフィールド定義の取得
+	Record *record.Client // レコードの取得・書き込み
+	File   *file.Client   // 添付ファイルのダウンロード・アップロード
+}
+
+// NewEndpoint はgoten.Clientのクライアントを使うEndpointを作成する
+func NewEndpoint(client *goten.Client, appID types.AppID) Endpoint {
+	return Endpoint{
+		App:    appID,
+		Form:   client.App,
+		Record: client.Record,
+		File:   client.File,
+	}
+}
+
+// RecordsParams はRecordsのパラメータ
+type RecordsParams struct {
+	Source Endpoint
+	Target Endpoint
+
+	Condition string // コピーするレコードの条件（省略時は全レコード）
+	OrderBy   string // コピーする順序（省略時はレコードIDの昇順）
+
+	// Mapping はコピー元のフィールドコードからコピー先のフィールドコードへの対応（空文字列を指定したフィールドはコピーしない）
+	// 指定していないフィールドは、コピー先の同じフィールドコードのフィールドにコピーする
+	Mapping map[string]string
+
+	// KeyField を指定すると、コピー先でキーの値が同じレコードを更新し、存在しない場合は追加する（省略時は追加のみ）
+	// コピー先の重複禁止に設定したフィールドコードを指定する
+	KeyField string
+
+	ChunkSize int // 1回に書き込むレコード数（省略時は500）
+}
+
+// RecordsResult はRecordsの結果
+type RecordsResult struct {
+	Total   int // コピー元から読み込んだレコード数
+	Added   int // コピー先に追加したレコード数
+	Updated int // コピー先で更新したレコード数
+	Files   int // コピーした添付ファイル数
+
+	// FailedID は失敗したコピー元のレコードID（エラーがない場合、またはレコードを特定できない場合は空）
+	FailedID types.RecordID
+}
+
+// Records はコピー元のアプリのレコードをコピー先のアプリにコピーする
+//
+// 両アプリのフォームのフィールド定義からフィールドの対応を作成し、コピーできないMappingがある場合はレコードを読み込む前にエラーを返す。
+// 対応するフィールドは、同じフィールドタイプのほか、文字列の値を文字列（1行・複数行）のフィールドに、
+// 作成日時・更新日時を日時のフィールドに、作成者・更新者をユーザー選択のフィールドにコピーできる。
+// テーブルはコピー先のテーブルに新しい行として追加する
+//
+// 添付ファイルは、コピー元からダウンロードしてコピー先にアップロードし直したファイルキーで書き込む。
+// レコードはChunkSize件ずつ読み込んで書き込む。書き込みのバルクリクエスト単位の処理はrecordパッケージのドキュメントを参照。
+// 途中で失敗した場合は、それまでの結果とFailedIDを含むRecordsResultと、エラーを返す
+//
+// コピー元とコピー先が同じアプリの場合は、追加したレコードを読み込まないように、開始時点の最大のレコードIDまでをコピーする
+func Records(ctx context.Context, params RecordsParams) (*RecordsResult, error) {
+	if params.ChunkSize <= 0 {
+		params.ChunkSize = defaultChunkSize
+	}
+
+	sourceFields, err := params.Source.Form.GetFormFields(ctx, app.GetFormFieldsParams{App: params.Source.App})
+	if err != nil {
+		return nil, fmt.Errorf("コピー元のフォームの取得エラー: %w", err)
+	}
+	targetFields, err := params.Target.Form.GetFormFields(ctx, app.GetFormFieldsParams{App: params.Target.App})
+	if err != nil {
+		return nil, fmt.Errorf("コピー先のフォームの取得エラー: %w", err)
+	}
+	maps, err := buildMapping(sourceFields, targetFields, params.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(maps) == 0 {
+		return nil, fmt.Errorf("コピーするフィールドがありません")
+	}
+	if params.KeyField != "" && !hasTarget(maps, params.KeyField) {
+		return nil, fmt.Errorf("キーのフィールドにコピーするフィールドがありません: %s", params.KeyField)
+	}
+
+	fields := make([]string, len(maps))
+	for i, fm := range maps {
+		fields[i] = fm.source
+	}
+
+	condition := params.Condition
+	if params.Source.App == params.Target.App {
+		last, err := lastRecordID(ctx, params.Source, condition)
+		if err != nil {
+			return nil, fmt.Errorf("コピー元のレコードの取得エラー: %w", err)
+		}
+		condition = "$id <= " + last
+		if params.Condition != "" {
+			condition = "(" + params.Condition + ") and " + condition
+		}
+	}
+
+	c := &copier{params: params, result: &RecordsResult{}}
+	var chunk []map[string]types.FieldValue
+	var ids []types.RecordID
+	for rec, err := range record.AllRecords[record.Dynamic](ctx, params.Source.Record, record.GetAllRecordsParams{
+		App:       params.Source.App,
+		Fields:    fields,
+		Condition: condition,
+		OrderBy:   params.OrderBy,
+	}) {
+		if err != nil {
+			return c.result, fmt.Errorf("コピー元のレコードの取得エラー: %w", err)
+		}
+		c.result.Total++
+
+		converted, err := c.record(ctx, maps, rec)
+		if err != nil {
+			c.result.FailedID = rec.ID()
+			return c.result, fmt.Errorf("レコード %s のコピーエラー: %w", rec.ID(), err)
+		}
+		chunk = append(chunk, converted)
+		ids = append(ids, rec.ID())
+
+		if len(chunk) >= params.ChunkSize {
+			if err := c.write(ctx, chunk, ids); err != nil {
+				return c.result, err
+			}
+			chunk, ids = nil, nil
+		}
+	}
+	if len(chunk) > 0 {
+		if err := c.write(ctx, chunk, ids); err != nil {
+			return c.result, err
+		}
+	}
+	return c.result, nil
+}
+
+// lastRecordID は条件に一致するレコードの最大のレコードIDを返す（レコードがない場合は"0"）
+func lastRecordID(ctx context.Context, source Endpoint, condition string) (types.RecordID, error) {
+	q := "order by $id desc limit 1"
+	if condition != "" {
+		q = condition + " " + q
+	}
+	result, err := record.GetRecords[record.Dynamic](ctx, source.Record, record.GetRecordsParams{
+		App:    source.App,
+		Fields: []string{"$id"},
+		Query:  q,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Records) == 0 {
+		return "0", nil
+	}
+	return result.Records[0].ID(), nil
+}
+
+// hasTarget はコピー先のフィールドコードにコピーするフィールドがあるかどうかを返す
+func hasTarget(maps []fieldMap, code string) bool {
+	for _, fm := range maps {
+		if fm.target == code {
+			return true
+		}
+	}
+	return false
+}
+
+// copier はレコードを変換してコピー先に書き込む
+type copier struct {
+	params RecordsParams
+	result *RecordsResult
+}
+
+// record はコピー元のレコードをコピー先のフィールド値に変換する
+func (c *copier) record(ctx context.Context, maps []fieldMap, rec record.Dynamic) (map[string]types.FieldValue, error) {
+	converted := make(map[string]types.FieldValue, len(maps))
+	for _, fm := range maps {
+		raw := rec.Raw(fm.source)
+		if raw == nil {
+			continue
+		}
+		value, err := c.value(ctx, fm, raw)
+		if err != nil {
+			return nil, err
+		}
+		converted[fm.target] = types.FieldValue{Value: value}
+	}
+	return converted, nil
+}
+
+// value はフィールドの値をコピー先のフィールドタイプの値に変換する
+func (c *copier) value(ctx context.Context, fm fieldMap, raw json.RawMessage) (any, error) {
+	switch fm.targetType {
+	case types.FieldTypeSubtable:
+		var rows []struct {
+			Value map[string]struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("フィールド値の変換エラー: %s: %w", fm.source, err)
+		}
+		values := make([]map[string]any, len(rows))
+		for i, row := range rows {
+			cells := make(map[string]types.FieldValue, len(fm.inner))
+			for _, inner := range fm.inner {
+				cell, ok := row.Value[inner.source]
+				if !ok {
+					continue
+				}
+				value, err := c.value(ctx, inner, cell.Value)
+				if err != nil {
+					return nil, err
+				}
+				cells[inner.target] = types.FieldValue{Value: value}
+			}
+			values[i] = map[string]any{"value": cells}
+		}
+		return values, nil
+
+	case types.FieldTypeFile:
+		var files types.Files
+		if err := json.Unmarshal(raw, &files); err != nil {
+			return nil, fmt.Errorf("フィールド値の変換エラー: %s: %w", fm.source, err)
+		}
+		copied := make(types.Files, len(files))
+		for i, f := range files {
+			fileKey, err := c.copyFile(ctx, f)
+			if err != nil {
+				return nil, fmt.Errorf("添付ファイルのコピーエラー: %s: %s: %w", fm.source, f.Name, err)
+			}
+			copied[i] = types.File{FileKey: fileKey}
+		}
+		return copied, nil
+
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect:
+		var entities types.Entities
+		if fm.sourceType == types.FieldTypeCreator || fm.sourceType == types.FieldTypeModifier {
+			var entity types.Entity
+			if err := json.Unmarshal(raw, &entity); err != nil {
+				return nil, fmt.Errorf("フィールド値の変換エラー: %s: %w", fm.source, err)
+			}
+			entities = types.Entities{entity}
+		} else if err := json.Unmarshal(raw, &entities); err != nil {
+			return nil, fmt.Errorf("フィールド値の変換エラー: %s: %w", fm.source, err)
+		}
+		codes := make(types.Entities, len(entities))
+		for i, entity := range entities {
+			codes[i] = types.Entity{Code: entity.Code}
+		}
+		return codes, nil
+	}
+	return raw, nil
+}
+
+// copyFile は添付ファイルをコピー元からダウンロードしてコピー先にアップロードし、コピー先のファイルキーを返す
+func (c *copier) copyFile(ctx context.Context, f types.File) (string, error) {
+	body, err := c.params.Source.File.Download(ctx, file.DownloadParams{FileKey: f.FileKey})
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	uploaded, err := c.params.Target.File.Upload(ctx, file.UploadParams{FileName: f.Name, Reader: body})
+	if err != nil {
+		return "", err
+	}
+	c.result.Files++
+	return uploaded.FileKey, nil
+}
+
+// write はレコードをコピー先に書き込む
+// idsは各レコードのコピー元のレコードID
+func (c *copier) write(ctx context.Context, records []map[string]types.FieldValue, ids []types.RecordID) error {
+	target := c.params.Target
+	if c.params.KeyField == "" {
+		added, err := target.Record.AddAllRecords(ctx, record.AddAllRecordsParams{App: target.App, Records: records})
+		if err != nil {
+			var allErr *kintoneError.KintoneAllRecordsError
+			if errors.As(err, &allErr) {
+				if processed, ok := allErr.ProcessedRecords.(*record.AddAllRecordsResult); ok {
+					c.result.Added += len(processed.IDs)
+				}
+				c.failed(allErr, ids)
+			}
+			return err
+		}
+		c.result.Added += len(added.IDs)
+		return nil
+	}
+
+	upserted, err := target.Record.UpsertRecords(ctx, record.UpsertRecordsParams{App: target.App, KeyField: c.params.KeyField, Records: records})
+	if err != nil {
+		var allErr *kintoneError.KintoneAllRecordsError
+		if errors.As(err, &allErr) {
+			if processed, ok := allErr.ProcessedRecords.(*record.UpsertRecordsResult); ok {
+				c.countUpserted(processed)
+			}
+			c.failed(allErr, ids)
+		}
+		return err
+	}
+	c.countUpserted(upserted)
+	return nil
+}
+
+// countUpserted はUpsertRecordsの結果から追加・更新したレコード数を数える
+func (c *copier) countUpserted(upserted *record.UpsertRecordsResult) {
+	for _, r := range upserted.Records {
+		switch {
+		case r.ID == "":
+		case r.Added:
+			c.result.Added++
+		default:
+			c.result.Updated++
+		}
+	}
+}
+
+// failed は書き込みに失敗したレコードのコピー元のレコードIDを記録する
+func (c *copier) failed(err *kintoneError.KintoneAllRecordsError, ids []types.RecordID) {
+	if err.ErrorIndex >= 0 && err.ErrorIndex < len(ids) {
+		c.result.FailedID = ids[err.ErrorIndex]
+	}
+}
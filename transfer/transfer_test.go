@@ -0,0 +1,281 @@
+package transfer_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/file"
+	"github.com/goqoo-on-kintone/goten/kintonetest"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/transfer"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// transferFixture はコピー元（ID: 1）とコピー先（ID: 2）のアプリ
+// レコード番号・作成者などのシステムのフィールドはサーバーが追加する
+const transferFixture = `{"apps": [
+	{
+		"id": "1",
+		"name": "経費申請",
+		"fields": {
+			"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名"},
+			"金額": {"type": "NUMBER", "code": "金額", "label": "金額"},
+			"添付": {"type": "FILE", "code": "添付", "label": "添付"},
+			"明細": {"type": "SUBTABLE", "code": "明細", "label": "明細", "fields": {
+				"品名": {"type": "SINGLE_LINE_TEXT", "code": "品名", "label": "品名"},
+				"領収書": {"type": "FILE", "code": "領収書", "label": "領収書"}
+			}}
+		}
+	},
+	{
+		"id": "2",
+		"name": "経費申請（移行先）",
+		"fields": {
+			"旧レコード番号": {"type": "SINGLE_LINE_TEXT", "code": "旧レコード番号", "label": "旧レコード番号", "unique": true},
+			"申請者": {"type": "USER_SELECT", "code": "申請者", "label": "申請者"},
+			"件名": {"type": "SINGLE_LINE_TEXT", "code": "件名", "label": "件名"},
+			"添付": {"type": "FILE", "code": "添付", "label": "添付"},
+			"明細": {"type": "SUBTABLE", "code": "明細", "label": "明細", "fields": {
+				"品名": {"type": "SINGLE_LINE_TEXT", "code": "品名", "label": "品名"},
+				"領収書": {"type": "FILE", "code": "領収書", "label": "領収書"}
+			}}
+		}
+	}
+]}`
+
+func TestRecords(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(transferFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+	ctx := context.Background()
+
+	// 添付ファイルはアップロードしてからレコードに添付する
+	uploaded := map[string]string{}
+	for name, content := range map[string]string{"申請書.pdf": "申請書", "領収書.png": "領収書"} {
+		result, err := client.File.Upload(ctx, file.UploadParams{FileName: name, Reader: strings.NewReader(content)})
+		if err != nil {
+			t.Fatalf("エラーが発生: %v", err)
+		}
+		uploaded[name] = result.FileKey
+	}
+	if _, err := client.Record.AddRecords(ctx, record.AddRecordsParams{
+		App: "1",
+		Records: []map[string]types.FieldValue{
+			{
+				"作成者": {Value: map[string]string{"code": "user1", "name": "佐藤"}},
+				"件名":  {Value: "出張費"},
+				"金額":  {Value: "12000"},
+				"添付":  {Value: []map[string]string{{"fileKey": uploaded["申請書.pdf"]}}},
+				"明細": {Value: []map[string]any{{"value": map[string]any{
+					"品名":  map[string]any{"value": "航空券"},
+					"領収書": map[string]any{"value": []map[string]string{{"fileKey": uploaded["領収書.png"]}}},
+				}}}},
+			},
+			{
+				"作成者": {Value: map[string]string{"code": "user2", "name": "鈴木"}},
+				"件名":  {Value: "文具"},
+				"金額":  {Value: "500"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	result, err := transfer.Records(ctx, transfer.RecordsParams{
+		Source: transfer.NewEndpoint(client, "1"),
+		Target: transfer.NewEndpoint(client, "2"),
+		Mapping: map[string]string{
+			"レコード番号": "旧レコード番号",
+			"作成者":    "申請者",
+		},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Total != 2 || result.Added != 2 || result.Files != 2 {
+		t.Errorf("期待される結果: 読み込み2件 追加2件 添付ファイル2件, 実際: %+v", result)
+	}
+
+	records := server.Records("2")
+	if len(records) != 2 {
+		t.Fatalf("期待されるレコード数: 2, 実際: %d", len(records))
+	}
+	rec := records[0]
+	tests := []struct {
+		name string
+		got  func() (string, error)
+		want string
+	}{
+		{"旧レコード番号", func() (string, error) { return rec.String("旧レコード番号") }, "1"},
+		{"申請者", func() (string, error) {
+			users, err := rec.Users("申請者")
+			return strings.Join(users.Codes(), ","), err
+		}, "user1"},
+		{"件名", func() (string, error) { return rec.String("件名") }, "出張費"},
+		{"添付", func() (string, error) {
+			files, err := rec.Files("添付")
+			return attachedFiles(server, files), err
+		}, "申請書.pdf:申請書"},
+		{"明細", func() (string, error) {
+			rows, err := rec.Subtable("明細")
+			cells := make([]string, len(rows))
+			for i, row := range rows {
+				name, _ := row.Record.String("品名")
+				files, _ := row.Record.Files("領収書")
+				cells[i] = name + "/" + attachedFiles(server, files)
+			}
+			return strings.Join(cells, ","), err
+		}, "航空券/領収書.png:領収書"},
+		{"2件目の旧レコード番号", func() (string, error) { return records[1].String("旧レコード番号") }, "2"},
+	}
+	for _, tt := range tests {
+		got, err := tt.got()
+		if err != nil {
+			t.Errorf("%s: エラーが発生: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: 期待される値: %s, 実際: %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+// attachedFiles はサーバーに保存された添付ファイルを「ファイル名:内容」の形式で並べる
+func attachedFiles(server *kintonetest.Server, files types.Files) string {
+	list := make([]string, len(files))
+	for i, f := range files {
+		data, _ := server.File(f.FileKey)
+		list[i] = f.Name + ":" + string(data)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestRecordsChunkSize(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(transferFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+	ctx := context.Background()
+	if _, err := client.Record.AddRecords(ctx, record.AddRecordsParams{
+		App:     "1",
+		Records: []map[string]types.FieldValue{{"件名": {Value: "出張費"}}, {"件名": {Value: "文具"}}},
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	result, err := transfer.Records(ctx, transfer.RecordsParams{
+		Source:    transfer.NewEndpoint(client, "1"),
+		Target:    transfer.NewEndpoint(client, "2"),
+		Mapping:   map[string]string{"添付": "", "明細": ""},
+		ChunkSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Added != 2 || result.Files != 0 {
+		t.Errorf("期待される結果: 追加2件 添付ファイル0件, 実際: %+v", result)
+	}
+	records := server.Records("2")
+	if len(records) != 2 {
+		t.Fatalf("期待されるレコード数: 2, 実際: %d", len(records))
+	}
+	for i, want := range []string{"出張費", "文具"} {
+		if got, _ := records[i].String("件名"); got != want {
+			t.Errorf("期待される件名: %s, 実際: %s", want, got)
+		}
+	}
+}
+
+func TestRecordsSameApp(t *testing.T) {
+	// 1ページ（500件）を超えるレコードで、読み込み中に追加したレコードを読み込まないことを確認する
+	records := make([]map[string]json.RawMessage, 600)
+	for i := range records {
+		records[i] = map[string]json.RawMessage{"件名": json.RawMessage(fmt.Sprintf(`{"value": "件名%d"}`, i))}
+	}
+	server, err := kintonetest.NewServer(kintonetest.App{
+		ID:      "1",
+		Name:    "受注",
+		Fields:  map[string]app.FieldProperty{"件名": {Type: "SINGLE_LINE_TEXT"}},
+		Records: records,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+
+	client := server.Client()
+	result, err := transfer.Records(context.Background(), transfer.RecordsParams{
+		Source:    transfer.NewEndpoint(client, "1"),
+		Target:    transfer.NewEndpoint(client, "1"),
+		ChunkSize: 100,
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if result.Total != 600 || result.Added != 600 {
+		t.Errorf("期待される結果: 読み込み600件 追加600件, 実際: %+v", result)
+	}
+	if n := len(server.Records("1")); n != 1200 {
+		t.Errorf("期待されるレコード数: 1200, 実際: %d", n)
+	}
+}
+
+func TestRecordsMappingError(t *testing.T) {
+	server, err := kintonetest.NewServer()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	defer server.Close()
+	if err := server.LoadFixture([]byte(transferFixture)); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := server.Client()
+	if _, err := client.Record.AddRecords(context.Background(), record.AddRecordsParams{
+		App:     "1",
+		Records: []map[string]types.FieldValue{{"件名": {Value: "出張費"}}},
+	}); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    string
+	}{
+		{"存在しないフィールド", map[string]string{"件名": "タイトル"}, "コピー先にフィールドが存在しません"},
+		{"フィールドタイプの不一致", map[string]string{"金額": "添付", "添付": ""}, "フィールドタイプが対応していません"},
+		{"書き込みできないフィールド", map[string]string{"件名": "レコード番号"}, "書き込みできません"},
+		{"テーブル外からテーブル内", map[string]string{"件名": "品名", "品名": ""}, "テーブル外のフィールド"},
+		{"コピー先の重複", map[string]string{"作成者": "申請者", "レコード番号": "件名"}, "コピー先のフィールドが重複しています"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transfer.Records(context.Background(), transfer.RecordsParams{
+				Source:  transfer.NewEndpoint(client, "1"),
+				Target:  transfer.NewEndpoint(client, "2"),
+				Mapping: tt.mapping,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("期待されるエラー: %s, 実際: %v", tt.want, err)
+			}
+		})
+	}
+	if n := len(server.Records("2")); n != 0 {
+		t.Errorf("コピー先にレコードが書き込まれている: %d件", n)
+	}
+}
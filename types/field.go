@@ -0,0 +1,235 @@
+package types
+
+import "encoding/json"
+
+// フィールドタイプ
+const (
+	FieldTypeRecordNumber       = "RECORD_NUMBER"
+	FieldTypeID                 = "__ID__"
+	FieldTypeRevision           = "__REVISION__"
+	FieldTypeCreator            = "CREATOR"
+	FieldTypeCreatedTime        = "CREATED_TIME"
+	FieldTypeModifier           = "MODIFIER"
+	FieldTypeUpdatedTime        = "UPDATED_TIME"
+	FieldTypeSingleLineText     = "SINGLE_LINE_TEXT"
+	FieldTypeMultiLineText      = "MULTI_LINE_TEXT"
+	FieldTypeRichText           = "RICH_TEXT"
+	FieldTypeNumber             = "NUMBER"
+	FieldTypeCalc               = "CALC"
+	FieldTypeCheckBox           = "CHECK_BOX"
+	FieldTypeRadioButton        = "RADIO_BUTTON"
+	FieldTypeMultiSelect        = "MULTI_SELECT"
+	FieldTypeDropDown           = "DROP_DOWN"
+	FieldTypeUserSelect         = "USER_SELECT"
+	FieldTypeOrganizationSelect = "ORGANIZATION_SELECT"
+	FieldTypeGroupSelect        = "GROUP_SELECT"
+	FieldTypeDate               = "DATE"
+	FieldTypeTime               = "TIME"
+	FieldTypeDateTime           = "DATETIME"
+	FieldTypeLink               = "LINK"
+	FieldTypeFile               = "FILE"
+	FieldTypeSubtable           = "SUBTABLE"
+	FieldTypeCategory           = "CATEGORY"
+	FieldTypeStatus             = "STATUS"
+	FieldTypeStatusAssignee     = "STATUS_ASSIGNEE"
+)
+
+// --- システムフィールド ---
+
+// IDField はレコードID（$id）
+type IDField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// RevisionField はリビジョン（$revision）
+type RevisionField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// RecordNumberField はレコード番号
+type RecordNumberField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// CreatorField は作成者
+type CreatorField struct {
+	Type  string `json:"type,omitempty"`
+	Value Entity `json:"value"`
+}
+
+// CreatedTimeField は作成日時
+type CreatedTimeField struct {
+	Type  string   `json:"type,omitempty"`
+	Value DateTime `json:"value"`
+}
+
+// ModifierField は更新者
+type ModifierField struct {
+	Type  string `json:"type,omitempty"`
+	Value Entity `json:"value"`
+}
+
+// UpdatedTimeField は更新日時
+type UpdatedTimeField struct {
+	Type  string   `json:"type,omitempty"`
+	Value DateTime `json:"value"`
+}
+
+// StatusField はプロセス管理のステータス
+type StatusField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// StatusAssigneeField はプロセス管理の作業者
+type StatusAssigneeField struct {
+	Type  string   `json:"type,omitempty"`
+	Value Entities `json:"value"`
+}
+
+// CategoryField はカテゴリー
+type CategoryField struct {
+	Type  string  `json:"type,omitempty"`
+	Value Strings `json:"value"`
+}
+
+// --- 文字列 ---
+
+// SingleLineTextField は文字列（1行）
+type SingleLineTextField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// MultiLineTextField は文字列（複数行）
+type MultiLineTextField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// RichTextField はリッチエディター（HTML）
+type RichTextField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// LinkField はリンク
+type LinkField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// --- 数値・計算 ---
+
+// NumberField は数値
+type NumberField struct {
+	Type  string  `json:"type,omitempty"`
+	Value Decimal `json:"value"`
+}
+
+// CalcField は計算（書式により数値・日付・時刻などの文字列になる）
+type CalcField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// --- 選択 ---
+
+// CheckBoxField はチェックボックス
+type CheckBoxField struct {
+	Type  string  `json:"type,omitempty"`
+	Value Strings `json:"value"`
+}
+
+// MultiSelectField は複数選択
+type MultiSelectField struct {
+	Type  string  `json:"type,omitempty"`
+	Value Strings `json:"value"`
+}
+
+// RadioButtonField はラジオボタン
+type RadioButtonField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// DropDownField はドロップダウン（未選択の場合は空文字列）
+type DropDownField struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// UserSelectField はユーザー選択
+type UserSelectField struct {
+	Type  string   `json:"type,omitempty"`
+	Value Entities `json:"value"`
+}
+
+// OrganizationSelectField は組織選択
+type OrganizationSelectField struct {
+	Type  string   `json:"type,omitempty"`
+	Value Entities `json:"value"`
+}
+
+// GroupSelectField はグループ選択
+type GroupSelectField struct {
+	Type  string   `json:"type,omitempty"`
+	Value Entities `json:"value"`
+}
+
+// --- 日付・時刻 ---
+
+// DateField は日付
+type DateField struct {
+	Type  string `json:"type,omitempty"`
+	Value Date   `json:"value"`
+}
+
+// TimeField は時刻
+type TimeField struct {
+	Type  string    `json:"type,omitempty"`
+	Value TimeOfDay `json:"value"`
+}
+
+// DateTimeField は日時
+type DateTimeField struct {
+	Type  string   `json:"type,omitempty"`
+	Value DateTime `json:"value"`
+}
+
+// --- ファイル・テーブル ---
+
+// FileField は添付ファイル
+type FileField struct {
+	Type  string `json:"type,omitempty"`
+	Value Files  `json:"value"`
+}
+
+// SubtableRow はテーブルの行
+// 既存の行を更新する場合はIDを指定する。IDを省略した行は新規に追加される
+type SubtableRow[T any] struct {
+	ID    string `json:"id,omitempty"`
+	Value T      `json:"value"`
+}
+
+// SubtableField はテーブル
+// Tには行のフィールドを定義した構造体を指定する
+type SubtableField[T any] struct {
+	Type  string           `json:"type,omitempty"`
+	Value []SubtableRow[T] `json:"value"`
+}
+
+// MarshalJSON はJSONに変換する（行がない場合も空配列にする）
+func (f SubtableField[T]) MarshalJSON() ([]byte, error) {
+	rows := f.Value
+	if rows == nil {
+		rows = []SubtableRow[T]{}
+	}
+	return json.Marshal(struct {
+		Type  string           `json:"type,omitempty"`
+		Value []SubtableRow[T] `json:"value"`
+	}{f.Type, rows})
+}
@@ -0,0 +1,214 @@
+package types_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// testRecord はテスト用のレコード構造体
+type testRecord struct {
+	ID       types.IDField                 `json:"$id"`
+	Creator  types.CreatorField            `json:"作成者"`
+	Created  types.CreatedTimeField        `json:"作成日時"`
+	Title    types.SingleLineTextField     `json:"タイトル"`
+	Amount   types.NumberField             `json:"金額"`
+	Due      types.DateField               `json:"期限"`
+	Start    types.TimeField               `json:"開始時刻"`
+	Tags     types.CheckBoxField           `json:"タグ"`
+	Owner    types.UserSelectField         `json:"担当者"`
+	Files    types.FileField               `json:"添付ファイル"`
+	Category types.CategoryField           `json:"カテゴリー"`
+	Items    types.SubtableField[testItem] `json:"明細"`
+}
+
+type testItem struct {
+	Product types.SingleLineTextField `json:"品名"`
+	Price   types.NumberField         `json:"単価"`
+}
+
+const testRecordJSON = `{
+	"$id": {"type": "__ID__", "value": "1"},
+	"作成者": {"type": "CREATOR", "value": {"code": "user1", "name": "ユーザー1"}},
+	"作成日時": {"type": "CREATED_TIME", "value": "2024-01-02T03:04:00Z"},
+	"タイトル": {"type": "SINGLE_LINE_TEXT", "value": "テスト"},
+	"金額": {"type": "NUMBER", "value": "12345678901234567890.12"},
+	"期限": {"type": "DATE", "value": "2024-03-31"},
+	"開始時刻": {"type": "TIME", "value": "09:30"},
+	"タグ": {"type": "CHECK_BOX", "value": ["A", "B"]},
+	"担当者": {"type": "USER_SELECT", "value": [{"code": "user1", "name": "ユーザー1"}]},
+	"添付ファイル": {"type": "FILE", "value": [{"contentType": "text/plain", "fileKey": "key1", "name": "a.txt", "size": "10"}]},
+	"カテゴリー": {"type": "CATEGORY", "value": ["分類1"]},
+	"明細": {"type": "SUBTABLE", "value": [
+		{"id": "100", "value": {
+			"品名": {"type": "SINGLE_LINE_TEXT", "value": "りんご"},
+			"単価": {"type": "NUMBER", "value": "120"}
+		}}
+	]}
+}`
+
+func TestUnmarshalRecord(t *testing.T) {
+	var rec testRecord
+	if err := json.Unmarshal([]byte(testRecordJSON), &rec); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	if rec.Creator.Value.Code != "user1" {
+		t.Errorf("期待される作成者: user1, 実際: %s", rec.Creator.Value.Code)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC); !rec.Created.Value.Equal(want) {
+		t.Errorf("期待される作成日時: %v, 実際: %v", want, rec.Created.Value)
+	}
+	amount, err := rec.Amount.Value.Rat()
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if want, _ := new(big.Rat).SetString("12345678901234567890.12"); amount.Cmp(want) != 0 {
+		t.Errorf("期待される金額: %s, 実際: %s", want.FloatString(2), amount.FloatString(2))
+	}
+	if rec.Due.Value.String() != "2024-03-31" || rec.Due.Value.Month() != time.March {
+		t.Errorf("期待される期限: 2024-03-31, 実際: %s", rec.Due.Value)
+	}
+	if !rec.Start.Value.Valid || rec.Start.Value.Hour() != 9 || rec.Start.Value.Minute() != 30 {
+		t.Errorf("期待される開始時刻: 09:30, 実際: %s", rec.Start.Value)
+	}
+	if len(rec.Tags.Value) != 2 {
+		t.Errorf("期待されるタグの件数: 2, 実際: %d", len(rec.Tags.Value))
+	}
+	if codes := rec.Owner.Value.Codes(); len(codes) != 1 || codes[0] != "user1" {
+		t.Errorf("期待される担当者: [user1], 実際: %v", codes)
+	}
+	if rec.Files.Value[0].FileKey != "key1" {
+		t.Errorf("期待されるfileKey: key1, 実際: %s", rec.Files.Value[0].FileKey)
+	}
+	if len(rec.Items.Value) != 1 || rec.Items.Value[0].ID != "100" {
+		t.Fatalf("期待される行ID: 100, 実際: %v", rec.Items.Value)
+	}
+	if rec.Items.Value[0].Value.Product.Value != "りんご" {
+		t.Errorf("期待される品名: りんご, 実際: %s", rec.Items.Value[0].Value.Product.Value)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	var rec testRecord
+	if err := json.Unmarshal([]byte(testRecordJSON), &rec); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+
+	var got, want map[string]any
+	json.Unmarshal(data, &got)
+	json.Unmarshal([]byte(testRecordJSON), &want)
+
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("期待されるJSON: %s, 実際: %s", wantJSON, gotJSON)
+	}
+}
+
+func TestEmptyValues(t *testing.T) {
+	const input = `{
+		"金額": {"type": "NUMBER", "value": ""},
+		"期限": {"type": "DATE", "value": null},
+		"開始時刻": {"type": "TIME", "value": ""},
+		"タグ": {"type": "CHECK_BOX", "value": []},
+		"明細": {"type": "SUBTABLE", "value": []}
+	}`
+	var rec testRecord
+	if err := json.Unmarshal([]byte(input), &rec); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if !rec.Amount.Value.IsEmpty() || !rec.Due.Value.IsZero() || rec.Start.Value.Valid {
+		t.Errorf("空値が未入力として扱われていない: %+v", rec)
+	}
+
+	data, err := json.Marshal(testRecord{})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	var got map[string]map[string]any
+	json.Unmarshal(data, &got)
+
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"金額", `""`},
+		{"期限", `null`},
+		{"開始時刻", `null`},
+		{"作成日時", `null`},
+		{"タグ", `[]`},
+		{"担当者", `[]`},
+		{"添付ファイル", `[]`},
+		{"明細", `[]`},
+	}
+	for _, tt := range tests {
+		value, _ := json.Marshal(got[tt.code]["value"])
+		if string(value) != tt.want {
+			t.Errorf("%s: 期待される値: %s, 実際: %s", tt.code, tt.want, value)
+		}
+		if _, ok := got[tt.code]["type"]; ok {
+			t.Errorf("%s: 空のtypeが出力されている", tt.code)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	var d types.Decimal
+	if err := json.Unmarshal([]byte(`123.45`), &d); err != nil || d != "123.45" {
+		t.Errorf("数値から変換できない: %q, %v", d, err)
+	}
+	if err := json.Unmarshal([]byte(`"-1e3"`), &d); err != nil || d != "-1e3" {
+		t.Errorf("文字列から変換できない: %q, %v", d, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &d); err == nil {
+		t.Error("数値でない文字列でエラーが発生しない")
+	}
+	if _, err := types.ParseDecimal("1.2.3"); err == nil {
+		t.Error("不正な数値でエラーが発生しない")
+	}
+	if got := types.DecimalFromFloat(0.1); got != "0.1" {
+		t.Errorf("期待される値: 0.1, 実際: %s", got)
+	}
+	if got := types.DecimalFromRat(big.NewRat(1, 3), 3); got != "0.333" {
+		t.Errorf("期待される値: 0.333, 実際: %s", got)
+	}
+	if n, err := types.DecimalFromInt(42).Int64(); err != nil || n != 42 {
+		t.Errorf("期待される値: 42, 実際: %d, %v", n, err)
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	dt := types.DateTime{Time: time.Date(2024, 1, 1, 9, 0, 0, 0, jst)}
+	data, _ := json.Marshal(dt)
+	if string(data) != `"2024-01-01T00:00:00Z"` {
+		t.Errorf("期待される日時: \"2024-01-01T00:00:00Z\", 実際: %s", data)
+	}
+
+	var parsed types.DateTime
+	if err := json.Unmarshal([]byte(`"2024-01-01T09:00+09:00"`), &parsed); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if !parsed.Equal(dt.Time) {
+		t.Errorf("期待される日時: %v, 実際: %v", dt.Time, parsed.Time)
+	}
+	if err := json.Unmarshal([]byte(`"2024/01/01"`), &parsed); err == nil {
+		t.Error("不正な日時でエラーが発生しない")
+	}
+
+	var date types.Date
+	if err := json.Unmarshal([]byte(`"2024-13-01"`), &date); err == nil {
+		t.Error("不正な日付でエラーが発生しない")
+	}
+	if data, _ := json.Marshal(types.NewTimeOfDay(8, 5)); string(data) != `"08:05"` {
+		t.Errorf("期待される時刻: \"08:05\", 実際: %s", data)
+	}
+}
@@ -0,0 +1,311 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// isEmptyJSON はJSONの値がnullまたは空文字列かどうかを返す
+func isEmptyJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`))
+}
+
+// --- 数値 ---
+
+// Decimal は数値フィールドの値
+// 桁落ちを防ぐため、kintoneと同じく10進数の文字列で保持する。空文字列は未入力を表す
+type Decimal string
+
+// decimalPattern は数値として有効な文字列
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// ParseDecimal は文字列をDecimalに変換する
+func ParseDecimal(s string) (Decimal, error) {
+	if s != "" && !decimalPattern.MatchString(s) {
+		return "", fmt.Errorf("数値ではありません: %q", s)
+	}
+	return Decimal(s), nil
+}
+
+// DecimalFromInt は整数からDecimalを作成する
+func DecimalFromInt(n int64) Decimal {
+	return Decimal(strconv.FormatInt(n, 10))
+}
+
+// DecimalFromFloat は浮動小数点数からDecimalを作成する
+func DecimalFromFloat(f float64) Decimal {
+	return Decimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// DecimalFromRat は有理数から小数点以下precision桁のDecimalを作成する
+func DecimalFromRat(r *big.Rat, precision int) Decimal {
+	return Decimal(r.FloatString(precision))
+}
+
+// String は数値の文字列を返す
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// IsEmpty は未入力かどうかを返す
+func (d Decimal) IsEmpty() bool {
+	return d == ""
+}
+
+// Float64 はfloat64に変換する（精度が失われる可能性がある）
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// Int64 はint64に変換する（小数部がある場合はエラー）
+func (d Decimal) Int64() (int64, error) {
+	return strconv.ParseInt(string(d), 10, 64)
+}
+
+// Rat は精度を保ったまま有理数に変換する
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, fmt.Errorf("数値ではありません: %q", string(d))
+	}
+	return r, nil
+}
+
+// UnmarshalJSON は文字列、数値、nullのいずれも受け付ける
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		*d = ""
+		return nil
+	}
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return err
+		}
+		parsed, err := ParseDecimal(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+	parsed, err := ParseDecimal(string(trimmed))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// --- 日付・時刻 ---
+
+// 日付・時刻の書式
+const (
+	DateFormat     = "2006-01-02"
+	TimeFormat     = "15:04"
+	DateTimeFormat = "2006-01-02T15:04:05Z"
+)
+
+// Date は日付フィールドの値
+// ゼロ値は未入力を表し、nullとしてJSONに変換される
+type Date struct {
+	time.Time
+}
+
+// NewDate は年月日からDateを作成する
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// String は「2006-01-02」形式の文字列を返す（未入力の場合は空文字列）
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateFormat)
+}
+
+// MarshalJSON はJSONに変換する
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON はJSONから変換する（nullと空文字列は未入力）
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if isEmptyJSON(data) {
+		*d = Date{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		return fmt.Errorf("日付の形式が不正です: %q", s)
+	}
+	*d = Date{Time: t}
+	return nil
+}
+
+// TimeOfDay は時刻フィールドの値（日付部分は使用しない）
+// Validがfalseの場合は未入力を表し、nullとしてJSONに変換される
+type TimeOfDay struct {
+	time.Time
+	Valid bool
+}
+
+// NewTimeOfDay は時と分からTimeOfDayを作成する
+func NewTimeOfDay(hour, minute int) TimeOfDay {
+	return TimeOfDay{Time: time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC), Valid: true}
+}
+
+// String は「15:04」形式の文字列を返す（未入力の場合は空文字列）
+func (t TimeOfDay) String() string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Format(TimeFormat)
+}
+
+// MarshalJSON はJSONに変換する
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON はJSONから変換する（nullと空文字列は未入力）
+func (t *TimeOfDay) UnmarshalJSON(data []byte) error {
+	if isEmptyJSON(data) {
+		*t = TimeOfDay{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(TimeFormat, s)
+	if err != nil {
+		return fmt.Errorf("時刻の形式が不正です: %q", s)
+	}
+	*t = TimeOfDay{Time: parsed, Valid: true}
+	return nil
+}
+
+// DateTime は日時フィールドの値
+// ゼロ値は未入力を表し、nullとしてJSONに変換される。JSONへの変換時はUTCで出力する
+type DateTime struct {
+	time.Time
+}
+
+// String は「2006-01-02T15:04:05Z」形式の文字列を返す（未入力の場合は空文字列）
+func (d DateTime) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.UTC().Format(DateTimeFormat)
+}
+
+// MarshalJSON はJSONに変換する
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON はJSONから変換する（nullと空文字列は未入力）
+// 秒を省略した形式（2006-01-02T15:04Z）も受け付ける
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	if isEmptyJSON(data) {
+		*d = DateTime{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02T15:04Z07:00", s)
+		if err != nil {
+			return fmt.Errorf("日時の形式が不正です: %q", s)
+		}
+	}
+	*d = DateTime{Time: t}
+	return nil
+}
+
+// --- 複数値 ---
+
+// Strings はチェックボックスなどの文字列の配列
+// nilの場合も空配列としてJSONに変換される
+type Strings []string
+
+// MarshalJSON はJSONに変換する
+func (s Strings) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(s))
+}
+
+// Entity はユーザー・組織・グループ
+type Entity struct {
+	Code string `json:"code"`
+	Name string `json:"name,omitempty"` // 書き込み時は不要
+}
+
+// Entities はユーザー選択などのエンティティの配列
+// nilの場合も空配列としてJSONに変換される
+type Entities []Entity
+
+// MarshalJSON はJSONに変換する
+func (e Entities) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Entity(e))
+}
+
+// Codes はエンティティのコード一覧を返す
+func (e Entities) Codes() []string {
+	codes := make([]string, len(e))
+	for i, entity := range e {
+		codes[i] = entity.Code
+	}
+	return codes
+}
+
+// File は添付ファイル
+// 取得時のFileKeyはダウンロード用、書き込み時はアップロードで取得したFileKeyを指定する
+type File struct {
+	ContentType string `json:"contentType,omitempty"`
+	FileKey     string `json:"fileKey"`
+	Name        string `json:"name,omitempty"`
+	Size        string `json:"size,omitempty"`
+}
+
+// Files は添付ファイルの配列
+// nilの場合も空配列としてJSONに変換される
+type Files []File
+
+// MarshalJSON はJSONに変換する
+func (f Files) MarshalJSON() ([]byte, error) {
+	if f == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]File(f))
+}
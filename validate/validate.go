@@ -0,0 +1,388 @@
+// Package validate はフォームの設定をもとに、レコードを書き込む前に入力内容を検証する
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// エラーメッセージ（kintoneの入力エラーと同じ形式）
+const (
+	validationMessage = "入力内容が正しくありません。"
+
+	msgRequired     = "必須です。"
+	msgUnknownField = "指定したフィールドが見つかりません。"
+	msgInvalidValue = "値の形式が不正です。"
+	msgNotNumber    = "数字でなければなりません。"
+	msgInvalidDate  = "日付の形式が不正です。"
+	msgInvalidTime  = "時刻の形式が不正です。"
+	msgInvalidDT    = "日時の形式が不正です。"
+	msgDuplicated   = "値がほかのレコードと重複しています。"
+)
+
+// dateTimeLayouts は日時フィールドで受け付ける形式
+var dateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+// Validator はフォームの設定をもとにレコードを検証する
+//
+// 必須項目、文字数・値の範囲、選択肢、日付・時刻の形式、存在しないフィールドコードを検証し、
+// サーバーの入力エラー（CB_VA01）と同じ形式の *KintoneRestAPIError を返す。
+// 重複禁止のフィールドは、複数のレコードを検証する場合にレコード間の重複のみを検証する（既存のレコードとの重複は検証しない）
+type Validator struct {
+	properties map[string]app.FieldProperty
+}
+
+// New はフォームのフィールドの設定から新しいValidatorを作成する
+func New(fields *app.GetFormFieldsResult) *Validator {
+	return &Validator{properties: fields.Properties}
+}
+
+// AddRecord はAddRecordで追加するレコードを検証する
+// 必須項目は、レコードに含まれていない場合も初期値がなければエラーとする
+func (v *Validator) AddRecord(rec map[string]types.FieldValue) error {
+	errs := errorSet{}
+	v.record(errs, "record", rec, true)
+	return errs.err()
+}
+
+// UpdateRecord はUpdateRecordで更新するレコードを検証する
+// レコードに含まれるフィールドのみを検証する
+func (v *Validator) UpdateRecord(rec map[string]types.FieldValue) error {
+	errs := errorSet{}
+	v.record(errs, "record", rec, false)
+	return errs.err()
+}
+
+// AddRecords はAddRecords（AddAllRecords）で追加するレコードを検証する
+// エラーのキーはレコードのインデックスを含む（records[0].フィールドコード.value）
+func (v *Validator) AddRecords(records []map[string]types.FieldValue) error {
+	errs := errorSet{}
+	prefixes := make([]string, len(records))
+	for i, rec := range records {
+		prefixes[i] = fmt.Sprintf("records[%d]", i)
+		v.record(errs, prefixes[i], rec, true)
+	}
+	v.unique(errs, prefixes, records)
+	return errs.err()
+}
+
+// UpdateRecords はUpdateRecords（UpdateAllRecords）で更新するレコードを検証する
+func (v *Validator) UpdateRecords(records []record.UpdateRecordItem) error {
+	errs := errorSet{}
+	prefixes := make([]string, len(records))
+	recs := make([]map[string]types.FieldValue, len(records))
+	for i, item := range records {
+		prefixes[i] = fmt.Sprintf("records[%d].record", i)
+		recs[i] = item.Record
+		v.record(errs, prefixes[i], item.Record, false)
+	}
+	v.unique(errs, prefixes, recs)
+	return errs.err()
+}
+
+// Messages はCB_VA01のエラーからキーごとのエラーメッセージを取り出す
+// サーバーの入力エラーとValidatorのエラーのどちらにも使用できる。それ以外のエラーの場合はnilを返す
+func Messages(err error) map[string][]string {
+	if !kintoneError.HasCode(err, kintoneError.CodeValidation) {
+		return nil
+	}
+	var apiErr *kintoneError.KintoneRestAPIError
+	errors.As(err, &apiErr)
+	messages := map[string][]string{}
+	for key, detail := range apiErr.Errors {
+		m, _ := detail.(map[string]any)
+		list, _ := m["messages"].([]any)
+		for _, msg := range list {
+			if s, ok := msg.(string); ok {
+				messages[key] = append(messages[key], s)
+			}
+		}
+	}
+	return messages
+}
+
+// record はレコードの各フィールドを検証する
+func (v *Validator) record(errs errorSet, prefix string, rec map[string]types.FieldValue, add bool) {
+	for code, fv := range rec {
+		key := prefix + "." + code + ".value"
+		prop, ok := v.properties[code]
+		if !ok {
+			errs.add(key, msgUnknownField)
+			continue
+		}
+		value, err := normalize(fv.Value)
+		if err != nil {
+			errs.add(key, msgInvalidValue)
+			continue
+		}
+		if prop.Type == types.FieldTypeSubtable {
+			v.subtable(errs, key, prop, value)
+			continue
+		}
+		field(errs, key, prop, value)
+	}
+	if add {
+		required(errs, prefix, v.properties, rec)
+	}
+}
+
+// subtable はテーブルの各行のフィールドを検証する
+// テーブルは行全体を書き込むため、行に含まれない必須項目もエラーとする
+func (v *Validator) subtable(errs errorSet, key string, prop app.FieldProperty, value any) {
+	if value == nil {
+		return
+	}
+	rows, ok := value.([]any)
+	if !ok {
+		errs.add(key, msgInvalidValue)
+		return
+	}
+	for i, row := range rows {
+		rowMap, _ := row.(map[string]any)
+		fields, ok := rowMap["value"].(map[string]any)
+		if !ok {
+			errs.add(fmt.Sprintf("%s[%d].value", key, i), msgInvalidValue)
+			continue
+		}
+		prefix := fmt.Sprintf("%s[%d].value", key, i)
+		present := map[string]types.FieldValue{}
+		for code, raw := range fields {
+			fieldKey := prefix + "." + code + ".value"
+			inner, ok := prop.Fields[code]
+			if !ok {
+				errs.add(fieldKey, msgUnknownField)
+				continue
+			}
+			cell, _ := raw.(map[string]any)
+			field(errs, fieldKey, inner, cell["value"])
+			present[code] = types.FieldValue{}
+		}
+		required(errs, prefix, prop.Fields, present)
+	}
+}
+
+// unique は重複禁止のフィールドの値がレコード間で重複していないかを検証する
+func (v *Validator) unique(errs errorSet, prefixes []string, records []map[string]types.FieldValue) {
+	for code, prop := range v.properties {
+		if !prop.Unique {
+			continue
+		}
+		seen := map[string]bool{}
+		for i, rec := range records {
+			fv, ok := rec[code]
+			if !ok {
+				continue
+			}
+			value, err := normalize(fv.Value)
+			if err != nil || isEmpty(value) {
+				continue
+			}
+			s := fmt.Sprint(value)
+			if seen[s] {
+				errs.add(prefixes[i]+"."+code+".value", msgDuplicated)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+// required はレコードに含まれていない必須項目を検証する（初期値がある場合は除く）
+func required(errs errorSet, prefix string, properties map[string]app.FieldProperty, rec map[string]types.FieldValue) {
+	for code, prop := range properties {
+		if _, ok := rec[code]; ok || !prop.Required {
+			continue
+		}
+		if def, err := normalize(prop.DefaultValue); err == nil && !isEmpty(def) {
+			continue
+		}
+		errs.add(prefix+"."+code+".value", msgRequired)
+	}
+}
+
+// field はフィールドの値を検証する
+func field(errs errorSet, key string, prop app.FieldProperty, value any) {
+	if isEmpty(value) {
+		if prop.Required {
+			errs.add(key, msgRequired)
+		}
+		return
+	}
+
+	switch prop.Type {
+	case types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText, types.FieldTypeLink:
+		s, ok := value.(string)
+		if !ok {
+			errs.add(key, msgInvalidValue)
+			return
+		}
+		length := utf8.RuneCountInString(s)
+		if n, ok := parseInt(prop.MaxLength); ok && length > n {
+			errs.add(key, fmt.Sprintf("%d文字以下で入力してください。", n))
+		}
+		if n, ok := parseInt(prop.MinLength); ok && length < n {
+			errs.add(key, fmt.Sprintf("%d文字以上で入力してください。", n))
+		}
+
+	case types.FieldTypeNumber:
+		n, ok := parseNumber(value)
+		if !ok {
+			errs.add(key, msgNotNumber)
+			return
+		}
+		if limit, ok := parseNumber(prop.MaxValue); ok && n.Cmp(limit) > 0 {
+			errs.add(key, fmt.Sprintf("%s以下である必要があります。", prop.MaxValue))
+		}
+		if limit, ok := parseNumber(prop.MinValue); ok && n.Cmp(limit) < 0 {
+			errs.add(key, fmt.Sprintf("%s以上である必要があります。", prop.MinValue))
+		}
+
+	case types.FieldTypeDropDown, types.FieldTypeRadioButton:
+		s, ok := value.(string)
+		if !ok {
+			errs.add(key, msgInvalidValue)
+			return
+		}
+		if _, ok := prop.Options[s]; !ok {
+			errs.add(key, fmt.Sprintf("「%s」は選択肢にありません。", s))
+		}
+
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect:
+		list, ok := value.([]any)
+		if !ok {
+			errs.add(key, msgInvalidValue)
+			return
+		}
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				errs.add(key, msgInvalidValue)
+				continue
+			}
+			if _, ok := prop.Options[s]; !ok {
+				errs.add(key, fmt.Sprintf("「%s」は選択肢にありません。", s))
+			}
+		}
+
+	case types.FieldTypeDate:
+		if !parseTime(value, "2006-01-02") {
+			errs.add(key, msgInvalidDate)
+		}
+	case types.FieldTypeTime:
+		if !parseTime(value, "15:04", "15:04:05") {
+			errs.add(key, msgInvalidTime)
+		}
+	case types.FieldTypeDateTime:
+		if !parseTime(value, dateTimeLayouts...) {
+			errs.add(key, msgInvalidDT)
+		}
+	}
+}
+
+// errorSet はキー（record.フィールドコード.value）ごとのエラーメッセージ
+type errorSet map[string][]string
+
+func (e errorSet) add(key, message string) {
+	if !slices.Contains(e[key], message) {
+		e[key] = append(e[key], message)
+	}
+}
+
+// err はエラーがある場合にCB_VA01の *KintoneRestAPIError を返す
+func (e errorSet) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	details := make(map[string]any, len(e))
+	for key, messages := range e {
+		list := make([]any, len(messages))
+		for i, m := range messages {
+			list[i] = m
+		}
+		details[key] = map[string]any{"messages": list}
+	}
+	return &kintoneError.KintoneRestAPIError{
+		Status:  http.StatusBadRequest,
+		Code:    kintoneError.CodeValidation,
+		Message: validationMessage,
+		Errors:  details,
+	}
+}
+
+// normalize は値をJSONに変換した形式（string / []any / map[string]any など）に揃える
+func normalize(value any) (any, error) {
+	switch value.(type) {
+	case nil, string:
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// isEmpty は値が未入力かどうかを返す
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func parseInt(s string) (int, bool) {
+	var n int
+	_, err := fmt.Sscan(s, &n)
+	return n, s != "" && err == nil
+}
+
+// parseNumber は数値（文字列またはJSONの数値）を解析する
+func parseNumber(value any) (*big.Rat, bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = fmt.Sprint(v)
+	default:
+		return nil, false
+	}
+	if s == "" || strings.Contains(s, "/") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// parseTime は値がいずれかの形式の日付・時刻かどうかを返す
+func parseTime(value any, layouts ...string) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package validate_test
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	kintoneError "github.com/goqoo-on-kintone/goten/error"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+	"github.com/goqoo-on-kintone/goten/validate"
+)
+
+var formFields = &app.GetFormFieldsResult{
+	Properties: map[string]app.FieldProperty{
+		"件名":   {Type: types.FieldTypeSingleLineText, Code: "件名", Required: true, MaxLength: "5"},
+		"申請番号": {Type: types.FieldTypeSingleLineText, Code: "申請番号", Unique: true},
+		"金額":   {Type: types.FieldTypeNumber, Code: "金額", MinValue: "0", MaxValue: "1000"},
+		"区分":   {Type: types.FieldTypeDropDown, Code: "区分", Required: true, DefaultValue: "通常", Options: map[string]app.Option{"通常": {}, "至急": {}}},
+		"タグ":   {Type: types.FieldTypeCheckBox, Code: "タグ", Options: map[string]app.Option{"A": {}, "B": {}}},
+		"期日":   {Type: types.FieldTypeDate, Code: "期日"},
+		"開始":   {Type: types.FieldTypeDateTime, Code: "開始"},
+		"明細": {Type: types.FieldTypeSubtable, Code: "明細", Fields: map[string]app.FieldProperty{
+			"品名": {Type: types.FieldTypeSingleLineText, Code: "品名", Required: true},
+			"数量": {Type: types.FieldTypeNumber, Code: "数量"},
+		}},
+	},
+}
+
+// messages はエラーのキーごとのメッセージを「キー: メッセージ」の形式で並べて返す
+func messages(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	if !kintoneError.HasCode(err, kintoneError.CodeValidation) {
+		t.Fatalf("CB_VA01のエラーではない: %v", err)
+	}
+	m := validate.Messages(err)
+	var list []string
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		list = append(list, key+": "+strings.Join(m[key], " "))
+	}
+	return list
+}
+
+func TestAddRecord(t *testing.T) {
+	v := validate.New(formFields)
+
+	err := v.AddRecord(map[string]types.FieldValue{
+		"件名": {Value: "長すぎる件名です"},
+		"金額": {Value: types.Decimal("1000.5")},
+		"区分": {Value: "普通"},
+		"タグ": {Value: []string{"A", "C"}},
+		"期日": {Value: "2024/01/01"},
+		"開始": {Value: "2024-01-01T09:00Z"},
+		"備考": {Value: "x"},
+		"明細": {Value: []map[string]any{
+			{"value": map[string]any{"数量": map[string]any{"value": "abc"}}},
+			{"id": "1", "value": map[string]any{"品名": map[string]any{"value": "りんご"}, "数量": map[string]any{"value": "3"}}},
+		}},
+	})
+	want := []string{
+		"record.タグ.value: 「C」は選択肢にありません。",
+		"record.件名.value: 5文字以下で入力してください。",
+		"record.備考.value: 指定したフィールドが見つかりません。",
+		"record.区分.value: 「普通」は選択肢にありません。",
+		"record.明細.value[0].value.品名.value: 必須です。",
+		"record.明細.value[0].value.数量.value: 数字でなければなりません。",
+		"record.期日.value: 日付の形式が不正です。",
+		"record.金額.value: 1000以下である必要があります。",
+	}
+	if got := messages(t, err); !slices.Equal(got, want) {
+		t.Errorf("期待されるエラー: %v, 実際: %v", want, got)
+	}
+
+	var apiErr *kintoneError.KintoneRestAPIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 400 || apiErr.Message == "" {
+		t.Errorf("サーバーの入力エラーと同じ形式ではない: %+v", apiErr)
+	}
+}
+
+func TestAddRecordRequired(t *testing.T) {
+	v := validate.New(formFields)
+
+	// 初期値のある必須項目は省略できる
+	want := []string{"record.件名.value: 必須です。"}
+	if got := messages(t, v.AddRecord(map[string]types.FieldValue{})); !slices.Equal(got, want) {
+		t.Errorf("期待されるエラー: %v, 実際: %v", want, got)
+	}
+	if err := v.AddRecord(map[string]types.FieldValue{"件名": {Value: "申請"}}); err != nil {
+		t.Errorf("エラーが発生: %v", err)
+	}
+}
+
+func TestUpdateRecord(t *testing.T) {
+	v := validate.New(formFields)
+
+	// 含まれていない必須項目は検証しない
+	if err := v.UpdateRecord(map[string]types.FieldValue{"金額": {Value: "10"}}); err != nil {
+		t.Errorf("エラーが発生: %v", err)
+	}
+	want := []string{"record.件名.value: 必須です。", "record.金額.value: 0以上である必要があります。"}
+	got := messages(t, v.UpdateRecord(map[string]types.FieldValue{"件名": {Value: ""}, "金額": {Value: "-1"}}))
+	if !slices.Equal(got, want) {
+		t.Errorf("期待されるエラー: %v, 実際: %v", want, got)
+	}
+}
+
+func TestRecordsUnique(t *testing.T) {
+	v := validate.New(formFields)
+
+	err := v.AddRecords([]map[string]types.FieldValue{
+		{"件名": {Value: "a"}, "申請番号": {Value: "001"}},
+		{"件名": {Value: "b"}, "申請番号": {Value: "002"}},
+		{"件名": {Value: "c"}, "申請番号": {Value: "001"}},
+		{"申請番号": {Value: ""}},
+	})
+	want := []string{
+		"records[2].申請番号.value: 値がほかのレコードと重複しています。",
+		"records[3].件名.value: 必須です。",
+	}
+	if got := messages(t, err); !slices.Equal(got, want) {
+		t.Errorf("期待されるエラー: %v, 実際: %v", want, got)
+	}
+
+	err = v.UpdateRecords([]record.UpdateRecordItem{
+		{ID: "1", Record: map[string]types.FieldValue{"申請番号": {Value: "001"}}},
+		{ID: "2", Record: map[string]types.FieldValue{"申請番号": {Value: "001"}, "開始": {Value: "明日"}}},
+	})
+	want = []string{
+		"records[1].record.申請番号.value: 値がほかのレコードと重複しています。",
+		"records[1].record.開始.value: 日時の形式が不正です。",
+	}
+	if got := messages(t, err); !slices.Equal(got, want) {
+		t.Errorf("期待されるエラー: %v, 実際: %v", want, got)
+	}
+}
+
+func TestMessagesServerError(t *testing.T) {
+	// サーバーの入力エラーも同じ方法で取り出せる
+	err := &kintoneError.KintoneRestAPIError{
+		Status: 400,
+		Code:   kintoneError.CodeValidation,
+		Errors: map[string]any{"record.件名.value": map[string]any{"messages": []any{"必須です。"}}},
+	}
+	m := validate.Messages(err)
+	if len(m["record.件名.value"]) != 1 || m["record.件名.value"][0] != "必須です。" {
+		t.Errorf("期待されるメッセージ: 必須です。, 実際: %v", m)
+	}
+	if validate.Messages(&kintoneError.KintoneRestAPIError{Code: kintoneError.CodeNoPermission}) != nil {
+		t.Error("CB_VA01以外のエラーはnilのはず")
+	}
+}
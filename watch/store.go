@@ -0,0 +1,131 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// Checkpoint はWatcherがどこまでレコードの変更を通知したかを表す
+type Checkpoint struct {
+	// UpdatedAt とID は通知したレコードのうち、更新日時・$idの順で最後のレコード
+	UpdatedAt time.Time      `json:"updatedAt"`
+	ID        types.RecordID `json:"id"`
+
+	// Seen は更新日時がUpdatedAtからOverlap以内のレコードの通知済みのリビジョン（重複して通知しないために使う）
+	Seen map[types.RecordID]SeenRecord `json:"seen"`
+
+	// Known は通知済みの存在するレコードの$id（削除の検出に使う）
+	// アプリの全レコードの$idを保持するため、レコード数に比例して大きくなり、Saveのたびに全体を書き込む
+	// （10万件のアプリでは1MB程度）
+	Known []types.RecordID `json:"known"`
+
+	ReconciledAt time.Time `json:"reconciledAt"` // 最後に削除を検出した日時（ローカルの時刻）
+}
+
+// SeenRecord は通知済みのレコードのリビジョンと更新日時
+type SeenRecord struct {
+	Revision  types.Revision `json:"revision"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// Store はCheckpointの保存先
+type Store interface {
+	// Load は保存したCheckpointを返す。保存していない場合はnilを返す
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save はCheckpointを保存する
+	Save(ctx context.Context, cp *Checkpoint) error
+}
+
+// MemoryStore はメモリにCheckpointを保持するStore（プロセスを終了すると失われる）
+type MemoryStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemoryStore は新しいMemoryStoreを作成する
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load はStoreインターフェースを実装
+func (s *MemoryStore) Load(ctx context.Context) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, nil
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(s.data, &cp); err != nil {
+		return nil, fmt.Errorf("チェックポイントの解析エラー: %w", err)
+	}
+	return &cp, nil
+}
+
+// Save はStoreインターフェースを実装
+func (s *MemoryStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("チェックポイントのJSONエンコードエラー: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
+
+// FileStore はJSONファイルにCheckpointを保存するStore
+type FileStore struct {
+	Path string
+}
+
+// Load はStoreインターフェースを実装
+func (s FileStore) Load(ctx context.Context) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("チェックポイントの読み込みエラー: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("チェックポイントの解析エラー: %w", err)
+	}
+	return &cp, nil
+}
+
+// Save はStoreインターフェースを実装
+// 一時ファイルに書き込んでから名前を変更するため、書き込み途中で終了しても以前のCheckpointが残る
+func (s FileStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("チェックポイントのJSONエンコードエラー: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("チェックポイントの書き込みエラー: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("チェックポイントの書き込みエラー: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("チェックポイントの書き込みエラー: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("チェックポイントの書き込みエラー: %w", err)
+	}
+	return nil
+}
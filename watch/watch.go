@@ -0,0 +1,431 @@
+// Package watch はアプリをポーリングしてレコードの追加・更新・削除を検出し、通知する機能を提供する
+package watch
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// Watcherの既定値
+const (
+	defaultUpdatedTimeField  = "更新日時"
+	defaultInterval          = 30 * time.Second
+	defaultOverlap           = 2 * time.Minute // kintoneの日時は分単位のため、1分より長くする
+	defaultReconcileInterval = 10 * time.Minute
+)
+
+// EventType は変更の種類
+type EventType string
+
+const (
+	EventCreate EventType = "create"
+	EventUpdate EventType = "update"
+	EventDelete EventType = "delete"
+)
+
+// Event はレコードの変更
+// EventDeleteの場合はIDのみを設定する
+type Event[T any] struct {
+	Type      EventType
+	ID        types.RecordID
+	Revision  types.Revision
+	UpdatedAt time.Time
+	Record    T
+}
+
+// Handler はイベントを処理する関数
+// エラーを返した場合、そのポーリングの結果はCheckpointに保存せず、次のポーリングで再度通知する
+type Handler[T any] func(ctx context.Context, event Event[T]) error
+
+// Options はWatcherの設定
+type Options struct {
+	App       types.AppID
+	Condition string   // 監視するレコードの条件（省略時は全レコード）
+	Fields    []string // 取得するフィールド（$id、$revision、UpdatedTimeFieldは自動的に追加する）
+
+	UpdatedTimeField string        // 更新日時フィールドのコード（省略時は「更新日時」）
+	Interval         time.Duration // ポーリングの間隔（0の場合は30秒）
+
+	// Overlap はCheckpointより前に遡って取得する時間（0の場合は2分）
+	// kintoneのサーバーとの時刻のずれや、更新日時が確定するまでの遅れを吸収する。
+	// 遡って取得したレコードのうち、通知済みのリビジョンのレコードは通知しない
+	Overlap time.Duration
+
+	// ReconcileInterval は$idの一覧を取得して削除を検出する間隔（0の場合は10分、負の場合は検出しない）
+	ReconcileInterval time.Duration
+
+	// InitialSync がtrueの場合、Checkpointがない初回に既存の全レコードをEventCreateとして通知する
+	// falseの場合は、初回は既存のレコードを通知せず、それ以降の変更のみを通知する
+	InitialSync bool
+
+	Store Store // Checkpointの保存先（省略時はMemoryStore）
+}
+
+// Watcher はアプリをポーリングしてレコードの追加・更新・削除を通知する
+//
+// 更新日時と$idの順で最後に通知したレコードをCheckpointとしてStoreに保存し、次のポーリングではそれ以降に更新されたレコードを取得する。
+// 判定にはkintoneの更新日時のみを使い、ローカルの時刻は使わない。
+// 通知はat-least-onceで、Handlerの処理中に終了した場合は次回に同じイベントを再度通知することがある。
+// 同時に複数のgoroutineから使用することはできない
+type Watcher[T any] struct {
+	client *record.Client
+	opts   Options
+
+	cp    *Checkpoint
+	known map[types.RecordID]bool
+}
+
+// New は新しいWatcherを作成する
+func New[T any](c *record.Client, opts Options) *Watcher[T] {
+	if opts.UpdatedTimeField == "" {
+		opts.UpdatedTimeField = defaultUpdatedTimeField
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	if opts.Overlap <= 0 {
+		opts.Overlap = defaultOverlap
+	}
+	if opts.ReconcileInterval == 0 {
+		opts.ReconcileInterval = defaultReconcileInterval
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+	return &Watcher[T]{client: c, opts: opts}
+}
+
+// Run はIntervalごとにポーリングし、contextがキャンセルされるかエラーが発生するまでイベントを通知する
+// キャンセルされた場合はcontextのエラーを返す
+func (w *Watcher[T]) Run(ctx context.Context, handler Handler[T]) error {
+	for {
+		if err := w.Poll(ctx, handler); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(w.opts.Interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Events はRunを別のgoroutineで実行し、イベントを送るチャネルを返す
+// Runが終了するとエラーのチャネルに終了の原因を1つ送り、両方のチャネルを閉じる。
+// イベントはチャネルで受け取った時点で処理済みとして扱う
+func (w *Watcher[T]) Events(ctx context.Context) (<-chan Event[T], <-chan error) {
+	events := make(chan Event[T])
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(events)
+		errs <- w.Run(ctx, func(ctx context.Context, event Event[T]) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events, errs
+}
+
+// Poll は1回ポーリングして変更を通知する
+// 追加・更新を更新日時と$idの順で通知した後、ReconcileIntervalが過ぎていれば削除を$idの順で通知する。
+// 全てのイベントを処理するとCheckpointを保存する
+func (w *Watcher[T]) Poll(ctx context.Context, handler Handler[T]) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	if err := w.poll(ctx, handler); err != nil {
+		w.cp = nil // 次回はStoreに保存したCheckpointから再開する
+		return err
+	}
+	if w.opts.ReconcileInterval > 0 && time.Since(w.cp.ReconciledAt) >= w.opts.ReconcileInterval {
+		if err := w.reconcile(ctx, handler); err != nil {
+			w.cp = nil
+			return err
+		}
+	}
+	return w.save(ctx)
+}
+
+// Checkpoint は現在のCheckpointを返す（まだポーリングしていない場合はnil）
+func (w *Watcher[T]) Checkpoint() *Checkpoint {
+	if w.cp == nil {
+		return nil
+	}
+	cp := *w.cp
+	cp.Seen = maps.Clone(w.cp.Seen)
+	cp.Known = w.knownIDs()
+	return &cp
+}
+
+// load はStoreからCheckpointを読み込む。Checkpointがない場合は初期化する
+func (w *Watcher[T]) load(ctx context.Context) error {
+	if w.cp != nil {
+		return nil
+	}
+	cp, err := w.opts.Store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		w.cp = cp
+		if w.cp.Seen == nil {
+			w.cp.Seen = map[types.RecordID]SeenRecord{}
+		}
+		w.known = make(map[types.RecordID]bool, len(cp.Known))
+		for _, id := range cp.Known {
+			w.known[id] = true
+		}
+		return nil
+	}
+
+	w.cp = &Checkpoint{Seen: map[types.RecordID]SeenRecord{}}
+	w.known = map[types.RecordID]bool{}
+	if w.opts.InitialSync {
+		return nil
+	}
+
+	// 既存のレコードを通知済みとして記録する
+	if err := w.baseline(ctx); err != nil {
+		w.cp = nil
+		return err
+	}
+	return w.save(ctx)
+}
+
+// baseline は既存のレコードを通知せずに、Checkpointを最新のレコードに合わせる
+func (w *Watcher[T]) baseline(ctx context.Context) error {
+	query := w.opts.Condition
+	if query != "" {
+		query += " "
+	}
+	query += fmt.Sprintf("order by %s desc, $id desc limit 1", w.opts.UpdatedTimeField)
+	result, err := record.GetRecords[record.Dynamic](ctx, w.client, record.GetRecordsParams{
+		App:    w.opts.App,
+		Fields: []string{"$id", w.opts.UpdatedTimeField},
+		Query:  query,
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Records) == 0 {
+		w.cp.ReconciledAt = time.Now()
+		return nil
+	}
+	latest, err := result.Records[0].Time(w.opts.UpdatedTimeField)
+	if err != nil {
+		return err
+	}
+	w.cp.UpdatedAt = latest
+
+	// Overlapの範囲のリビジョンを記録し、既存の$idを全て記録する
+	skip := func(context.Context, Event[T]) error { return nil }
+	if err := w.poll(ctx, skip); err != nil {
+		return err
+	}
+	ids, err := w.recordIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		w.known[id] = true
+	}
+	w.cp.ReconciledAt = time.Now()
+	return nil
+}
+
+// poll はCheckpointのOverlap前以降に更新されたレコードを取得し、通知していないリビジョンを通知する
+func (w *Watcher[T]) poll(ctx context.Context, handler Handler[T]) error {
+	condition := w.opts.Condition
+	if !w.cp.UpdatedAt.IsZero() {
+		since := w.cp.UpdatedAt.Add(-w.opts.Overlap).UTC().Format(time.RFC3339)
+		condition = fmt.Sprintf("%s >= %q", w.opts.UpdatedTimeField, since)
+		if w.opts.Condition != "" {
+			condition = "(" + w.opts.Condition + ") and " + condition
+		}
+	}
+
+	var events []Event[T]
+	for d, err := range record.AllRecords[record.Dynamic](ctx, w.client, record.GetAllRecordsParams{
+		App:       w.opts.App,
+		Fields:    w.fields(),
+		Condition: condition,
+	}) {
+		if err != nil {
+			return err
+		}
+		event, ok, err := w.event(d)
+		if err != nil {
+			return err
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	// 更新日時と$idの順で通知する
+	slices.SortFunc(events, func(a, b Event[T]) int {
+		if c := a.UpdatedAt.Compare(b.UpdatedAt); c != 0 {
+			return c
+		}
+		return compareID(a.ID, b.ID)
+	})
+	for _, event := range events {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+		w.known[event.ID] = true
+		w.cp.Seen[event.ID] = SeenRecord{Revision: event.Revision, UpdatedAt: event.UpdatedAt}
+		if event.UpdatedAt.After(w.cp.UpdatedAt) ||
+			event.UpdatedAt.Equal(w.cp.UpdatedAt) && compareID(event.ID, w.cp.ID) > 0 {
+			w.cp.UpdatedAt = event.UpdatedAt
+			w.cp.ID = event.ID
+		}
+	}
+
+	// Overlapの範囲外になったリビジョンは再度取得しないため破棄する
+	window := w.cp.UpdatedAt.Add(-w.opts.Overlap)
+	for id, seen := range w.cp.Seen {
+		if seen.UpdatedAt.Before(window) {
+			delete(w.cp.Seen, id)
+		}
+	}
+	return nil
+}
+
+// event はレコードをイベントに変換する。通知済みのリビジョンの場合はfalseを返す
+func (w *Watcher[T]) event(d record.Dynamic) (Event[T], bool, error) {
+	id := d.ID()
+	revision := d.Revision()
+	if id == "" || revision == "" {
+		return Event[T]{}, false, fmt.Errorf("レコードに$idまたは$revisionが含まれていません")
+	}
+	if seen, ok := w.cp.Seen[id]; ok && seen.Revision == revision {
+		return Event[T]{}, false, nil
+	}
+
+	updatedAt, err := d.Time(w.opts.UpdatedTimeField)
+	if err != nil {
+		return Event[T]{}, false, err
+	}
+	rec, err := record.FromDynamic[T](d)
+	if err != nil {
+		return Event[T]{}, false, err
+	}
+	eventType := EventCreate
+	if w.known[id] {
+		eventType = EventUpdate
+	}
+	return Event[T]{Type: eventType, ID: id, Revision: revision, UpdatedAt: updatedAt, Record: rec}, true, nil
+}
+
+// reconcile は現在の$idの一覧と通知済みの$idを比較し、存在しなくなったレコードの削除を通知する
+// 取得中に追加されたレコードを削除と誤認しないよう、開始時点で通知済みの$idの最大値以下のみを対象にする
+func (w *Watcher[T]) reconcile(ctx context.Context, handler Handler[T]) error {
+	var limit types.RecordID
+	for id := range w.known {
+		if compareID(id, limit) > 0 {
+			limit = id
+		}
+	}
+	startedAt := time.Now()
+
+	ids, err := w.recordIDs(ctx)
+	if err != nil {
+		return err
+	}
+	exists := make(map[types.RecordID]bool, len(ids))
+	for _, id := range ids {
+		exists[id] = true
+	}
+
+	var deleted []types.RecordID
+	for id := range w.known {
+		if !exists[id] && compareID(id, limit) <= 0 {
+			deleted = append(deleted, id)
+		}
+	}
+	slices.SortFunc(deleted, compareID)
+	for _, id := range deleted {
+		if err := handler(ctx, Event[T]{Type: EventDelete, ID: id}); err != nil {
+			return err
+		}
+		delete(w.known, id)
+		delete(w.cp.Seen, id)
+	}
+	w.cp.ReconciledAt = startedAt
+	return nil
+}
+
+// recordIDs は条件に一致する全レコードの$idを返す
+func (w *Watcher[T]) recordIDs(ctx context.Context) ([]types.RecordID, error) {
+	var ids []types.RecordID
+	for d, err := range record.AllRecords[record.Dynamic](ctx, w.client, record.GetAllRecordsParams{
+		App:       w.opts.App,
+		Fields:    []string{"$id"},
+		Condition: w.opts.Condition,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, d.ID())
+	}
+	return ids, nil
+}
+
+// fields は取得するフィールドを返す（全フィールドの場合はnil）
+func (w *Watcher[T]) fields() []string {
+	if len(w.opts.Fields) == 0 {
+		return nil
+	}
+	fields := slices.Clone(w.opts.Fields)
+	for _, code := range []string{"$id", "$revision", w.opts.UpdatedTimeField} {
+		if !slices.Contains(fields, code) {
+			fields = append(fields, code)
+		}
+	}
+	return fields
+}
+
+// save はCheckpointをStoreに保存する
+func (w *Watcher[T]) save(ctx context.Context) error {
+	w.cp.Known = w.knownIDs()
+	return w.opts.Store.Save(ctx, w.cp)
+}
+
+// knownIDs は通知済みの$idを昇順で返す
+func (w *Watcher[T]) knownIDs() []types.RecordID {
+	ids := make([]types.RecordID, 0, len(w.known))
+	for id := range w.known {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, compareID)
+	return ids
+}
+
+// compareID は$idを数値として比較する
+func compareID(a, b types.RecordID) int {
+	x, _ := strconv.ParseInt(a, 10, 64)
+	y, _ := strconv.ParseInt(b, 10, 64)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,293 @@
+package watch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+	"github.com/goqoo-on-kintone/goten/watch"
+)
+
+var (
+	sincePattern  = regexp.MustCompile(`更新日時 >= "([^"]+)"`)
+	lastIDPattern = regexp.MustCompile(`\$id > (\d+) order by \$id asc`)
+)
+
+// watchRecord はテストサーバーが保持するレコード
+type watchRecord struct {
+	revision  int
+	updatedAt time.Time
+	name      string
+}
+
+// watchServer はレコードの取得に応答するテストサーバー
+type watchServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	records map[int]*watchRecord
+	queries []string
+}
+
+func newWatchServer(t *testing.T) *watchServer {
+	t.Helper()
+	s := &watchServer{records: map[int]*watchRecord{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		query, _ := reqBody["query"].(string)
+		s.queries = append(s.queries, query)
+
+		var ids []int
+		for id := range s.records {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+
+		var matched []int
+		if strings.HasSuffix(query, "order by 更新日時 desc, $id desc limit 1") {
+			for _, id := range ids {
+				if len(matched) == 0 || !s.records[id].updatedAt.Before(s.records[matched[0]].updatedAt) {
+					matched = []int{id}
+				}
+			}
+		} else {
+			var since time.Time
+			if m := sincePattern.FindStringSubmatch(query); m != nil {
+				since, _ = time.Parse(time.RFC3339, m[1])
+			}
+			lastID := 0
+			if m := lastIDPattern.FindStringSubmatch(query); m != nil {
+				lastID, _ = strconv.Atoi(m[1])
+			}
+			for _, id := range ids {
+				if id > lastID && !s.records[id].updatedAt.Before(since) && len(matched) < 500 {
+					matched = append(matched, id)
+				}
+			}
+		}
+
+		records := []map[string]any{}
+		for _, id := range matched {
+			rec := s.records[id]
+			records = append(records, map[string]any{
+				"$id":       map[string]string{"type": "__ID__", "value": strconv.Itoa(id)},
+				"$revision": map[string]string{"type": "__REVISION__", "value": strconv.Itoa(rec.revision)},
+				"更新日時":      map[string]string{"type": "UPDATED_TIME", "value": rec.updatedAt.UTC().Format(time.RFC3339)},
+				"名前":        map[string]string{"type": "SINGLE_LINE_TEXT", "value": rec.name},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	return s
+}
+
+// put はレコードを追加・更新する
+func (s *watchServer) put(id int, name string, updatedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[id]
+	if !ok {
+		rec = &watchRecord{}
+		s.records[id] = rec
+	}
+	rec.revision++
+	rec.name = name
+	rec.updatedAt = updatedAt
+}
+
+func (s *watchServer) remove(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+}
+
+func (s *watchServer) lastQuery() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries[len(s.queries)-1]
+}
+
+type nameRecord struct {
+	Name struct {
+		Value string `json:"value"`
+	} `json:"名前"`
+}
+
+func newWatcher(serverURL string, opts watch.Options) *watch.Watcher[nameRecord] {
+	client := record.NewClient(gotenhttp.NewDefaultClient(serverURL, auth.APITokenAuth{Token: "test-token"}))
+	opts.App = "1"
+	return watch.New[nameRecord](client, opts)
+}
+
+// poll は1回ポーリングしたイベントを「種類:$id:名前」の形式で返す
+func poll(t *testing.T, w *watch.Watcher[nameRecord]) []string {
+	t.Helper()
+	var events []string
+	err := w.Poll(context.Background(), func(ctx context.Context, e watch.Event[nameRecord]) error {
+		events = append(events, fmt.Sprintf("%s:%s:%s", e.Type, e.ID, e.Record.Name.Value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	return events
+}
+
+func TestWatcherInitialSync(t *testing.T) {
+	server := newWatchServer(t)
+	defer server.Close()
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	server.put(2, "B", base.Add(time.Minute))
+	server.put(1, "A", base)
+
+	w := newWatcher(server.URL, watch.Options{InitialSync: true, ReconcileInterval: time.Nanosecond})
+	if got := strings.Join(poll(t, w), ","); got != "create:1:A,create:2:B" {
+		t.Errorf("期待されるイベント: create:1:A,create:2:B, 実際: %s", got)
+	}
+
+	server.put(1, "A2", base.Add(2*time.Minute))
+	server.put(3, "C", base.Add(2*time.Minute))
+	if got := strings.Join(poll(t, w), ","); got != "update:1:A2,create:3:C" {
+		t.Errorf("期待されるイベント: update:1:A2,create:3:C, 実際: %s", got)
+	}
+	if cp := w.Checkpoint(); !cp.UpdatedAt.Equal(base.Add(2*time.Minute)) || cp.ID != "3" {
+		t.Errorf("期待されるチェックポイント: %v $id=3, 実際: %v $id=%s", base.Add(2*time.Minute), cp.UpdatedAt, cp.ID)
+	}
+
+	// Overlapで再取得したレコードは通知しない
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("イベントがないはず: %v", events)
+	}
+
+	server.remove(2)
+	if got := strings.Join(poll(t, w), ","); got != "delete:2:" {
+		t.Errorf("期待されるイベント: delete:2:, 実際: %s", got)
+	}
+	if known := w.Checkpoint().Known; !slices.Equal(known, []types.RecordID{"1", "3"}) {
+		t.Errorf("期待される$id: [1 3], 実際: %v", known)
+	}
+}
+
+func TestWatcherBaseline(t *testing.T) {
+	server := newWatchServer(t)
+	defer server.Close()
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	server.put(1, "A", base)
+	server.put(2, "B", base.Add(10*time.Minute))
+
+	w := newWatcher(server.URL, watch.Options{Condition: `名前 != ""`, Overlap: 5 * time.Minute})
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("既存のレコードは通知しないはず: %v", events)
+	}
+
+	server.put(1, "A2", base.Add(11*time.Minute))
+	server.put(3, "C", base.Add(11*time.Minute))
+	if got := strings.Join(poll(t, w), ","); got != "update:1:A2,create:3:C" {
+		t.Errorf("期待されるイベント: update:1:A2,create:3:C, 実際: %s", got)
+	}
+	// 前回のチェックポイント（09:10）からOverlapだけ遡って取得する
+	if want := `((名前 != "") and 更新日時 >= "2024-01-01T09:05:00Z")`; !strings.HasPrefix(server.lastQuery(), want) {
+		t.Errorf("期待されるクエリ: %s..., 実際: %s", want, server.lastQuery())
+	}
+}
+
+func TestWatcherHandlerError(t *testing.T) {
+	server := newWatchServer(t)
+	defer server.Close()
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	server.put(1, "A", base)
+	server.put(2, "B", base)
+
+	store := watch.FileStore{Path: filepath.Join(t.TempDir(), "checkpoint.json")}
+	w := newWatcher(server.URL, watch.Options{InitialSync: true, Store: store})
+	handlerErr := errors.New("処理エラー")
+	err := w.Poll(context.Background(), func(ctx context.Context, e watch.Event[nameRecord]) error {
+		if e.ID == "2" {
+			return handlerErr
+		}
+		return nil
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("期待されるエラー: %v, 実際: %v", handlerErr, err)
+	}
+
+	// 保存していないイベントは再度通知する
+	w = newWatcher(server.URL, watch.Options{InitialSync: true, Store: store})
+	if got := strings.Join(poll(t, w), ","); got != "create:1:A,create:2:B" {
+		t.Errorf("期待されるイベント: create:1:A,create:2:B, 実際: %s", got)
+	}
+
+	// 保存したチェックポイントから再開する
+	w = newWatcher(server.URL, watch.Options{InitialSync: true, Store: store})
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("イベントがないはず: %v", events)
+	}
+}
+
+func TestWatcherEvents(t *testing.T) {
+	server := newWatchServer(t)
+	defer server.Close()
+	server.put(1, "A", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := newWatcher(server.URL, watch.Options{InitialSync: true, Interval: time.Millisecond})
+	events, errs := w.Events(ctx)
+
+	e := <-events
+	if e.Type != watch.EventCreate || e.ID != "1" || e.Revision != "1" {
+		t.Errorf("期待されるイベント: create $id=1 $revision=1, 実際: %+v", e)
+	}
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("期待されるエラー: context.Canceled, 実際: %v", err)
+	}
+	if _, ok := <-events; ok {
+		t.Error("イベントのチャネルが閉じられていない")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := watch.FileStore{Path: filepath.Join(t.TempDir(), "checkpoint.json")}
+	cp, err := store.Load(context.Background())
+	if err != nil || cp != nil {
+		t.Fatalf("保存前はnilのはず: %+v, %v", cp, err)
+	}
+
+	updatedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	err = store.Save(context.Background(), &watch.Checkpoint{
+		UpdatedAt: updatedAt,
+		ID:        "3",
+		Seen:      map[types.RecordID]watch.SeenRecord{"3": {Revision: "2", UpdatedAt: updatedAt}},
+		Known:     []types.RecordID{"1", "3"},
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	cp, err = store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if !cp.UpdatedAt.Equal(updatedAt) || cp.ID != "3" || cp.Seen["3"].Revision != "2" || !slices.Equal(cp.Known, []types.RecordID{"1", "3"}) {
+		t.Errorf("保存したチェックポイントと異なる: %+v", cp)
+	}
+}
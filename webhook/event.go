@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// EventType はWebhookの通知の種類
+type EventType string
+
+const (
+	EventAddRecord        EventType = "ADD_RECORD"
+	EventUpdateRecord     EventType = "UPDATE_RECORD"
+	EventDeleteRecord     EventType = "DELETE_RECORD"
+	EventAddRecordComment EventType = "ADD_RECORD_COMMENT"
+	EventUpdateStatus     EventType = "UPDATE_STATUS"
+)
+
+// App は通知したアプリ
+type App struct {
+	ID   types.AppID `json:"id"`
+	Name string      `json:"name"`
+}
+
+// Header は全ての通知に共通する項目
+type Header struct {
+	ID   string    `json:"id"` // Webhookの通知ごとに一意なID
+	Type EventType `json:"type"`
+	App  App       `json:"app"`
+	URL  string    `json:"url"` // レコード（削除の場合はアプリ）のURL
+}
+
+// RecordEvent はレコードの追加・編集・ステータスの更新の通知
+type RecordEvent[T any] struct {
+	Header
+	Record      T      `json:"record"`
+	RecordTitle string `json:"recordTitle"`
+}
+
+// DeleteRecordEvent はレコードの削除の通知
+type DeleteRecordEvent struct {
+	Header
+	RecordID types.RecordID `json:"recordId"`
+	DeleteBy types.Entity   `json:"deleteBy"`
+	DeleteAt time.Time      `json:"deleteAt"`
+}
+
+// CommentEvent はコメントの書き込みの通知
+type CommentEvent struct {
+	Header
+	RecordID    types.RecordID `json:"recordId"`
+	RecordTitle string         `json:"recordTitle"`
+	Comment     record.Comment `json:"comment"`
+}
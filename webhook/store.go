@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// defaultIDStoreSize はMemoryIDStoreが記録するIDの数の既定値
+const defaultIDStoreSize = 10000
+
+// IDStore は処理した通知のIDを記録し、同じ通知を重複して処理しないために使う
+type IDStore interface {
+	// Add はIDを記録する。既に記録している場合はfalseを返す
+	Add(ctx context.Context, id string) (bool, error)
+	// Remove はIDの記録を削除する（処理に失敗した通知を再度受け付けるために使う）
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryIDStore はメモリにIDを記録するIDStore
+// 記録したIDの数が上限を超えると、古いIDから破棄する
+type MemoryIDStore struct {
+	mu    sync.Mutex
+	size  int
+	ids   map[string]struct{}
+	order []string
+}
+
+// NewMemoryIDStore は最大size件のIDを記録するMemoryIDStoreを作成する（0以下の場合は10000件）
+func NewMemoryIDStore(size int) *MemoryIDStore {
+	if size <= 0 {
+		size = defaultIDStoreSize
+	}
+	return &MemoryIDStore{size: size, ids: map[string]struct{}{}}
+}
+
+// Add はIDStoreインターフェースを実装
+func (s *MemoryIDStore) Add(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ids[id]; ok {
+		return false, nil
+	}
+	s.ids[id] = struct{}{}
+	s.order = append(s.order, id)
+	for len(s.order) > s.size {
+		delete(s.ids, s.order[0])
+		s.order = s.order[1:]
+	}
+	return true, nil
+}
+
+// Remove はIDStoreインターフェースを実装
+func (s *MemoryIDStore) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ids[id]; !ok {
+		return nil
+	}
+	delete(s.ids, id)
+	if i := slices.Index(s.order, id); i >= 0 {
+		s.order = slices.Delete(s.order, i, i+1)
+	}
+	return nil
+}
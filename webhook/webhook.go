@@ -0,0 +1,265 @@
+// Package webhook はkintoneのWebhookの通知を受け取り、イベントの種類ごとに型付きのハンドラを呼び出すhttp.Handlerを提供する
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// Handlerの既定値
+const (
+	defaultSecretParam  = "token"
+	defaultMaxBodyBytes = 10 << 20
+)
+
+// Options はHandlerの設定
+type Options struct {
+	Apps []types.AppID // 受け付けるアプリ（省略時は全てのアプリ）。それ以外のアプリの通知は無視する
+
+	// Secret を指定した場合、URLのクエリパラメータSecretParam（省略時は「token」）に同じ値がある通知のみを受け付ける
+	// kintoneのWebhookのURLに ?token=... を付けて設定する
+	Secret      string
+	SecretParam string
+
+	// AllowedIPs を指定した場合、送信元のIPアドレスが含まれる通知のみを受け付ける（IPアドレスまたはCIDR）
+	AllowedIPs []string
+	// TrustForwardedFor がtrueの場合、X-Forwarded-Forの最後のアドレス（直前のプロキシが追加したもの）を送信元とする
+	TrustForwardedFor bool
+
+	// IDStore は処理した通知のIDの記録先（省略時は10000件まで記録するMemoryIDStore）
+	// 記録済みのIDの通知は処理せずに成功を返す
+	IDStore IDStore
+
+	MaxBodyBytes int64 // 受け付けるリクエストボディの最大サイズ（省略時は10MB）
+
+	// OnError は通知を処理できなかった場合に呼び出す（ログの出力用）
+	OnError func(r *http.Request, err error)
+}
+
+// Handler はkintoneのWebhookの通知を受け付けるhttp.Handler
+//
+// 通知を種類ごとのイベントに変換し、登録した関数を呼び出す。レコードは record.GetRecords[T] と同じ形式でTに変換する。
+// 関数を登録していない種類の通知や、対象外のアプリの通知は無視して成功を返す。
+// 関数がエラーを返した場合は500を返し、通知のIDの記録を削除する（同じ通知を再送した場合は再度処理する）。
+// 関数の登録は、リクエストを受け付ける前に行う
+type Handler[T any] struct {
+	opts    Options
+	allowed []netip.Prefix
+
+	onAddRecord        func(ctx context.Context, event *RecordEvent[T]) error
+	onUpdateRecord     func(ctx context.Context, event *RecordEvent[T]) error
+	onUpdateStatus     func(ctx context.Context, event *RecordEvent[T]) error
+	onDeleteRecord     func(ctx context.Context, event *DeleteRecordEvent) error
+	onAddRecordComment func(ctx context.Context, event *CommentEvent) error
+}
+
+// New は新しいHandlerを作成する
+// AllowedIPsに解析できないアドレスがある場合はエラーを返す
+func New[T any](opts Options) (*Handler[T], error) {
+	if opts.SecretParam == "" {
+		opts.SecretParam = defaultSecretParam
+	}
+	if opts.IDStore == nil {
+		opts.IDStore = NewMemoryIDStore(0)
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	h := &Handler[T]{opts: opts}
+	for _, s := range opts.AllowedIPs {
+		prefix, err := parsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("許可するIPアドレスが不正です: %q: %w", s, err)
+		}
+		h.allowed = append(h.allowed, prefix)
+	}
+	return h, nil
+}
+
+// OnAddRecord はレコードの追加（ADD_RECORD）の通知を処理する関数を登録する
+func (h *Handler[T]) OnAddRecord(fn func(ctx context.Context, event *RecordEvent[T]) error) {
+	h.onAddRecord = fn
+}
+
+// OnUpdateRecord はレコードの編集（UPDATE_RECORD）の通知を処理する関数を登録する
+func (h *Handler[T]) OnUpdateRecord(fn func(ctx context.Context, event *RecordEvent[T]) error) {
+	h.onUpdateRecord = fn
+}
+
+// OnUpdateStatus はステータスの更新（UPDATE_STATUS）の通知を処理する関数を登録する
+func (h *Handler[T]) OnUpdateStatus(fn func(ctx context.Context, event *RecordEvent[T]) error) {
+	h.onUpdateStatus = fn
+}
+
+// OnDeleteRecord はレコードの削除（DELETE_RECORD）の通知を処理する関数を登録する
+func (h *Handler[T]) OnDeleteRecord(fn func(ctx context.Context, event *DeleteRecordEvent) error) {
+	h.onDeleteRecord = fn
+}
+
+// OnAddRecordComment はコメントの書き込み（ADD_RECORD_COMMENT）の通知を処理する関数を登録する
+func (h *Handler[T]) OnAddRecordComment(fn func(ctx context.Context, event *CommentEvent) error) {
+	h.onAddRecordComment = fn
+}
+
+// ServeHTTP はhttp.Handlerインターフェースを実装
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.allowedAddr(r) {
+		h.fail(w, r, http.StatusForbidden, fmt.Errorf("許可されていない送信元です: %s", r.RemoteAddr))
+		return
+	}
+	if h.opts.Secret != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get(h.opts.SecretParam)), []byte(h.opts.Secret)) != 1 {
+		h.fail(w, r, http.StatusUnauthorized, fmt.Errorf("シークレットが一致しません"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes))
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("リクエストの読み込みエラー: %w", err))
+		return
+	}
+	var header Header
+	if err := json.Unmarshal(body, &header); err != nil {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("リクエスト解析エラー: %w", err))
+		return
+	}
+	if header.ID == "" || header.Type == "" {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("リクエスト解析エラー: idまたはtypeがありません"))
+		return
+	}
+	if len(h.opts.Apps) > 0 && !slices.Contains(h.opts.Apps, header.App.ID) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	dispatch, err := h.dispatcher(header.Type, body)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if dispatch == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ctx := r.Context()
+	first, err := h.opts.IDStore.Add(ctx, header.ID)
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if !first {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := dispatch(ctx); err != nil {
+		if removeErr := h.opts.IDStore.Remove(context.WithoutCancel(ctx), header.ID); removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatcher は通知を種類に応じたイベントに変換し、登録した関数を呼び出す関数を返す
+// 関数を登録していない種類の場合はnilを返す
+func (h *Handler[T]) dispatcher(eventType EventType, body []byte) (func(ctx context.Context) error, error) {
+	switch eventType {
+	case EventAddRecord:
+		return eventDispatcher(h.onAddRecord, body)
+	case EventUpdateRecord:
+		return eventDispatcher(h.onUpdateRecord, body)
+	case EventUpdateStatus:
+		return eventDispatcher(h.onUpdateStatus, body)
+	case EventDeleteRecord:
+		return eventDispatcher(h.onDeleteRecord, body)
+	case EventAddRecordComment:
+		return eventDispatcher(h.onAddRecordComment, body)
+	}
+	return nil, nil
+}
+
+// eventDispatcher は通知をEに変換してfnを呼び出す関数を返す（fnがnilの場合はnil）
+func eventDispatcher[E any](fn func(context.Context, *E) error, body []byte) (func(context.Context) error, error) {
+	if fn == nil {
+		return nil, nil
+	}
+	var event E
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("リクエスト解析エラー: %w", err)
+	}
+	return func(ctx context.Context) error { return fn(ctx, &event) }, nil
+}
+
+// allowedAddr は送信元のIPアドレスが許可されているかを返す
+func (h *Handler[T]) allowedAddr(r *http.Request) bool {
+	if len(h.allowed) == 0 {
+		return true
+	}
+	addr, ok := clientAddr(r, h.opts.TrustForwardedFor)
+	if !ok {
+		return false
+	}
+	for _, prefix := range h.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fail はエラーのレスポンスを返し、OnErrorを呼び出す
+func (h *Handler[T]) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.opts.OnError != nil {
+		h.opts.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// clientAddr は送信元のIPアドレスを返す
+func clientAddr(r *http.Request, trustForwardedFor bool) (netip.Addr, bool) {
+	if trustForwardedFor {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			parts := strings.Split(values[len(values)-1], ",")
+			addr, err := netip.ParseAddr(strings.TrimSpace(parts[len(parts)-1]))
+			return addr.Unmap(), err == nil
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	return addr.Unmap(), err == nil
+}
+
+// parsePrefix はIPアドレスまたはCIDRを解析する
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,221 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/webhook"
+)
+
+type orderRecord struct {
+	Title struct {
+		Value string `json:"value"`
+	} `json:"件名"`
+}
+
+const addRecordPayload = `{
+	"id": "01234567-0123-0123-0123-0123456789ab",
+	"type": "ADD_RECORD",
+	"app": {"id": "1", "name": "受注"},
+	"record": {
+		"$id": {"type": "__ID__", "value": "10"},
+		"件名": {"type": "SINGLE_LINE_TEXT", "value": "テスト"}
+	},
+	"recordTitle": "テスト",
+	"url": "https://example.cybozu.com/k/1/show#record=10"
+}`
+
+const deleteRecordPayload = `{
+	"id": "11111111-0123-0123-0123-0123456789ab",
+	"type": "DELETE_RECORD",
+	"app": {"id": "1", "name": "受注"},
+	"recordId": "10",
+	"deleteBy": {"code": "sato", "name": "佐藤"},
+	"deleteAt": "2024-01-01T09:00:00.000Z",
+	"url": "https://example.cybozu.com/k/1/"
+}`
+
+const commentPayload = `{
+	"id": "22222222-0123-0123-0123-0123456789ab",
+	"type": "ADD_RECORD_COMMENT",
+	"app": {"id": "1", "name": "受注"},
+	"recordId": "10",
+	"recordTitle": "テスト",
+	"comment": {
+		"id": "3",
+		"text": "確認しました",
+		"createdAt": "2024-01-01T09:00:00Z",
+		"creator": {"code": "sato", "name": "佐藤"},
+		"mentions": [{"code": "suzuki", "type": "USER"}]
+	},
+	"url": "https://example.cybozu.com/k/1/show#record=10&comment=3"
+}`
+
+// post はHandlerに通知を送り、レスポンスのステータスコードを返す
+func post(h http.Handler, target, body string, modify ...func(*http.Request)) int {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for _, m := range modify {
+		m(req)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func newHandler(t *testing.T, opts webhook.Options) *webhook.Handler[orderRecord] {
+	t.Helper()
+	h, err := webhook.New[orderRecord](opts)
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	return h
+}
+
+func TestHandlerEvents(t *testing.T) {
+	h := newHandler(t, webhook.Options{})
+
+	var added *webhook.RecordEvent[orderRecord]
+	var deleted *webhook.DeleteRecordEvent
+	var comment *webhook.CommentEvent
+	h.OnAddRecord(func(ctx context.Context, e *webhook.RecordEvent[orderRecord]) error {
+		added = e
+		return nil
+	})
+	h.OnDeleteRecord(func(ctx context.Context, e *webhook.DeleteRecordEvent) error {
+		deleted = e
+		return nil
+	})
+	h.OnAddRecordComment(func(ctx context.Context, e *webhook.CommentEvent) error {
+		comment = e
+		return nil
+	})
+
+	for _, payload := range []string{addRecordPayload, deleteRecordPayload, commentPayload} {
+		if code := post(h, "/", payload); code != http.StatusOK {
+			t.Fatalf("期待されるステータス: 200, 実際: %d", code)
+		}
+	}
+
+	if added == nil || added.Type != webhook.EventAddRecord || added.App.ID != "1" || added.Record.Title.Value != "テスト" || added.RecordTitle != "テスト" {
+		t.Errorf("レコードの追加の通知が正しくない: %+v", added)
+	}
+	if deleted == nil || deleted.RecordID != "10" || deleted.DeleteBy.Code != "sato" || deleted.DeleteAt.Hour() != 9 {
+		t.Errorf("レコードの削除の通知が正しくない: %+v", deleted)
+	}
+	if comment == nil || comment.RecordID != "10" || comment.Comment.Text != "確認しました" || len(comment.Comment.Mentions) != 1 {
+		t.Errorf("コメントの通知が正しくない: %+v", comment)
+	}
+}
+
+func TestHandlerIdempotency(t *testing.T) {
+	h := newHandler(t, webhook.Options{})
+	calls := 0
+	fail := true
+	h.OnAddRecord(func(ctx context.Context, e *webhook.RecordEvent[orderRecord]) error {
+		calls++
+		if fail {
+			return errors.New("処理エラー")
+		}
+		return nil
+	})
+
+	// 失敗した通知は再送すると再度処理する
+	if code := post(h, "/", addRecordPayload); code != http.StatusInternalServerError {
+		t.Errorf("期待されるステータス: 500, 実際: %d", code)
+	}
+	fail = false
+	if code := post(h, "/", addRecordPayload); code != http.StatusOK {
+		t.Errorf("期待されるステータス: 200, 実際: %d", code)
+	}
+	// 処理済みの通知は処理しない
+	if code := post(h, "/", addRecordPayload); code != http.StatusOK {
+		t.Errorf("期待されるステータス: 200, 実際: %d", code)
+	}
+	if calls != 2 {
+		t.Errorf("期待される呼び出し回数: 2, 実際: %d", calls)
+	}
+}
+
+func TestHandlerFilters(t *testing.T) {
+	var errs []error
+	h := newHandler(t, webhook.Options{
+		Apps:              []string{"2"},
+		Secret:            "s3cret",
+		AllowedIPs:        []string{"192.0.2.0/24", "2001:db8::1"},
+		TrustForwardedFor: true,
+		OnError:           func(r *http.Request, err error) { errs = append(errs, err) },
+	})
+	calls := 0
+	h.OnAddRecord(func(ctx context.Context, e *webhook.RecordEvent[orderRecord]) error {
+		calls++
+		return nil
+	})
+	from := func(addr string) func(*http.Request) {
+		return func(r *http.Request) { r.Header.Set("X-Forwarded-For", "203.0.113.1, "+addr) }
+	}
+	other := strings.Replace(addRecordPayload, `"id": "1"`, `"id": "2"`, 1)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		modify func(*http.Request)
+		want   int
+	}{
+		{"シークレットなし", "/", other, from("192.0.2.10"), http.StatusUnauthorized},
+		{"シークレットが異なる", "/?token=wrong", other, from("192.0.2.10"), http.StatusUnauthorized},
+		{"許可されていない送信元", "/?token=s3cret", other, from("198.51.100.1"), http.StatusForbidden},
+		{"対象外のアプリ", "/?token=s3cret", addRecordPayload, from("192.0.2.10"), http.StatusOK},
+		{"不正なJSON", "/?token=s3cret", "{", from("192.0.2.10"), http.StatusBadRequest},
+		{"受け付ける", "/?token=s3cret", other, from("2001:db8::1"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := post(h, tt.target, tt.body, tt.modify); code != tt.want {
+				t.Errorf("期待されるステータス: %d, 実際: %d", tt.want, code)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("期待される呼び出し回数: 1, 実際: %d", calls)
+	}
+	if len(errs) != 4 {
+		t.Errorf("期待されるエラー数: 4, 実際: %d", len(errs))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("期待されるステータス: 405, 実際: %d", rec.Code)
+	}
+}
+
+func TestNewInvalidAllowedIP(t *testing.T) {
+	_, err := webhook.New[orderRecord](webhook.Options{AllowedIPs: []string{"example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "example.com") {
+		t.Errorf("期待されるエラー: example.com, 実際: %v", err)
+	}
+}
+
+func TestMemoryIDStore(t *testing.T) {
+	ctx := context.Background()
+	store := webhook.NewMemoryIDStore(2)
+	for _, id := range []string{"a", "b", "c"} {
+		if ok, _ := store.Add(ctx, id); !ok {
+			t.Errorf("初めてのIDは記録できるはず: %s", id)
+		}
+	}
+	// 上限を超えたため最も古いIDを破棄した
+	if ok, _ := store.Add(ctx, "a"); !ok {
+		t.Error("破棄したIDは再度記録できるはず")
+	}
+	if ok, _ := store.Add(ctx, "c"); ok {
+		t.Error("記録済みのIDは記録できないはず")
+	}
+}
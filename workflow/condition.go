@@ -0,0 +1,295 @@
+package workflow
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goqoo-on-kintone/goten/query"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// truth は条件式の評価結果
+type truth int
+
+const (
+	truthUnknown truth = iota // レコードの値だけでは判定できない
+	truthFalse
+	truthTrue
+)
+
+// truthOf はboolを評価結果に変換する
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// not は評価結果を反転する（判定できない場合はそのまま）
+func (t truth) not() truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+// evaluateCondition はアクションの実行条件（filterCond）をレコードの値で評価する
+// 関数（LOGINUSER()、TODAY()など）を使う条件や、レコードに含まれないフィールド・テーブル内のフィールドの条件は判定できない
+func evaluateCondition(cond string, rec record.Dynamic) (truth, error) {
+	if strings.TrimSpace(cond) == "" {
+		return truthTrue, nil
+	}
+	q, err := query.Parse(cond)
+	if err != nil {
+		return truthUnknown, fmt.Errorf("実行条件の解析エラー: %w", err)
+	}
+	if q.Condition == nil {
+		return truthTrue, nil
+	}
+	return evaluateExpr(q.Condition, rec), nil
+}
+
+// evaluateExpr は条件式のノードを評価する
+func evaluateExpr(expr query.Expr, rec record.Dynamic) truth {
+	switch e := expr.(type) {
+	case *query.LogicalExpr:
+		left, right := evaluateExpr(e.Left, rec), evaluateExpr(e.Right, rec)
+		if e.Operator == "and" {
+			if left == truthFalse || right == truthFalse {
+				return truthFalse
+			}
+			if left == truthTrue && right == truthTrue {
+				return truthTrue
+			}
+			return truthUnknown
+		}
+		if left == truthTrue || right == truthTrue {
+			return truthTrue
+		}
+		if left == truthFalse && right == truthFalse {
+			return truthFalse
+		}
+		return truthUnknown
+	case *query.ComparisonExpr:
+		return evaluateComparison(e, rec)
+	}
+	return truthUnknown
+}
+
+// evaluateComparison はフィールドと値の比較を評価する
+func evaluateComparison(e *query.ComparisonExpr, rec record.Dynamic) truth {
+	if !rec.Has(e.Field) {
+		return truthUnknown
+	}
+	values := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		if v.Kind == query.FunctionValue {
+			return truthUnknown
+		}
+		values[i] = v.Text
+	}
+
+	switch rec.Type(e.Field) {
+	case types.FieldTypeSingleLineText, types.FieldTypeMultiLineText, types.FieldTypeRichText,
+		types.FieldTypeLink, types.FieldTypeRecordNumber, types.FieldTypeStatus,
+		types.FieldTypeDropDown, types.FieldTypeRadioButton:
+		s, err := rec.String(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		return compareText(e.Operator, s, values)
+
+	case types.FieldTypeNumber, types.FieldTypeCalc, types.FieldTypeID:
+		n, err := rec.Number(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		return compareNumber(e.Operator, n, values)
+
+	case types.FieldTypeDate, types.FieldTypeTime:
+		// 日付（YYYY-MM-DD）・時刻（HH:MM）は固定長のため文字列のまま比較できる
+		s, err := rec.String(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		layout := time.DateOnly
+		if rec.Type(e.Field) == types.FieldTypeTime {
+			layout = "15:04"
+		}
+		return compareOrdered(e.Operator, s, values, func(v string) (string, error) {
+			_, err := time.Parse(layout, v)
+			return v, err
+		}, strings.Compare)
+
+	case types.FieldTypeDateTime, types.FieldTypeCreatedTime, types.FieldTypeUpdatedTime:
+		t, err := rec.Time(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		if t.IsZero() {
+			return compareOrdered(e.Operator, "", values, func(v string) (string, error) { return v, nil }, strings.Compare)
+		}
+		// kintoneは日時を分単位で比較する
+		return compareOrdered(e.Operator, t.Truncate(time.Minute), values, func(v string) (time.Time, error) {
+			parsed, err := time.Parse(time.RFC3339, v)
+			return parsed.Truncate(time.Minute), err
+		}, func(a, b time.Time) int { return a.Compare(b) })
+
+	case types.FieldTypeCheckBox, types.FieldTypeMultiSelect, types.FieldTypeCategory:
+		s, err := rec.Strings(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		return compareSet(e.Operator, s, values)
+
+	case types.FieldTypeUserSelect, types.FieldTypeOrganizationSelect, types.FieldTypeGroupSelect,
+		types.FieldTypeStatusAssignee, types.FieldTypeCreator, types.FieldTypeModifier:
+		entities, err := rec.Users(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		return compareSet(e.Operator, entities.Codes(), values)
+
+	case types.FieldTypeFile:
+		files, err := rec.Files(e.Field)
+		if err != nil {
+			return truthUnknown
+		}
+		return compareEmpty(e.Operator, len(files) == 0)
+	}
+	return truthUnknown
+}
+
+// compareEmpty はis empty / is not emptyを評価する
+func compareEmpty(operator string, empty bool) truth {
+	switch operator {
+	case "is empty":
+		return truthOf(empty)
+	case "is not empty":
+		return truthOf(!empty)
+	}
+	return truthUnknown
+}
+
+// compareText は文字列のフィールドを評価する
+func compareText(operator, s string, values []string) truth {
+	switch operator {
+	case "=":
+		return truthOf(s == values[0])
+	case "!=":
+		return truthOf(s != values[0])
+	case "in":
+		return truthOf(slices.Contains(values, s))
+	case "not in":
+		return truthOf(!slices.Contains(values, s))
+	case "like":
+		return truthOf(strings.Contains(strings.ToLower(s), strings.ToLower(values[0])))
+	case "not like":
+		return truthOf(!strings.Contains(strings.ToLower(s), strings.ToLower(values[0])))
+	}
+	return compareEmpty(operator, s == "")
+}
+
+// compareNumber は数値のフィールドを評価する
+func compareNumber(operator string, n types.Decimal, values []string) truth {
+	if n.IsEmpty() {
+		return compareOrdered(operator, "", values, func(v string) (string, error) { return v, nil }, strings.Compare)
+	}
+	r, err := n.Rat()
+	if err != nil {
+		return truthUnknown
+	}
+	return compareOrdered(operator, r, values, func(v string) (*big.Rat, error) {
+		parsed, ok := new(big.Rat).SetString(v)
+		if !ok {
+			return nil, fmt.Errorf("数値ではありません: %s", v)
+		}
+		return parsed, nil
+	}, (*big.Rat).Cmp)
+}
+
+// compareOrdered は大小関係のある値を評価する
+// 未入力の値（空文字列）は等価・包含の比較のみ行い、大小の比較は偽とする
+// 条件の値を解析できない場合は、一致する値がほかにない限り判定できないものとする
+func compareOrdered[V any](operator string, value V, values []string, parse func(string) (V, error), cmp func(a, b V) int) truth {
+	if operator == "is empty" || operator == "is not empty" {
+		s, ok := any(value).(string)
+		return compareEmpty(operator, ok && s == "")
+	}
+	if s, ok := any(value).(string); ok && s == "" {
+		switch operator {
+		case "=", "in":
+			return truthOf(slices.Contains(values, ""))
+		case "!=", "not in":
+			return truthOf(!slices.Contains(values, ""))
+		}
+		return truthFalse
+	}
+
+	match := func(v string) (int, bool) {
+		if v == "" {
+			return 0, false
+		}
+		parsed, err := parse(v)
+		if err != nil {
+			return 0, false
+		}
+		return cmp(value, parsed), true
+	}
+	switch operator {
+	case "in", "not in":
+		// 一致する値があれば判定できる。一致せず、解析できない値がある場合は判定できない
+		unknown := false
+		for _, v := range values {
+			c, ok := match(v)
+			if ok && c == 0 {
+				return truthOf(operator == "in")
+			}
+			if !ok && v != "" {
+				unknown = true
+			}
+		}
+		if unknown {
+			return truthUnknown
+		}
+		return truthOf(operator == "not in")
+	}
+
+	c, ok := match(values[0])
+	if !ok {
+		return truthUnknown
+	}
+	switch operator {
+	case "=":
+		return truthOf(c == 0)
+	case "!=":
+		return truthOf(c != 0)
+	case ">":
+		return truthOf(c > 0)
+	case "<":
+		return truthOf(c < 0)
+	case ">=":
+		return truthOf(c >= 0)
+	case "<=":
+		return truthOf(c <= 0)
+	}
+	return truthUnknown
+}
+
+// compareSet は複数の値を持つフィールド（チェックボックス、ユーザー選択など）を評価する
+func compareSet(operator string, current []string, values []string) truth {
+	switch operator {
+	case "in":
+		return truthOf(slices.ContainsFunc(current, func(s string) bool { return slices.Contains(values, s) }))
+	case "not in":
+		return truthOf(!slices.ContainsFunc(current, func(s string) bool { return slices.Contains(values, s) }))
+	}
+	return compareEmpty(operator, len(current) == 0)
+}
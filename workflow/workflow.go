@@ -0,0 +1,256 @@
+// Package workflow はプロセス管理の設定をもとにレコードのステータスを操作する機能を提供する
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/types"
+)
+
+// Workflow はアプリのプロセス管理設定
+type Workflow struct {
+	app     types.AppID
+	process *app.ProcessManagement
+	record  *record.Client
+}
+
+// AvailableAction はレコードで実行できるアクション
+type AvailableAction struct {
+	app.Action
+
+	// Determined は実行条件（FilterCond）をレコードの値で判定できたかどうか
+	// falseの場合は条件を満たさない可能性があり、実行時にkintoneが判定する
+	Determined bool
+}
+
+// AssigneeSelector は遷移先のステータスの作業者を選ぶ
+// 作業者を1人選ぶ設定（ONE）のステータスに遷移するときに呼ばれ、作業者のログイン名を返す
+// 空文字列を返した場合は作業者を指定しない
+type AssigneeSelector func(ctx context.Context, rec record.Dynamic, action app.Action, state app.State) (string, error)
+
+// ActionError はアクションを実行できないことを示すエラー
+type ActionError struct {
+	Action string // アクション名
+	Status string // レコードの現在のステータス
+	Reason string
+}
+
+// Error はerrorインターフェースを実装
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("アクション「%s」は実行できません（ステータス: %s）: %s", e.Action, e.Status, e.Reason)
+}
+
+// StepError はMoveToの途中でアクションの実行に失敗したことを示すエラー
+type StepError struct {
+	Completed []app.Action // 実行済みのアクション
+	Action    app.Action   // 失敗したアクション
+	Err       error
+}
+
+// Error はerrorインターフェースを実装
+func (e *StepError) Error() string {
+	return fmt.Sprintf("アクション「%s」の実行エラー（%d件実行済み）: %v", e.Action.Name, len(e.Completed), e.Err)
+}
+
+// Unwrap は元のエラーを返す
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// MoveToResult はMoveToの結果
+type MoveToResult struct {
+	Actions  []app.Action   // 実行したアクション
+	Revision types.Revision // 最後のアクション実行後のリビジョン
+}
+
+// New はプロセス管理設定からWorkflowを作成する
+func New(appID types.AppID, process *app.ProcessManagement, recordClient *record.Client) *Workflow {
+	return &Workflow{app: appID, process: process, record: recordClient}
+}
+
+// Load はアプリのプロセス管理設定を取得してWorkflowを作成する
+func Load(ctx context.Context, appClient *app.Client, recordClient *record.Client, appID types.AppID) (*Workflow, error) {
+	process, err := appClient.GetProcessManagement(ctx, app.GetProcessManagementParams{App: appID})
+	if err != nil {
+		return nil, err
+	}
+	if !process.Enable {
+		return nil, fmt.Errorf("アプリ%sのプロセス管理が有効になっていません", appID)
+	}
+	return New(appID, process, recordClient), nil
+}
+
+// Status はレコードの現在のステータスを返す
+// レコードにはステータス（STATUS）のフィールドが含まれている必要がある
+func (w *Workflow) Status(rec record.Dynamic) (string, error) {
+	for _, code := range rec.Codes() {
+		if rec.Type(code) == types.FieldTypeStatus {
+			return rec.String(code)
+		}
+	}
+	return "", fmt.Errorf("レコードにステータスのフィールドが含まれていません")
+}
+
+// AvailableActions はレコードの現在のステータスから実行できるアクションを設定順に返す
+// 実行条件を満たさないと判定できたアクションは除く。判定できない条件（関数を使う条件など）のアクションはDetermined=falseで含める
+// 作業者かどうかなど、ログインユーザーによる制限は判定しない
+func (w *Workflow) AvailableActions(rec record.Dynamic) ([]AvailableAction, error) {
+	status, err := w.Status(rec)
+	if err != nil {
+		return nil, err
+	}
+	return w.actionsFrom(status, rec)
+}
+
+// actionsFrom はステータスから実行できるアクションを返す
+func (w *Workflow) actionsFrom(status string, rec record.Dynamic) ([]AvailableAction, error) {
+	actions := []AvailableAction{}
+	for _, action := range w.process.Actions {
+		if action.From != status {
+			continue
+		}
+		t, err := evaluateCondition(action.FilterCond, rec)
+		if err != nil {
+			return nil, fmt.Errorf("アクション「%s」: %w", action.Name, err)
+		}
+		if t == truthFalse {
+			continue
+		}
+		actions = append(actions, AvailableAction{Action: action, Determined: t == truthTrue})
+	}
+	return actions, nil
+}
+
+// ValidateAction はレコードの現在のステータスからアクションを実行できるかを確認する
+// 実行できない場合は*ActionErrorを返す
+func (w *Workflow) ValidateAction(rec record.Dynamic, action string) (*AvailableAction, error) {
+	status, err := w.Status(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(w.process.Actions, func(a app.Action) bool { return a.Name == action }) {
+		return nil, &ActionError{Action: action, Status: status, Reason: "アクションが存在しません"}
+	}
+	for _, a := range w.process.Actions {
+		if a.Name != action || a.From != status {
+			continue
+		}
+		t, err := evaluateCondition(a.FilterCond, rec)
+		if err != nil {
+			return nil, err
+		}
+		if t == truthFalse {
+			return nil, &ActionError{Action: action, Status: status, Reason: "実行条件を満たしていません: " + a.FilterCond}
+		}
+		return &AvailableAction{Action: a, Determined: t == truthTrue}, nil
+	}
+	return nil, &ActionError{Action: action, Status: status, Reason: "現在のステータスから実行できるアクションではありません"}
+}
+
+// Execute はアクションを確認してから実行し、実行後のリビジョンを返す
+// レコードにリビジョン（$revision）が含まれている場合は、取得後にレコードが更新されていると失敗する
+func (w *Workflow) Execute(ctx context.Context, rec record.Dynamic, action string, selectAssignee AssigneeSelector) (types.Revision, error) {
+	available, err := w.ValidateAction(rec, action)
+	if err != nil {
+		return "", err
+	}
+	return w.execute(ctx, rec, available.Action, rec.Revision(), selectAssignee)
+}
+
+// execute はアクションを実行する
+func (w *Workflow) execute(ctx context.Context, rec record.Dynamic, action app.Action, revision types.Revision, selectAssignee AssigneeSelector) (types.Revision, error) {
+	params := record.UpdateRecordStatusParams{
+		App:    w.app,
+		ID:     rec.ID(),
+		Action: action.Name,
+	}
+	if revision != "" {
+		params.Revision = &revision
+	}
+
+	state := w.process.States[action.To]
+	if selectAssignee != nil && state.Assignee != nil && state.Assignee.Type == "ONE" {
+		assignee, err := selectAssignee(ctx, rec, action, state)
+		if err != nil {
+			return "", fmt.Errorf("作業者の選択エラー: %w", err)
+		}
+		params.Assignee = assignee
+	}
+
+	result, err := w.record.UpdateRecordStatus(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	return result.Revision, nil
+}
+
+// FindPath はレコードを現在のステータスからtargetのステータスに移すアクションの列を返す
+// 実行するアクションが最も少ない経路を返し、同じ数の経路が複数ある場合は設定順で先のアクションを優先する
+// 実行条件を満たさないと判定できたアクションは経路に含めない。現在のステータスがtargetの場合は空の列を返す
+func (w *Workflow) FindPath(rec record.Dynamic, target string) ([]app.Action, error) {
+	status, err := w.Status(rec)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := w.process.States[target]; !ok {
+		return nil, fmt.Errorf("ステータスが存在しません: %s", target)
+	}
+
+	// 幅優先探索で、各ステータスに到達したアクションを記録する
+	reachedBy := map[string]app.Action{}
+	visited := map[string]bool{status: true}
+	queue := []string{status}
+	for len(queue) > 0 && !visited[target] {
+		current := queue[0]
+		queue = queue[1:]
+
+		actions, err := w.actionsFrom(current, rec)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range actions {
+			if visited[a.To] {
+				continue
+			}
+			visited[a.To] = true
+			reachedBy[a.To] = a.Action
+			queue = append(queue, a.To)
+		}
+	}
+	if !visited[target] {
+		return nil, fmt.Errorf("ステータス「%s」から「%s」に移るアクションがありません", status, target)
+	}
+
+	path := []app.Action{}
+	for s := target; s != status; s = reachedBy[s].From {
+		path = append(path, reachedBy[s])
+	}
+	slices.Reverse(path)
+	return path, nil
+}
+
+// MoveTo はFindPathで求めたアクションを順に実行し、レコードをtargetのステータスに移す
+// 各アクションは直前のアクションで更新されたリビジョンを指定して実行する
+// 途中のアクションが失敗した場合は*StepErrorを返す（実行済みのアクションは元に戻さない）
+func (w *Workflow) MoveTo(ctx context.Context, rec record.Dynamic, target string, selectAssignee AssigneeSelector) (*MoveToResult, error) {
+	path, err := w.FindPath(rec, target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveToResult{Actions: []app.Action{}, Revision: rec.Revision()}
+	for _, action := range path {
+		revision, err := w.execute(ctx, rec, action, result.Revision, selectAssignee)
+		if err != nil {
+			return nil, &StepError{Completed: result.Actions, Action: action, Err: err}
+		}
+		result.Actions = append(result.Actions, action)
+		result.Revision = revision
+	}
+	return result, nil
+}
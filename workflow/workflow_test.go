@@ -0,0 +1,264 @@
+package workflow_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/goqoo-on-kintone/goten/app"
+	"github.com/goqoo-on-kintone/goten/auth"
+	gotenhttp "github.com/goqoo-on-kintone/goten/http"
+	"github.com/goqoo-on-kintone/goten/record"
+	"github.com/goqoo-on-kintone/goten/workflow"
+)
+
+// processJSON は経費申請のプロセス管理設定
+// 未処理 → 処理中 → 完了（10000円未満）/ 承認待ち → 完了（10000円以上）
+var processJSON = `{
+	"enable": true,
+	"states": {
+		"未処理": {"name": "未処理", "index": "0", "assignee": {"type": "ONE", "entities": []}},
+		"処理中": {"name": "処理中", "index": "1", "assignee": {"type": "ONE", "entities": []}},
+		"承認待ち": {"name": "承認待ち", "index": "2", "assignee": {"type": "ONE", "entities": [
+			{"entity": {"type": "USER", "code": "manager1"}},
+			{"entity": {"type": "USER", "code": "manager2"}}
+		]}},
+		"完了": {"name": "完了", "index": "3", "assignee": {"type": "ANY", "entities": []}}
+	},
+	"actions": [
+		{"name": "処理開始", "from": "未処理", "to": "処理中", "filterCond": ""},
+		{"name": "完了する", "from": "処理中", "to": "完了", "filterCond": "金額 < 10000"},
+		{"name": "承認依頼", "from": "処理中", "to": "承認待ち", "filterCond": "金額 >= 10000"},
+		{"name": "差し戻し", "from": "処理中", "to": "未処理", "filterCond": "作成者 in (LOGINUSER())"},
+		{"name": "承認", "from": "承認待ち", "to": "完了", "filterCond": ""}
+	],
+	"revision": "5"
+}`
+
+// newRecord はステータスと金額を指定したレコードを作成する
+func newRecord(t *testing.T, status, amount string) record.Dynamic {
+	t.Helper()
+	data := `{
+		"$id": {"type": "__ID__", "value": "10"},
+		"$revision": {"type": "__REVISION__", "value": "3"},
+		"ステータス": {"type": "STATUS", "value": "` + status + `"},
+		"金額": {"type": "NUMBER", "value": "` + amount + `"},
+		"区分": {"type": "DROP_DOWN", "value": "出張"},
+		"タグ": {"type": "CHECK_BOX", "value": ["至急", "海外"]},
+		"申請日": {"type": "DATE", "value": "2024-04-01"},
+		"作成者": {"type": "CREATOR", "value": {"code": "user1", "name": "ユーザー1"}}
+	}`
+	var rec record.Dynamic
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	return rec
+}
+
+// newWorkflow はテスト用のWorkflowを作成する
+func newWorkflow(t *testing.T, serverURL string) *workflow.Workflow {
+	t.Helper()
+	var process app.ProcessManagement
+	if err := json.Unmarshal([]byte(processJSON), &process); err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	client := record.NewClient(gotenhttp.NewDefaultClient(serverURL, auth.APITokenAuth{Token: "test-token"}))
+	return workflow.New("1", &process, client)
+}
+
+func TestAvailableActions(t *testing.T) {
+	wf := newWorkflow(t, "http://localhost")
+
+	actions, err := wf.AvailableActions(newRecord(t, "処理中", "5000"))
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("期待されるアクション数: 2, 実際: %d (%+v)", len(actions), actions)
+	}
+	if actions[0].Name != "完了する" || !actions[0].Determined {
+		t.Errorf("期待されるアクション: 完了する（判定済み）, 実際: %+v", actions[0])
+	}
+	if actions[1].Name != "差し戻し" || actions[1].Determined {
+		t.Errorf("LOGINUSER()の条件は判定できないはずが: %+v", actions[1])
+	}
+
+	actions, err = wf.AvailableActions(newRecord(t, "処理中", "20000"))
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(actions) != 2 || actions[0].Name != "承認依頼" {
+		t.Errorf("期待されるアクション: 承認依頼, 差し戻し, 実際: %+v", actions)
+	}
+}
+
+func TestAvailableActionsConditions(t *testing.T) {
+	rec := newRecord(t, "処理中", "5000")
+	tests := []struct {
+		cond string
+		want bool // アクションが含まれるかどうか
+	}{
+		{`区分 in ("出張", "交通費")`, true},
+		{`区分 not in ("出張")`, false},
+		{`タグ in ("海外")`, true},
+		{`タグ in ("国内")`, false},
+		{`申請日 >= "2024-04-01" and 申請日 < "2024-05-01"`, true},
+		{`金額 > 10000 or 区分 = "出張"`, true},
+		{`金額 > 10000 or 区分 = "交通費"`, false},
+		{`金額 is empty`, false},
+		{`作成者 in ("user1")`, true},
+		{`申請日 = TODAY()`, true},                 // 判定できない条件は含める
+		{`申請日 = TODAY() and 金額 > 10000`, false}, // 一方が偽なら全体も偽
+		{`存在しない = "x"`, true},
+		{`金額 in ("abc")`, true}, // 解析できない値は判定できない
+		{`金額 in ("abc", "5000")`, true},
+		{`金額 not in ("abc", "5000")`, false},
+		{`金額 < "abc" and 区分 = "出張"`, true},
+	}
+
+	for _, tt := range tests {
+		process := app.ProcessManagement{
+			Enable: true,
+			States: map[string]app.State{"処理中": {Name: "処理中"}, "完了": {Name: "完了"}},
+			Actions: []app.Action{
+				{Name: "完了する", From: "処理中", To: "完了", FilterCond: tt.cond},
+			},
+		}
+		wf := workflow.New("1", &process, nil)
+		actions, err := wf.AvailableActions(rec)
+		if err != nil {
+			t.Fatalf("%s: エラーが発生: %v", tt.cond, err)
+		}
+		if got := len(actions) == 1; got != tt.want {
+			t.Errorf("%s: 期待される結果: %v, 実際: %v", tt.cond, tt.want, got)
+		}
+	}
+}
+
+func TestAvailableActionsInvalidValue(t *testing.T) {
+	for _, cond := range []string{`金額 in ("abc")`, `金額 not in ("abc")`, `申請日 not in ("2024/04/01")`, `金額 >= "abc"`} {
+		process := app.ProcessManagement{
+			Enable:  true,
+			States:  map[string]app.State{"処理中": {Name: "処理中"}, "完了": {Name: "完了"}},
+			Actions: []app.Action{{Name: "完了する", From: "処理中", To: "完了", FilterCond: cond}},
+		}
+		actions, err := workflow.New("1", &process, nil).AvailableActions(newRecord(t, "処理中", "5000"))
+		if err != nil {
+			t.Fatalf("%s: エラーが発生: %v", cond, err)
+		}
+		if len(actions) != 1 || actions[0].Determined {
+			t.Errorf("%s: 解析できない値の条件は判定できないはずが: %+v", cond, actions)
+		}
+	}
+}
+
+func TestValidateAction(t *testing.T) {
+	wf := newWorkflow(t, "http://localhost")
+	rec := newRecord(t, "処理中", "5000")
+
+	if _, err := wf.ValidateAction(rec, "完了する"); err != nil {
+		t.Errorf("エラーが発生: %v", err)
+	}
+
+	var actionErr *workflow.ActionError
+	for _, action := range []string{"承認依頼", "承認", "取り下げ"} {
+		_, err := wf.ValidateAction(rec, action)
+		if !errors.As(err, &actionErr) {
+			t.Errorf("%s: 期待されるエラー: *workflow.ActionError, 実際: %v", action, err)
+			continue
+		}
+		if actionErr.Status != "処理中" {
+			t.Errorf("期待されるステータス: 処理中, 実際: %s", actionErr.Status)
+		}
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/k/v1/record/status.json" {
+			t.Errorf("期待されるリクエスト: PUT /k/v1/record/status.json, 実際: %s %s", r.Method, r.URL.Path)
+		}
+		var reqBody map[string]any
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		requests = append(requests, reqBody)
+
+		revision, _ := strconv.Atoi(reqBody["revision"].(string))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"revision": strconv.Itoa(revision + 2)})
+	}))
+	defer server.Close()
+
+	wf := newWorkflow(t, server.URL)
+	rec := newRecord(t, "未処理", "20000")
+
+	path, err := wf.FindPath(rec, "完了")
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(path) != 3 || path[0].Name != "処理開始" || path[1].Name != "承認依頼" || path[2].Name != "承認" {
+		t.Fatalf("期待される経路: 処理開始 → 承認依頼 → 承認, 実際: %+v", path)
+	}
+
+	var selected []string
+	result, err := wf.MoveTo(context.Background(), rec, "完了", func(ctx context.Context, rec record.Dynamic, action app.Action, state app.State) (string, error) {
+		selected = append(selected, state.Name)
+		if state.Name == "承認待ち" {
+			return state.Assignee.Entities[1].Entity.Code, nil
+		}
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("エラーが発生: %v", err)
+	}
+	if len(result.Actions) != 3 || result.Revision != "9" {
+		t.Errorf("期待される結果: 3アクション revision=9, 実際: %d アクション revision=%s", len(result.Actions), result.Revision)
+	}
+	if len(selected) != 2 || selected[1] != "承認待ち" {
+		t.Errorf("作業者の選択はONEのステータスでのみ呼ばれるはずが: %v", selected)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("期待されるリクエスト数: 3, 実際: %d", len(requests))
+	}
+	if requests[1]["action"] != "承認依頼" || requests[1]["assignee"] != "manager2" || requests[1]["revision"] != "5" {
+		t.Errorf("2番目のリクエストが不正: %v", requests[1])
+	}
+	if _, ok := requests[2]["assignee"]; ok {
+		t.Errorf("ANYのステータスには作業者を指定しないはずが: %v", requests[2])
+	}
+
+	if _, err := wf.FindPath(newRecord(t, "完了", "20000"), "未処理"); err == nil {
+		t.Error("到達できないステータスでエラーが発生しない")
+	}
+}
+
+func TestMoveToStepError(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.Header().Set("Content-Type", "application/json")
+		if callCount == 2 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]any{"code": "GAIA_CO02", "id": "x", "message": "リビジョンが一致しません"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"revision": "5"})
+	}))
+	defer server.Close()
+
+	wf := newWorkflow(t, server.URL)
+	_, err := wf.MoveTo(context.Background(), newRecord(t, "未処理", "5000"), "完了", nil)
+
+	var stepErr *workflow.StepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("期待されるエラー: *workflow.StepError, 実際: %v", err)
+	}
+	if len(stepErr.Completed) != 1 || stepErr.Action.Name != "完了する" {
+		t.Errorf("期待される結果: 1件実行済み、完了するで失敗, 実際: %+v", stepErr)
+	}
+}